@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttendanceHandler struct {
+	attendanceService service.AttendanceService
+}
+
+func NewAttendanceHandler(attendanceService service.AttendanceService) *AttendanceHandler {
+	return &AttendanceHandler{
+		attendanceService: attendanceService,
+	}
+}
+
+// GetAttendance handles the attendance report of a room, as JSON or CSV
+// GET /api/v1/rooms/:id/attendance?from=&to=&format=csv
+func (h *AttendanceHandler) GetAttendance(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var query service.AttendanceQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+	if query.Format != "" && query.Format != "json" && query.Format != "csv" {
+		util.BadRequest(c, "format must be json or csv")
+		return
+	}
+
+	report, err := h.attendanceService.GetAttendance(c.Param("id"), userID.(string), query)
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	if query.Format == "csv" {
+		data, err := service.AttendanceCSV(report)
+		if err != nil {
+			util.ErrorResponse(c, http.StatusInternalServerError, "Failed to export attendance", nil)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"attendance-%s.csv\"", report.RoomID))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Attendance retrieved successfully", report)
+}
@@ -14,13 +14,13 @@ import (
 
 type AuthHandler struct {
 	authService service.AuthService
-	jwtSecret   string
+	jwtKeys     *util.JWTKeySet
 }
 
-func NewAuthHandler(authService service.AuthService, jwtSecret string) *AuthHandler {
+func NewAuthHandler(authService service.AuthService, jwtKeys *util.JWTKeySet) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
-		jwtSecret:   jwtSecret,
+		jwtKeys:     jwtKeys,
 	}
 }
 
@@ -51,8 +51,11 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.Login(req)
+	resp, err := h.authService.Login(req, clientInfo(c))
 	if err != nil {
+		if rateLimited(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not verified") {
 			// Return special response for unverified email with email in data
 			util.ErrorResponse(c, http.StatusUnauthorized, err.Error(), gin.H{
@@ -82,8 +85,11 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.VerifyOTP(req.Email, req.OTPCode)
+	resp, err := h.authService.VerifyOTP(req.Email, req.OTPCode, clientInfo(c))
 	if err != nil {
+		if rateLimited(c, err) {
+			return
+		}
 		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
 		return
 	}
@@ -104,6 +110,9 @@ func (h *AuthHandler) ResendOTP(c *gin.Context) {
 	}
 
 	if err := h.authService.ResendOTP(req.Email); err != nil {
+		if rateLimited(c, err) {
+			return
+		}
 		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
 		return
 	}
@@ -120,9 +129,16 @@ func (h *AuthHandler) GoogleOAuth(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.GoogleOAuth(req)
+	resp, err := h.authService.GoogleOAuth(req, clientInfo(c))
 	if err != nil {
-		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		switch {
+		case errors.Is(err, service.ErrGoogleSignInDisabled):
+			util.ErrorResponse(c, http.StatusServiceUnavailable, err.Error(), nil)
+		case errors.Is(err, util.ErrInvalidGoogleToken):
+			util.ErrorResponse(c, http.StatusUnauthorized, err.Error(), nil)
+		default:
+			util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		}
 		return
 	}
 
@@ -141,7 +157,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.RefreshToken(req.RefreshToken)
+	resp, err := h.authService.RefreshToken(req.RefreshToken, clientInfo(c))
 	if err != nil {
 		util.Unauthorized(c, err.Error())
 		return
@@ -150,6 +166,86 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	util.SuccessResponse(c, http.StatusOK, "Token refreshed successfully", resp)
 }
 
+// Logout revokes a refresh token and the login it belongs to
+// POST /api/v1/auth/logout
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.authService.Logout(req.RefreshToken); err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			util.Unauthorized(c, err.Error())
+			return
+		}
+		util.InternalServerError(c, err.Error())
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Logged out successfully", nil)
+}
+
+// LogoutAll revokes the refresh tokens of all the user's devices
+// POST /api/v1/auth/logout-all
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.authService.LogoutAll(userID.(string)); err != nil {
+		util.InternalServerError(c, err.Error())
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Logged out from all devices", nil)
+}
+
+// ListSessions lists the devices the user is signed in on
+// GET /api/v1/auth/sessions
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(userID.(string), c.GetString("sessionID"))
+	if err != nil {
+		util.InternalServerError(c, err.Error())
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Sessions retrieved successfully", gin.H{"sessions": sessions})
+}
+
+// RevokeSession signs one of the user's devices out
+// DELETE /api/v1/auth/sessions/:id
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID.(string), c.Param("id")); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.InternalServerError(c, err.Error())
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Session revoked successfully", nil)
+}
+
 // RequestResetPassword handles password reset request
 // POST /api/v1/auth/forgot-password
 func (h *AuthHandler) RequestResetPassword(c *gin.Context) {
@@ -163,6 +259,9 @@ func (h *AuthHandler) RequestResetPassword(c *gin.Context) {
 	}
 
 	if err := h.authService.RequestResetPassword(req.Email); err != nil {
+		if rateLimited(c, err) {
+			return
+		}
 		// Return error if email doesn't exist or other error occurs
 		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
 		return
@@ -218,6 +317,9 @@ func (h *AuthHandler) VerifyResetPassword(c *gin.Context) {
 	}
 
 	if err := h.authService.VerifyResetPassword(req.Email, req.OTPCode, req.NewPassword); err != nil {
+		if rateLimited(c, err) {
+			return
+		}
 		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
 		return
 	}
@@ -238,7 +340,7 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.ResetPassword(req.Token, req.NewPassword)
+	resp, err := h.authService.ResetPassword(req.Token, req.NewPassword, clientInfo(c))
 	if err != nil {
 		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
 		return
@@ -259,7 +361,7 @@ func (h *AuthHandler) VerifyEmail(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.VerifyEmail(req.Token)
+	resp, err := h.authService.VerifyEmail(req.Token, clientInfo(c))
 	if err != nil {
 		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
 		return
@@ -287,6 +389,24 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 }
 
 // AuthMiddleware validates JWT token
+// OptionalAuthMiddleware sets the user in the context when a valid token is
+// sent, and lets anonymous requests through
+func (h *AuthHandler) OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			claims, err := util.ValidateToken(parts[1], h.jwtKeys, util.TokenTypeAccess)
+			if err == nil && h.authService.IsSessionActive(claims.SessionID) {
+				c.Set("userID", claims.UserID)
+				c.Set("email", claims.Email)
+				c.Set("userType", claims.UserType)
+				c.Set("sessionID", claims.SessionID)
+			}
+		}
+		c.Next()
+	}
+}
+
 func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -304,16 +424,40 @@ func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
 		}
 
 		token := parts[1]
-		claims, err := util.ValidateToken(token, h.jwtSecret)
+		claims, err := util.ValidateToken(token, h.jwtKeys, util.TokenTypeAccess)
 		if err != nil {
 			util.Unauthorized(c, "Invalid or expired token")
 			c.Abort()
 			return
 		}
 
+		// Tokens stop working as soon as their session is signed out
+		if !h.authService.IsSessionActive(claims.SessionID) {
+			util.Unauthorized(c, "Session has been signed out")
+			c.Abort()
+			return
+		}
+
 		c.Set("userID", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("userType", claims.UserType)
+		c.Set("sessionID", claims.SessionID)
 		c.Next()
 	}
 }
+
+// JWKS publishes the public keys auth tokens are signed with, so other
+// services can verify them. Empty while tokens are signed with a shared secret.
+// GET /.well-known/jwks.json
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtKeys.JWKS())
+}
+
+// clientInfo describes the device making the request, for session tracking
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
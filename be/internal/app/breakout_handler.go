@@ -0,0 +1,120 @@
+package app
+
+import (
+	"net/http"
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BreakoutHandler struct {
+	breakoutService service.BreakoutService
+}
+
+func NewBreakoutHandler(breakoutService service.BreakoutService) *BreakoutHandler {
+	return &BreakoutHandler{
+		breakoutService: breakoutService,
+	}
+}
+
+// Create handles opening breakout rooms for a live meeting
+// POST /api/v1/rooms/:id/breakouts
+func (h *BreakoutHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.CreateBreakoutsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	overview, err := h.breakoutService.Create(c.Param("id"), userID.(string), req)
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "Breakout rooms opened successfully", overview)
+}
+
+// Get handles listing the open breakout rooms and their assignments
+// GET /api/v1/rooms/:id/breakouts
+func (h *BreakoutHandler) Get(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	overview, err := h.breakoutService.Get(c.Param("id"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Breakout rooms retrieved successfully", overview)
+}
+
+// Assign handles assigning participants to breakout rooms
+// PUT /api/v1/rooms/:id/breakouts/assignments
+func (h *BreakoutHandler) Assign(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.AssignBreakoutsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	overview, err := h.breakoutService.Assign(c.Param("id"), userID.(string), req)
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Participants assigned successfully", overview)
+}
+
+// Join handles issuing a token for a breakout room
+// POST /api/v1/rooms/:id/breakouts/:breakoutId/join
+func (h *BreakoutHandler) Join(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	response, err := h.breakoutService.Join(c.Param("id"), c.Param("breakoutId"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Joined breakout room successfully", response)
+}
+
+// Close handles closing all breakout rooms and returning everyone to the meeting
+// DELETE /api/v1/rooms/:id/breakouts
+func (h *BreakoutHandler) Close(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.breakoutService.Close(c.Param("id"), userID.(string)); err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Breakout rooms closed successfully", nil)
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"yourapp/internal/model"
 	"yourapp/internal/service"
 	"yourapp/internal/util"
 	"yourapp/internal/websocket"
@@ -17,22 +19,30 @@ import (
 )
 
 type ChatHandler struct {
-	chatService    service.ChatService
-	kolosalService service.KolosalService
-	roomService    service.RoomService
-	hub            *websocket.Hub
-	jwtSecret      string
+	chatService       service.ChatService
+	kolosalService    service.KolosalService
+	roomService       service.RoomService
+	agentToolService  service.AgentToolService
+	transcriptService service.TranscriptService
+	ragService        service.RAGService
+	authService       service.AuthService
+	hub               *websocket.Hub
+	jwtKeys           *util.JWTKeySet
 	// Store agent history per room (roomID -> []HistoryItem)
 	agentHistory sync.Map // map[string][]service.HistoryItem
 }
 
-func NewChatHandler(chatService service.ChatService, kolosalService service.KolosalService, roomService service.RoomService, hub *websocket.Hub, jwtSecret string) *ChatHandler {
+func NewChatHandler(chatService service.ChatService, kolosalService service.KolosalService, roomService service.RoomService, agentToolService service.AgentToolService, transcriptService service.TranscriptService, ragService service.RAGService, authService service.AuthService, hub *websocket.Hub, jwtKeys *util.JWTKeySet) *ChatHandler {
 	return &ChatHandler{
-		chatService:    chatService,
-		kolosalService: kolosalService,
-		roomService:    roomService,
-		hub:            hub,
-		jwtSecret:      jwtSecret,
+		chatService:       chatService,
+		kolosalService:    kolosalService,
+		roomService:       roomService,
+		agentToolService:  agentToolService,
+		transcriptService: transcriptService,
+		ragService:        ragService,
+		authService:       authService,
+		hub:               hub,
+		jwtKeys:           jwtKeys,
 	}
 }
 
@@ -59,7 +69,7 @@ func (h *ChatHandler) CreateMessage(c *gin.Context) {
 
 	message, err := h.chatService.CreateMessage(roomID, userID.(string), req.Message)
 	if err != nil {
-		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		roomError(c, err)
 		return
 	}
 
@@ -77,6 +87,12 @@ func (h *ChatHandler) CreateMessage(c *gin.Context) {
 // GetMessages handles getting messages for a room
 // GET /api/v1/rooms/:id/messages
 func (h *ChatHandler) GetMessages(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
 	roomID := c.Param("id")
 	if roomID == "" {
 		util.BadRequest(c, "Room ID is required")
@@ -98,9 +114,9 @@ func (h *ChatHandler) GetMessages(c *gin.Context) {
 		}
 	}
 
-	messages, err := h.chatService.GetMessages(roomID, limit, offset)
+	messages, err := h.chatService.GetMessages(roomID, userID.(string), limit, offset)
 	if err != nil {
-		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		roomError(c, err)
 		return
 	}
 
@@ -136,12 +152,27 @@ func (h *ChatHandler) ServeWebSocket(c *gin.Context) {
 	}
 
 	// Validate token
-	claims, err := util.ValidateToken(token, h.jwtSecret)
+	claims, err := util.ValidateToken(token, h.jwtKeys, util.TokenTypeAccess)
 	if err != nil {
 		log.Printf("[WS] WebSocket connection rejected: Invalid token for room %s, error: %v", roomID, err)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 		return
 	}
+	if !h.authService.IsSessionActive(claims.SessionID) {
+		log.Printf("[WS] WebSocket connection rejected: session of user %s has been signed out", claims.UserID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been signed out"})
+		return
+	}
+
+	if err := h.roomService.EnsureSocketAccess(roomID, claims.UserID); err != nil {
+		log.Printf("[WS] WebSocket connection rejected: user %s may not connect to room %s: %v", claims.UserID, roomID, err)
+		status := http.StatusForbidden
+		if errors.Is(err, service.ErrRoomNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
 
 	log.Printf("[WS] WebSocket connection accepted: room=%s, user=%s", roomID, claims.UserID)
 
@@ -174,11 +205,11 @@ func (h *ChatHandler) KolosalAPI(c *gin.Context) {
 		return
 	}
 
-	// Verify room exists (same pattern as CreateMessage - it verifies in service)
-	// But we verify here first to match the pattern
-	_, err := h.roomService.GetRoomByID(roomID)
+	// The answer is saved into the room, so only people who may post there
+	// can ask (same check as CreateMessage)
+	err := h.chatService.EnsureCanPost(roomID, userID.(string))
 	if err != nil {
-		util.ErrorResponse(c, http.StatusNotFound, "Room not found", nil)
+		roomError(c, err)
 		return
 	}
 
@@ -198,16 +229,46 @@ func (h *ChatHandler) KolosalAPI(c *gin.Context) {
 		Tools           []string               `json:"tools,omitempty"`             // Tools for agent
 		History         []service.HistoryItem  `json:"history,omitempty"`           // History from frontend
 		ResetHistory    bool                   `json:"reset_history,omitempty"`     // Reset chat history
+		UseRoomTools    bool                   `json:"use_room_tools,omitempty"`    // Let the model call server-side room tools
+		UseTranscript   bool                   `json:"use_transcript,omitempty"`    // Answer using the meeting transcript as context
+		UseRetrieval    bool                   `json:"use_retrieval,omitempty"`     // Answer using the most relevant snippets of the room history
+		TopK            int                    `json:"top_k,omitempty"`             // Number of snippets to retrieve
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		util.BadRequest(c, err.Error())
 		return
 	}
 
+	// The transcript is only shared with people who have access to the room
+	var transcript string
+	if req.UseTranscript {
+		transcript, err = h.transcriptService.BuildContext(roomID, userID.(string), 12000)
+		if errors.Is(err, service.ErrRoomNotFound) || errors.Is(err, service.ErrRoomAccessDenied) {
+			transcriptError(c, err)
+			return
+		}
+		if err != nil {
+			log.Printf("[KolosalAPI] Failed to load transcript: %v", err)
+		}
+	}
+
+	// So are the snippets retrieved from the room history
+	var snippets []service.RetrievedSnippet
+	if req.UseRetrieval {
+		snippets, err = h.ragService.Search(roomID, userID.(string), req.Prompt, req.TopK)
+		if errors.Is(err, service.ErrRoomNotFound) || errors.Is(err, service.ErrRoomAccessDenied) {
+			searchError(c, err)
+			return
+		}
+		if err != nil {
+			log.Printf("[KolosalAPI] Failed to retrieve room context: %v", err)
+		}
+	}
+
 	// Default values
-	model := req.Model
-	if model == "" {
-		model = "meta-llama/llama-4-maverick-17b-128e-instruct"
+	modelName := req.Model
+	if modelName == "" {
+		modelName = service.DefaultChatModel
 	}
 	maxTokens := req.MaxTokens
 	if maxTokens == 0 {
@@ -227,6 +288,14 @@ func (h *ChatHandler) KolosalAPI(c *gin.Context) {
 
 	var aiResponse string
 	var response *service.KolosalChatResponse
+	var citations []service.RetrievedSnippet
+
+	// Provenance of the answer, filled in as the request is processed
+	startedAt := time.Now()
+	provenance := &model.AIProvenance{
+		Mode:          model.AIModeChat,
+		RequestedByID: userID.(string),
+	}
 
 	// Handle reset history if requested
 	if req.ResetHistory {
@@ -238,6 +307,7 @@ func (h *ChatHandler) KolosalAPI(c *gin.Context) {
 	if req.UseAgent && req.WorkspaceID != "" {
 		// Handle Agent request
 		log.Printf("[KolosalAPI] Processing Agent request with workspace: %s", req.WorkspaceID)
+		provenance.Mode = model.AIModeAgent
 
 		// Get history - prefer from request, fallback to memory
 		var history []service.HistoryItem
@@ -254,7 +324,7 @@ func (h *ChatHandler) KolosalAPI(c *gin.Context) {
 		// Build agent request
 		agentRequest := &service.KolosalAgentRequest{
 			Input:       req.Prompt,
-			Model:       model,
+			Model:       modelName,
 			WorkspaceID: req.WorkspaceID,
 			Tools:       req.Tools,
 			History:     history,
@@ -317,6 +387,7 @@ func (h *ChatHandler) KolosalAPI(c *gin.Context) {
 	} else if req.UseOCR && req.ImageData != "" {
 		// Handle OCR request
 		log.Printf("[KolosalAPI] Processing OCR request")
+		provenance.Mode = model.AIModeOCR
 
 		ocrRequest := &service.KolosalOCRRequest{
 			ImageData: req.ImageData,
@@ -490,7 +561,7 @@ func (h *ChatHandler) KolosalAPI(c *gin.Context) {
 		// Handle regular chat completion
 		// Prepare Kolosal request
 		kolosalRequest := &service.KolosalChatRequest{
-			Model: model,
+			Model: modelName,
 			Messages: []service.Message{
 				{
 					Role:    "user",
@@ -502,14 +573,61 @@ func (h *ChatHandler) KolosalAPI(c *gin.Context) {
 			Stream:      false,
 		}
 
+		// Prepend the meeting transcript as context if requested
+		if transcript != "" {
+			kolosalRequest.Messages = append([]service.Message{{
+				Role:    "system",
+				Content: "You are an assistant in a video meeting. Use the meeting transcript below to answer. If the answer is not in the transcript, say so.\n\nTranscript:\n" + transcript,
+			}}, kolosalRequest.Messages...)
+			provenance.UsedTranscript = true
+			log.Printf("[KolosalAPI] Added transcript context: %d chars", len(transcript))
+		}
+
+		// Prepend the most relevant snippets of the room history if requested
+		if len(snippets) > 0 {
+			citations = snippets
+			for _, snippet := range snippets {
+				provenance.RetrievedSources = append(provenance.RetrievedSources, model.AISource{
+					Citation:   snippet.Citation,
+					SourceType: snippet.SourceType,
+					SourceID:   snippet.SourceID,
+					Score:      snippet.Score,
+				})
+				if snippet.SourceType == model.EmbeddingSourceMessage {
+					provenance.PromptMessageIDs = append(provenance.PromptMessageIDs, snippet.SourceID)
+				}
+			}
+			kolosalRequest.Messages = append([]service.Message{h.ragService.BuildContextMessage(snippets)}, kolosalRequest.Messages...)
+			log.Printf("[KolosalAPI] Added %d retrieved snippets", len(snippets))
+		}
+
 		// Set cache if provided
 		if req.Cache != nil {
 			kolosalRequest.Cache = req.Cache
 		}
 
-		// Call Kolosal API
+		// Call Kolosal API (with room tools if requested)
 		var err error
-		response, err = h.kolosalService.ChatCompletions(kolosalRequest)
+		if req.UseRoomTools {
+			log.Printf("[KolosalAPI] Processing chat request with room tools")
+			provenance.Mode = model.AIModeRoomTools
+			trace := &service.ToolTrace{}
+			response, err = h.agentToolService.ChatWithTools(kolosalRequest, service.ToolContext{
+				RoomID: roomID,
+				UserID: userID.(string),
+				Trace:  trace,
+			})
+			provenance.ToolCalls = trace.ToolCalls
+			provenance.PromptMessageIDs = append(provenance.PromptMessageIDs, trace.MessageIDs...)
+			for _, segmentID := range trace.SegmentIDs {
+				provenance.RetrievedSources = append(provenance.RetrievedSources, model.AISource{
+					SourceType: model.EmbeddingSourceTranscript,
+					SourceID:   segmentID,
+				})
+			}
+		} else {
+			response, err = h.kolosalService.ChatCompletions(kolosalRequest)
+		}
 		if err != nil {
 			log.Printf("[KolosalAPI] Error calling Kolosal API: %v", err)
 			log.Printf("[KolosalAPI] Error details - Type: %T, Message: %s", err, err.Error())
@@ -548,6 +666,15 @@ func (h *ChatHandler) KolosalAPI(c *gin.Context) {
 		}
 	}
 
+	provenance.Model = response.Model
+	if provenance.Model == "" {
+		provenance.Model = modelName
+	}
+	provenance.PromptTokens = response.Usage.PromptTokens
+	provenance.CompletionTokens = response.Usage.CompletionTokens
+	provenance.TotalTokens = response.Usage.TotalTokens
+	provenance.LatencyMs = time.Since(startedAt).Milliseconds()
+
 	// Generate temporary ID for streaming message
 	tempID := fmt.Sprintf("ai-temp-%d", time.Now().UnixNano())
 
@@ -576,8 +703,8 @@ func (h *ChatHandler) KolosalAPI(c *gin.Context) {
 				"content":    accumulatedContent,
 				"chunk":      chunk,
 				"user_id":    userID.(string),
-				"user_name":  "AI Agent",
-				"user_email": "ai@agent.com",
+				"user_name":  service.AIAgentName,
+				"user_email": service.AIAgentEmail,
 			},
 		})
 
@@ -585,38 +712,49 @@ func (h *ChatHandler) KolosalAPI(c *gin.Context) {
 		time.Sleep(50 * time.Millisecond)
 	}
 
-	// Save AI message to database (persistent storage)
-	// Note: AI messages are saved with userID "ai-agent" - this user should exist in DB
-	// If user doesn't exist, CreateMessage will fail but we'll still broadcast via WebSocket
+	// Save AI message to database (persistent storage) under the AI agent user
+	// seeded on startup. If saving fails we'll still broadcast via WebSocket.
 	var aiMessage *service.ChatMessageResponse
-	aiUserID := "ai-agent" // Fixed user ID for AI agent
-	aiMessage, err = h.chatService.CreateMessage(roomID, aiUserID, aiResponse)
+	aiMessage, err = h.chatService.CreateAIMessage(roomID, aiResponse, provenance)
 	if err != nil {
-		log.Printf("[KolosalAPI] Error saving AI message (user 'ai-agent' may not exist): %v", err)
+		log.Printf("[KolosalAPI] Error saving AI message: %v", err)
 		log.Printf("[KolosalAPI] Message will still be broadcast via WebSocket but not persisted")
 		// Create a temporary message structure for WebSocket if DB save fails
 		aiMessage = &service.ChatMessageResponse{
-			ID:        fmt.Sprintf("ai-%d", time.Now().UnixNano()),
-			RoomID:    roomID,
-			UserID:    aiUserID,
-			UserName:  "AI Agent",
-			UserEmail: "ai@agent.com",
-			Message:   aiResponse,
-			CreatedAt: time.Now(),
+			ID:         fmt.Sprintf("ai-%d", time.Now().UnixNano()),
+			RoomID:     roomID,
+			UserID:     service.AIAgentUserID,
+			UserName:   service.AIAgentName,
+			UserEmail:  service.AIAgentEmail,
+			Message:    aiResponse,
+			Provenance: provenance,
+			CreatedAt:  time.Now(),
 		}
 	} else {
 		log.Printf("[KolosalAPI] AI message saved to database: %s", aiMessage.ID)
 	}
 
+	// OCR output is indexed so later questions can retrieve it, whether or
+	// not the message could be saved
+	if provenance.Mode == model.AIModeOCR {
+		sourceID := aiMessage.ID
+		go func() {
+			if err := h.ragService.IndexOCR(roomID, sourceID, aiResponse); err != nil {
+				log.Printf("[KolosalAPI] Failed to index OCR output: %v", err)
+			}
+		}()
+	}
+
 	// Broadcast AI complete with final message
 	h.hub.BroadcastMessage(roomID, &websocket.Message{
 		RoomID: roomID,
 		UserID: userID.(string),
 		Type:   "ai_complete",
 		Payload: map[string]interface{}{
-			"temp_id": tempID,
-			"user_id": userID.(string),
-			"message": aiMessage,
+			"temp_id":   tempID,
+			"user_id":   userID.(string),
+			"message":   aiMessage,
+			"citations": citations,
 		},
 	})
 
@@ -631,6 +769,9 @@ func (h *ChatHandler) KolosalAPI(c *gin.Context) {
 		"usage":    response.Usage,
 		"message":  aiMessage, // Include saved message in response
 	}
+	if len(citations) > 0 {
+		responseData["citations"] = citations
+	}
 
 	// Include history in response if agent mode
 	if req.UseAgent {
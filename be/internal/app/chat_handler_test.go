@@ -0,0 +1,35 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// signedOutAuth reports every session as signed out
+type signedOutAuth struct{ service.AuthService }
+
+func (signedOutAuth) IsSessionActive(sessionID string) bool { return false }
+
+func TestServeWebSocketRejectsSignedOutSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := util.NewHMACKeySet("secret")
+	token, err := util.GenerateAccessToken("user-1", "user@example.com", "member", "session-1", keys)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+
+	handler := NewChatHandler(nil, nil, nil, nil, nil, nil, signedOutAuth{}, nil, keys)
+	router := gin.New()
+	router.GET("/rooms/:id/chat/ws", handler.ServeWebSocket)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/rooms/room-1/chat/ws?token="+token, nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"net/http"
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InviteLinkHandler struct {
+	inviteLinkService service.InviteLinkService
+}
+
+func NewInviteLinkHandler(inviteLinkService service.InviteLinkService) *InviteLinkHandler {
+	return &InviteLinkHandler{
+		inviteLinkService: inviteLinkService,
+	}
+}
+
+// Create handles creating a shareable invite link
+// POST /api/v1/rooms/:id/invite-links
+func (h *InviteLinkHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.CreateInviteLinkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			util.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	link, err := h.inviteLinkService.Create(c.Param("id"), userID.(string), req)
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "Invite link created successfully", link)
+}
+
+// List handles listing the invite links of a room
+// GET /api/v1/rooms/:id/invite-links
+func (h *InviteLinkHandler) List(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	links, err := h.inviteLinkService.List(c.Param("id"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Invite links retrieved successfully", links)
+}
+
+// Revoke handles revoking an invite link
+// DELETE /api/v1/rooms/:id/invite-links/:linkId
+func (h *InviteLinkHandler) Revoke(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.inviteLinkService.Revoke(c.Param("id"), c.Param("linkId"), userID.(string)); err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Invite link revoked successfully", nil)
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"log"
+	"net/http"
+	"yourapp/internal/config"
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/webhook"
+)
+
+type LiveKitWebhookHandler struct {
+	webhookService service.LiveKitWebhookService
+	keyProvider    auth.KeyProvider
+}
+
+func NewLiveKitWebhookHandler(webhookService service.LiveKitWebhookService, cfg *config.Config) *LiveKitWebhookHandler {
+	return &LiveKitWebhookHandler{
+		webhookService: webhookService,
+		keyProvider:    auth.NewSimpleKeyProvider(cfg.LiveKitAPIKey, cfg.LiveKitAPISecret),
+	}
+}
+
+// Receive handles webhook events sent by the LiveKit server. The request is
+// signed with the LiveKit API secret (Authorization header + body checksum).
+// POST /api/v1/livekit/webhook
+func (h *LiveKitWebhookHandler) Receive(c *gin.Context) {
+	event, err := webhook.ReceiveWebhookEvent(c.Request, h.keyProvider)
+	if err != nil {
+		log.Printf("[LiveKitWebhook] Rejected webhook: %v", err)
+		util.Unauthorized(c, "Invalid webhook signature")
+		return
+	}
+
+	if err := h.webhookService.HandleEvent(event); err != nil {
+		log.Printf("[LiveKitWebhook] Failed to handle %s (%s): %v", event.Event, event.Id, err)
+		// Non-2xx makes LiveKit retry the delivery
+		util.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Webhook processed", nil)
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"net/http"
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LobbyHandler struct {
+	lobbyService service.LobbyService
+}
+
+func NewLobbyHandler(lobbyService service.LobbyService) *LobbyHandler {
+	return &LobbyHandler{
+		lobbyService: lobbyService,
+	}
+}
+
+// GetWaiting handles listing the users waiting in the lobby
+// GET /api/v1/rooms/:id/lobby
+func (h *LobbyHandler) GetWaiting(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	entries, err := h.lobbyService.GetWaiting(c.Param("id"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Lobby retrieved successfully", entries)
+}
+
+// UpdateLobby handles turning the lobby on or off
+// PATCH /api/v1/rooms/:id/lobby
+func (h *LobbyHandler) UpdateLobby(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.UpdateLobbyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.lobbyService.SetEnabled(c.Param("id"), userID.(string), *req.Enabled); err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Lobby updated successfully", gin.H{"lobby_enabled": *req.Enabled})
+}
+
+// Admit handles admitting waiting users, individually or all at once
+// POST /api/v1/rooms/:id/lobby/admit
+func (h *LobbyHandler) Admit(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.LobbyDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	entries, err := h.lobbyService.Admit(c.Param("id"), userID.(string), req)
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Participants admitted successfully", entries)
+}
+
+// Deny handles denying waiting users, individually or all at once
+// POST /api/v1/rooms/:id/lobby/deny
+func (h *LobbyHandler) Deny(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.LobbyDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	entries, err := h.lobbyService.Deny(c.Param("id"), userID.(string), req)
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Participants denied successfully", entries)
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModerationHandler struct {
+	moderationService service.ModerationService
+}
+
+func NewModerationHandler(moderationService service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+	}
+}
+
+// MuteParticipant handles a host or co-host muting a participant's tracks
+// POST /api/v1/rooms/:id/moderation/mute
+func (h *ModerationHandler) MuteParticipant(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.MuteParticipantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	result, err := h.moderationService.MuteParticipant(c.Param("id"), userID.(string), req)
+	if err != nil {
+		moderationError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Participant muted successfully", result)
+}
+
+// RemoveParticipant handles a host or co-host removing a participant from the meeting
+// POST /api/v1/rooms/:id/moderation/remove
+func (h *ModerationHandler) RemoveParticipant(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.RemoveParticipantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	result, err := h.moderationService.RemoveParticipant(c.Param("id"), userID.(string), req)
+	if err != nil {
+		moderationError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Participant removed successfully", result)
+}
+
+// EndMeeting handles the host ending the meeting for everyone
+// POST /api/v1/rooms/:id/moderation/end
+func (h *ModerationHandler) EndMeeting(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.moderationService.EndMeeting(c.Param("id"), userID.(string)); err != nil {
+		moderationError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Meeting ended successfully", nil)
+}
+
+// moderationError maps moderation service errors to HTTP status codes
+func moderationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRoomNotFound), errors.Is(err, service.ErrParticipantNotFound):
+		util.NotFound(c, err.Error())
+	case errors.Is(err, service.ErrRoomAccessDenied), errors.Is(err, service.ErrRoomPermissionDenied):
+		util.Forbidden(c, err.Error())
+	case errors.Is(err, service.ErrParticipantNotConnected), errors.Is(err, service.ErrRoomClosed),
+		errors.Is(err, service.ErrInvalidRoomTransition):
+		util.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, service.ErrLiveKitNotConfigured):
+		util.ErrorResponse(c, http.StatusServiceUnavailable, err.Error(), nil)
+	case errors.Is(err, service.ErrLiveKitRequestFailed):
+		util.ErrorResponse(c, http.StatusBadGateway, err.Error(), nil)
+	default:
+		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+	}
+}
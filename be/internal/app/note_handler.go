@@ -0,0 +1,161 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NoteHandler struct {
+	noteService service.NoteService
+}
+
+func NewNoteHandler(noteService service.NoteService) *NoteHandler {
+	return &NoteHandler{
+		noteService: noteService,
+	}
+}
+
+// GetNotes handles listing meeting notes for a room
+// GET /api/v1/rooms/:id/notes
+func (h *NoteHandler) GetNotes(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	notes, err := h.noteService.GetNotes(c.Param("id"), userID.(string))
+	if err != nil {
+		noteError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Notes retrieved successfully", notes)
+}
+
+// CreateNote handles creating a meeting note
+// POST /api/v1/rooms/:id/notes
+func (h *NoteHandler) CreateNote(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.CreateNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	note, err := h.noteService.CreateNote(c.Param("id"), userID.(string), req)
+	if err != nil {
+		noteError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "Note created successfully", note)
+}
+
+// GenerateNote handles drafting meeting notes from the room chat history with AI
+// POST /api/v1/rooms/:id/notes/generate
+func (h *NoteHandler) GenerateNote(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.GenerateNoteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			util.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	note, err := h.noteService.GenerateNote(c.Param("id"), userID.(string), req)
+	if err != nil {
+		noteError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "Notes generated successfully", note)
+}
+
+// GetNote handles getting a single meeting note
+// GET /api/v1/rooms/:id/notes/:noteId
+func (h *NoteHandler) GetNote(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	note, err := h.noteService.GetNote(c.Param("id"), c.Param("noteId"), userID.(string))
+	if err != nil {
+		noteError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Note retrieved successfully", note)
+}
+
+// UpdateNote handles updating a meeting note
+// PATCH /api/v1/rooms/:id/notes/:noteId
+func (h *NoteHandler) UpdateNote(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.UpdateNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	note, err := h.noteService.UpdateNote(c.Param("id"), c.Param("noteId"), userID.(string), req)
+	if err != nil {
+		noteError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Note updated successfully", note)
+}
+
+// DeleteNote handles deleting a meeting note
+// DELETE /api/v1/rooms/:id/notes/:noteId
+func (h *NoteHandler) DeleteNote(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.noteService.DeleteNote(c.Param("id"), c.Param("noteId"), userID.(string)); err != nil {
+		noteError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Note deleted successfully", nil)
+}
+
+// noteError maps note service errors to HTTP status codes
+func noteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRoomNotFound), errors.Is(err, service.ErrNoteNotFound):
+		util.NotFound(c, err.Error())
+	case errors.Is(err, service.ErrRoomAccessDenied):
+		util.Forbidden(c, err.Error())
+	case errors.Is(err, service.ErrNoteVersionConflict):
+		util.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+	default:
+		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+	}
+}
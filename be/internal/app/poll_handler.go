@@ -0,0 +1,121 @@
+package app
+
+import (
+	"net/http"
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PollHandler struct {
+	pollService service.PollService
+}
+
+func NewPollHandler(pollService service.PollService) *PollHandler {
+	return &PollHandler{
+		pollService: pollService,
+	}
+}
+
+// Create handles creating a poll in a room
+// POST /api/v1/rooms/:id/polls
+func (h *PollHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.CreatePollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	poll, err := h.pollService.Create(c.Param("id"), userID.(string), req)
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "Poll created successfully", poll)
+}
+
+// List handles listing the polls of a room with their results
+// GET /api/v1/rooms/:id/polls
+func (h *PollHandler) List(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	polls, err := h.pollService.List(c.Param("id"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Polls retrieved successfully", polls)
+}
+
+// Get handles getting a single poll with its results
+// GET /api/v1/rooms/:id/polls/:pollId
+func (h *PollHandler) Get(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	poll, err := h.pollService.Get(c.Param("id"), c.Param("pollId"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Poll retrieved successfully", poll)
+}
+
+// Vote handles casting or changing a vote
+// POST /api/v1/rooms/:id/polls/:pollId/votes
+func (h *PollHandler) Vote(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.VoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	poll, err := h.pollService.Vote(c.Param("id"), c.Param("pollId"), userID.(string), req)
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Vote recorded successfully", poll)
+}
+
+// Close handles closing a poll
+// POST /api/v1/rooms/:id/polls/:pollId/close
+func (h *PollHandler) Close(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	poll, err := h.pollService.Close(c.Param("id"), c.Param("pollId"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Poll closed successfully", poll)
+}
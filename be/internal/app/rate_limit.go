@@ -0,0 +1,52 @@
+package app
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Per-IP limits of the auth routes. Users behind one NAT share an IP, so
+// these are looser than the per-account limits in the auth service.
+var (
+	authAttemptIPLimit = util.RateLimit{Burst: 30, Period: 10 * time.Minute} // Password and OTP checks
+	emailSendIPLimit   = util.RateLimit{Burst: 10, Period: 10 * time.Minute} // Requests that send an email
+)
+
+// rateLimitByIP throttles a route per client IP
+func rateLimitByIP(limiter util.RateLimiter, name string, limit util.RateLimit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if allowed, retryAfter := limiter.Allow("ip:"+name+":"+c.ClientIP(), limit); !allowed {
+			tooManyRequests(c, retryAfter, "Too many requests, please try again later")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimited responds with 429 if err is a rate limit from a service
+func rateLimited(c *gin.Context, err error) bool {
+	var limitErr *service.RateLimitError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+	tooManyRequests(c, limitErr.RetryAfter, limitErr.Message)
+	return true
+}
+
+func tooManyRequests(c *gin.Context, retryAfter time.Duration, message string) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	util.ErrorResponse(c, http.StatusTooManyRequests, message, gin.H{"retry_after": seconds})
+}
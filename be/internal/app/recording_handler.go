@@ -0,0 +1,95 @@
+package app
+
+import (
+	"net/http"
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecordingHandler struct {
+	recordingService service.RecordingService
+}
+
+func NewRecordingHandler(recordingService service.RecordingService) *RecordingHandler {
+	return &RecordingHandler{
+		recordingService: recordingService,
+	}
+}
+
+// Start handles the host starting a recording of the live meeting
+// POST /api/v1/rooms/:id/recordings
+func (h *RecordingHandler) Start(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	recording, err := h.recordingService.Start(c.Param("id"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "Recording started successfully", recording)
+}
+
+// Stop handles the host stopping a recording
+// POST /api/v1/rooms/:id/recordings/:recordingId/stop
+func (h *RecordingHandler) Stop(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	recording, err := h.recordingService.Stop(c.Param("id"), c.Param("recordingId"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Recording stopped successfully", recording)
+}
+
+// List handles listing the recordings of a room
+// GET /api/v1/rooms/:id/recordings
+func (h *RecordingHandler) List(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	recordings, err := h.recordingService.List(c.Param("id"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Recordings retrieved successfully", recordings)
+}
+
+// Download handles downloading a finished recording
+// GET /api/v1/rooms/:id/recordings/:recordingId/download
+func (h *RecordingHandler) Download(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	download, err := h.recordingService.GetDownload(c.Param("id"), c.Param("recordingId"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	if download.Path != "" {
+		c.FileAttachment(download.Path, download.Filename)
+		return
+	}
+	c.Redirect(http.StatusFound, download.URL)
+}
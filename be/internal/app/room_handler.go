@@ -1,6 +1,7 @@
 package app
 
 import (
+	"errors"
 	"net/http"
 	"yourapp/internal/service"
 	"yourapp/internal/util"
@@ -52,7 +53,11 @@ func (h *RoomHandler) GetRoom(c *gin.Context) {
 		return
 	}
 
-	room, err := h.roomService.GetRoomByID(roomID)
+	// Optional authentication: private rooms are only shown to members
+	userID, _ := c.Get("userID")
+	viewerID, _ := userID.(string)
+
+	room, err := h.roomService.GetRoom(roomID, viewerID)
 	if err != nil {
 		util.ErrorResponse(c, http.StatusNotFound, err.Error(), nil)
 		return
@@ -61,41 +66,26 @@ func (h *RoomHandler) GetRoom(c *gin.Context) {
 	util.SuccessResponse(c, http.StatusOK, "Room retrieved successfully", room)
 }
 
-// GetRooms handles getting all rooms
-// GET /api/v1/rooms
+// GetRooms handles listing rooms with pagination, filters and sorting
+// GET /api/v1/rooms?page=&page_size=&active=&created_by=&has_capacity=&q=&sort=&view=
 func (h *RoomHandler) GetRooms(c *gin.Context) {
-	rooms, err := h.roomService.GetAllRooms()
-	if err != nil {
-		util.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+	var query service.ListRoomsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequest(c, err.Error())
 		return
 	}
 
-	util.SuccessResponse(c, http.StatusOK, "Rooms retrieved successfully", rooms)
-}
+	// Optional authentication: needed for created_by=me
+	userID, _ := c.Get("userID")
+	viewerID, _ := userID.(string)
 
-// GetRoomIDs handles getting all room IDs (for AI/chatbot to know available rooms)
-// GET /api/v1/rooms/ids
-func (h *RoomHandler) GetRoomIDs(c *gin.Context) {
-	rooms, err := h.roomService.GetAllRooms()
+	rooms, err := h.roomService.ListRooms(query, viewerID)
 	if err != nil {
-		util.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
 		return
 	}
 
-	// Extract only room IDs
-	roomIDs := make([]map[string]interface{}, 0, len(rooms))
-	for _, room := range rooms {
-		roomIDs = append(roomIDs, map[string]interface{}{
-			"id":          room.ID,
-			"name":        room.Name,
-			"description": room.Description,
-		})
-	}
-
-	util.SuccessResponse(c, http.StatusOK, "Room IDs retrieved successfully", gin.H{
-		"rooms": roomIDs,
-		"count": len(roomIDs),
-	})
+	util.SuccessResponse(c, http.StatusOK, "Rooms retrieved successfully", rooms)
 }
 
 // GetMyRooms handles getting rooms created by current user
@@ -131,13 +121,25 @@ func (h *RoomHandler) JoinRoom(c *gin.Context) {
 		return
 	}
 
+	// The body is optional; the invite token may also come from the share link
+	var req service.JoinRoomRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			util.BadRequest(c, err.Error())
+			return
+		}
+	}
+	if req.InviteToken == "" {
+		req.InviteToken = c.Query("invite")
+	}
+
 	// Log for debugging
 	c.Header("X-Debug-UserID", userID.(string))
 	c.Header("X-Debug-RoomID", roomID)
 
-	response, err := h.roomService.JoinRoom(roomID, userID.(string))
+	response, err := h.roomService.JoinRoom(roomID, userID.(string), req)
 	if err != nil {
-		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		roomError(c, err)
 		return
 	}
 
@@ -167,6 +169,30 @@ func (h *RoomHandler) LeaveRoom(c *gin.Context) {
 	util.SuccessResponse(c, http.StatusOK, "Left room successfully", nil)
 }
 
+// UpdateRoom handles the creator editing a room or changing its status
+// PATCH /api/v1/rooms/:id
+func (h *RoomHandler) UpdateRoom(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.UpdateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	room, err := h.roomService.UpdateRoom(c.Param("id"), userID.(string), req)
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Room updated successfully", room)
+}
+
 // DeleteRoom handles room deletion
 // DELETE /api/v1/rooms/:id
 func (h *RoomHandler) DeleteRoom(c *gin.Context) {
@@ -189,3 +215,93 @@ func (h *RoomHandler) DeleteRoom(c *gin.Context) {
 
 	util.SuccessResponse(c, http.StatusOK, "Room deleted successfully", nil)
 }
+
+// GetParticipants handles listing the participants of a room with their roles
+// GET /api/v1/rooms/:id/participants
+func (h *RoomHandler) GetParticipants(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	participants, err := h.roomService.GetParticipants(c.Param("id"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Participants retrieved successfully", participants)
+}
+
+// UpdateParticipantRole handles the host promoting or demoting a participant
+// PATCH /api/v1/rooms/:id/participants/:userId/role
+func (h *RoomHandler) UpdateParticipantRole(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.UpdateParticipantRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	participant, err := h.roomService.UpdateParticipantRole(c.Param("id"), userID.(string), c.Param("userId"), req.Role)
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Participant role updated successfully", participant)
+}
+
+// IssueRecorderToken handles issuing a hidden recorder token for bots
+// POST /api/v1/rooms/:id/recorder-token
+func (h *RoomHandler) IssueRecorderToken(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	token, err := h.roomService.IssueRecorderToken(c.Param("id"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Recorder token issued successfully", token)
+}
+
+// roomError maps room service errors to HTTP status codes
+func roomError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRoomNotFound), errors.Is(err, service.ErrParticipantNotFound),
+		errors.Is(err, service.ErrRecordingNotFound), errors.Is(err, service.ErrBreakoutNotFound),
+		errors.Is(err, service.ErrPollNotFound):
+		util.NotFound(c, err.Error())
+	case errors.Is(err, service.ErrRoomAccessDenied), errors.Is(err, service.ErrRoomPermissionDenied),
+		errors.Is(err, service.ErrMeetingNotStarted), errors.Is(err, service.ErrMeetingEnded),
+		errors.Is(err, service.ErrLobbyDenied), errors.Is(err, service.ErrPasscodeRequired),
+		errors.Is(err, service.ErrInvalidPasscode), errors.Is(err, service.ErrInviteRequired),
+		errors.Is(err, service.ErrInviteLinkInvalid), errors.Is(err, service.ErrBreakoutNotAssigned),
+		errors.Is(err, service.ErrRemovedFromRoom):
+		util.Forbidden(c, err.Error())
+	case errors.Is(err, service.ErrRoomNotScheduled), errors.Is(err, service.ErrRoomFull),
+		errors.Is(err, service.ErrRoomClosed), errors.Is(err, service.ErrInvalidRoomTransition),
+		errors.Is(err, service.ErrRoomNotLive), errors.Is(err, service.ErrRecordingInProgress),
+		errors.Is(err, service.ErrRecordingNotRunning), errors.Is(err, service.ErrRecordingNotReady),
+		errors.Is(err, service.ErrBreakoutsOpen), errors.Is(err, service.ErrNoBreakouts),
+		errors.Is(err, service.ErrPollClosed):
+		util.ErrorResponse(c, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, service.ErrLiveKitNotConfigured):
+		util.ErrorResponse(c, http.StatusServiceUnavailable, err.Error(), nil)
+	case errors.Is(err, service.ErrLiveKitRequestFailed):
+		util.ErrorResponse(c, http.StatusBadGateway, err.Error(), nil)
+	default:
+		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+	}
+}
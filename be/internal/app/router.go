@@ -15,6 +15,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func NewRouter(cfg *config.Config) *gin.Engine {
@@ -27,6 +28,12 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 
 	r := gin.Default()
 
+	// Only trust forwarding headers from configured proxies, so ClientIP (and
+	// the per-IP rate limits) cannot be spoofed with X-Forwarded-For
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// CORS middleware - allow multiple origins
 	allowedOrigins := []string{
 		cfg.ClientURL,
@@ -63,14 +70,34 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 	}
 
 	// Auto migrate
-	if err := db.AutoMigrate(&model.User{}, &model.Room{}, &model.RoomParticipant{}, &model.ChatMessage{}); err != nil {
+	if err := db.AutoMigrate(&model.User{}, &model.Room{}, &model.RoomParticipant{}, &model.ChatMessage{}, &model.MeetingNote{}, &model.ActionItem{}, &model.TranscriptSegment{}, &model.EmbeddingChunk{}, &model.RoomInvitation{}, &model.LobbyEntry{}, &model.RoomInviteLink{}, &model.ParticipantSession{}, &model.Recording{}, &model.BreakoutAssignment{}, &model.Poll{}, &model.PollOption{}, &model.PollVote{}, &model.RefreshToken{}, &model.Session{}); err != nil {
 		panic("Failed to migrate database: " + err.Error())
 	}
+	if err := migrateRoomStatus(db); err != nil {
+		panic("Failed to migrate room status: " + err.Error())
+	}
+	if err := backfillParticipantSessions(db); err != nil {
+		panic("Failed to backfill participant sessions: " + err.Error())
+	}
+	if err := seedAIAgentUser(db); err != nil {
+		panic("Failed to create the AI agent user: " + err.Error())
+	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
 	roomRepo := repository.NewRoomRepository(db)
 	chatRepo := repository.NewChatRepository(db)
+	noteRepo := repository.NewNoteRepository(db)
+	transcriptRepo := repository.NewTranscriptRepository(db)
+	embeddingRepo := repository.NewEmbeddingRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	lobbyRepo := repository.NewLobbyRepository(db)
+	inviteLinkRepo := repository.NewInviteLinkRepository(db)
+	recordingRepo := repository.NewRecordingRepository(db)
+	breakoutRepo := repository.NewBreakoutRepository(db)
+	pollRepo := repository.NewPollRepository(db)
 
 	// Initialize RabbitMQ with retry logic
 	rabbitMQ := initRabbitMQWithRetry(cfg)
@@ -93,10 +120,47 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 		// No need for continuous polling - lazy reconnection is more efficient
 	}
 
+	// Initialize WebSocket hub
+	wsHub := websocket.NewHub()
+	go wsHub.Run()
+
+	// Share raise-hand queues and rate limits between instances through Redis when available
+	rateLimiter := util.NewMemoryRateLimiter()
+	if redisClient, err := util.NewRedisClient(cfg); err != nil {
+		log.Printf("Warning: %v. Raise-hand queues and rate limits will be kept in memory.", err)
+	} else {
+		wsHub.SetHandStore(websocket.NewRedisHandStore(redisClient))
+		rateLimiter = util.NewRedisRateLimiter(redisClient)
+		log.Println("Raise-hand queues and rate limits are stored in Redis")
+	}
+
+	// Keys that sign and verify auth tokens
+	jwtKeys, err := util.LoadJWTKeySet(cfg)
+	if err != nil {
+		panic("Failed to load JWT signing keys: " + err.Error())
+	}
+
 	// Initialize services
-	authService := service.NewAuthServiceWithConfig(userRepo, cfg.JWTSecret, rabbitMQ, cfg)
-	roomService := service.NewRoomService(roomRepo, userRepo, cfg)
-	chatService := service.NewChatService(chatRepo, roomRepo, userRepo)
+	authService := service.NewAuthServiceWithConfig(userRepo, refreshTokenRepo, sessionRepo, jwtKeys, rabbitMQ, rateLimiter, cfg)
+	scheduleService := service.NewScheduleService(roomRepo, userRepo, invitationRepo, emailService, rabbitMQ, cfg)
+	inviteLinkService := service.NewInviteLinkService(inviteLinkRepo, roomRepo, cfg)
+	lobbyService := service.NewLobbyService(lobbyRepo, roomRepo, userRepo, inviteLinkService, cfg, wsHub)
+	attendanceService := service.NewAttendanceService(roomRepo)
+	// LiveKit server API for moderating live meetings
+	liveKitAPIURL := cfg.LiveKitAPIURL
+	if liveKitAPIURL == "" {
+		liveKitAPIURL = cfg.LiveKitURL
+	}
+	liveKitService := service.NewLiveKitService(liveKitAPIURL, cfg.LiveKitAPIKey, cfg.LiveKitAPISecret)
+	roomService := service.NewRoomService(roomRepo, userRepo, cfg, wsHub, scheduleService, lobbyService, inviteLinkService, liveKitService)
+
+	// Send reminders for upcoming scheduled meetings
+	meetingScheduler := service.NewMeetingScheduler(scheduleService, time.Minute)
+	meetingScheduler.Start()
+
+	// End idle rooms, archive ended ones and purge them after the retention period
+	roomJanitor := service.NewRoomJanitor(roomRepo, wsHub, cfg, nil)
+	roomJanitor.Start()
 
 	// Initialize Kolosal service with validation
 	log.Printf("[ROUTER] Initializing Kolosal Service...")
@@ -108,14 +172,42 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 	}
 	kolosalService := service.NewKolosalService(cfg.KolosalAPIURL, cfg.KolosalAPIKey)
 
-	// Initialize WebSocket hub
-	wsHub := websocket.NewHub()
-	go wsHub.Run()
+	// Initialize retrieval over room history
+	embeddingProvider := service.NewEmbeddingProvider(cfg.EmbeddingProvider, cfg.EmbeddingModel, cfg.EmbeddingDimensions, kolosalService)
+	log.Printf("[ROUTER] Embedding provider: %s", embeddingProvider.Name())
+	ragService := service.NewRAGService(embeddingRepo, chatRepo, transcriptRepo, roomRepo, embeddingProvider, cfg.RAGTopK)
+	chatService := service.NewChatService(chatRepo, roomRepo, userRepo, ragService)
+
+	// Services that broadcast real-time events need the hub
+	transcriptService := service.NewTranscriptService(transcriptRepo, roomRepo, userRepo, ragService, wsHub)
+	pollService := service.NewPollService(pollRepo, roomRepo, wsHub)
+	pollService.Start()
+	noteService := service.NewNoteService(noteRepo, roomRepo, chatRepo, userRepo, kolosalService, transcriptService, pollService, wsHub)
+	moderationService := service.NewModerationService(roomRepo, userRepo, liveKitService, wsHub)
+	wsHub.SetModeratorCheck(moderationService.IsModerator)
+	wsHub.SetRemovedCheck(moderationService.IsRemoved)
+	breakoutService := service.NewBreakoutService(breakoutRepo, roomRepo, userRepo, liveKitService, cfg, wsHub)
+	breakoutService.Start()
+	recordingService := service.NewRecordingService(recordingRepo, roomRepo, liveKitService, cfg, wsHub)
+	liveKitWebhookService := service.NewLiveKitWebhookService(roomRepo, userRepo, recordingService, liveKitService, wsHub)
+	agentToolService := service.NewAgentToolService(kolosalService, roomRepo, chatRepo, noteService, transcriptService)
 
 	// Initialize handlers
-	authHandler := NewAuthHandler(authService, cfg.JWTSecret)
+	authHandler := NewAuthHandler(authService, jwtKeys)
 	roomHandler := NewRoomHandler(roomService)
-	chatHandler := NewChatHandler(chatService, kolosalService, roomService, wsHub, cfg.JWTSecret)
+	scheduleHandler := NewScheduleHandler(scheduleService)
+	lobbyHandler := NewLobbyHandler(lobbyService)
+	inviteLinkHandler := NewInviteLinkHandler(inviteLinkService)
+	attendanceHandler := NewAttendanceHandler(attendanceService)
+	recordingHandler := NewRecordingHandler(recordingService)
+	breakoutHandler := NewBreakoutHandler(breakoutService)
+	pollHandler := NewPollHandler(pollService)
+	chatHandler := NewChatHandler(chatService, kolosalService, roomService, agentToolService, transcriptService, ragService, authService, wsHub, jwtKeys)
+	noteHandler := NewNoteHandler(noteService)
+	transcriptHandler := NewTranscriptHandler(transcriptService, cfg)
+	searchHandler := NewSearchHandler(ragService)
+	moderationHandler := NewModerationHandler(moderationService)
+	liveKitWebhookHandler := NewLiveKitWebhookHandler(liveKitWebhookService, cfg)
 
 	// API routes
 	api := r.Group("/api/v1")
@@ -123,27 +215,38 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 		// Auth routes
 		auth := api.Group("/auth")
 		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
-			auth.POST("/verify-otp", authHandler.VerifyOTP)
-			auth.POST("/resend-otp", authHandler.ResendOTP)
-			auth.POST("/google-oauth", authHandler.GoogleOAuth)
-			auth.POST("/refresh-token", authHandler.RefreshToken)
-			auth.POST("/forgot-password", authHandler.RequestResetPassword)
-			auth.POST("/verify-reset-password", authHandler.VerifyResetPassword)
-			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.POST("/register", rateLimitByIP(rateLimiter, "register", emailSendIPLimit), authHandler.Register)
+			auth.POST("/login", rateLimitByIP(rateLimiter, "login", authAttemptIPLimit), authHandler.Login)
+			auth.POST("/verify-otp", rateLimitByIP(rateLimiter, "verify_otp", authAttemptIPLimit), authHandler.VerifyOTP)
+			auth.POST("/resend-otp", rateLimitByIP(rateLimiter, "send_otp", emailSendIPLimit), authHandler.ResendOTP)
+			auth.POST("/google-oauth", rateLimitByIP(rateLimiter, "google_oauth", authAttemptIPLimit), authHandler.GoogleOAuth)
+			auth.POST("/refresh-token", rateLimitByIP(rateLimiter, "refresh_token", authAttemptIPLimit), authHandler.RefreshToken)
+			auth.POST("/logout", authHandler.Logout)
+			auth.POST("/forgot-password", rateLimitByIP(rateLimiter, "send_otp", emailSendIPLimit), authHandler.RequestResetPassword)
+			auth.POST("/verify-reset-password", rateLimitByIP(rateLimiter, "verify_otp", authAttemptIPLimit), authHandler.VerifyResetPassword)
+			auth.POST("/reset-password", rateLimitByIP(rateLimiter, "reset_password", authAttemptIPLimit), authHandler.ResetPassword)
 			auth.POST("/verify-email", authHandler.VerifyEmail)
 
 			// Protected routes
 			auth.GET("/me", authHandler.AuthMiddleware(), authHandler.GetMe)
+			auth.POST("/logout-all", authHandler.AuthMiddleware(), authHandler.LogoutAll)
+			auth.GET("/sessions", authHandler.AuthMiddleware(), authHandler.ListSessions)
+			auth.DELETE("/sessions/:id", authHandler.AuthMiddleware(), authHandler.RevokeSession)
+		}
+
+		// LiveKit server webhooks (signed with the LiveKit API secret). Without
+		// a secret any request would pass the signature check.
+		if cfg.LiveKitAPISecret != "" {
+			api.POST("/livekit/webhook", liveKitWebhookHandler.Receive)
+		} else {
+			log.Println("[LiveKitWebhook] LIVEKIT_API_SECRET is not set; webhook endpoint disabled")
 		}
 
 		// Room routes
 		rooms := api.Group("/rooms")
 		{
 			// Public routes
-			rooms.GET("", roomHandler.GetRooms)
-			rooms.GET("/ids", roomHandler.GetRoomIDs)                              // Get all room IDs for AI
+			rooms.GET("", authHandler.OptionalAuthMiddleware(), roomHandler.GetRooms)
 			rooms.GET("/my", authHandler.AuthMiddleware(), roomHandler.GetMyRooms) // Specific route before :id
 
 			// Protected routes
@@ -157,17 +260,90 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 			log.Println("[ROUTER] ✓ kolosal route registered successfully in rooms group")
 
 			// Other chat routes
-			rooms.GET("/:id/messages", chatHandler.GetMessages)
+			rooms.GET("/:id/messages", authHandler.AuthMiddleware(), chatHandler.GetMessages)
 			rooms.POST("/:id/messages", authHandler.AuthMiddleware(), chatHandler.CreateMessage)
 			rooms.GET("/:id/chat/ws", chatHandler.ServeWebSocket)
 
+			// Meeting notes routes
+			rooms.GET("/:id/notes", authHandler.AuthMiddleware(), noteHandler.GetNotes)
+			rooms.POST("/:id/notes", authHandler.AuthMiddleware(), noteHandler.CreateNote)
+			rooms.POST("/:id/notes/generate", authHandler.AuthMiddleware(), noteHandler.GenerateNote)
+			rooms.GET("/:id/notes/:noteId", authHandler.AuthMiddleware(), noteHandler.GetNote)
+			rooms.PATCH("/:id/notes/:noteId", authHandler.AuthMiddleware(), noteHandler.UpdateNote)
+			rooms.DELETE("/:id/notes/:noteId", authHandler.AuthMiddleware(), noteHandler.DeleteNote)
+
+			// Transcript routes
+			rooms.GET("/:id/transcripts", authHandler.AuthMiddleware(), transcriptHandler.GetSegments)
+			rooms.POST("/:id/transcripts", authHandler.AuthMiddleware(), transcriptHandler.IngestSegments)
+			rooms.POST("/:id/transcripts/agent", transcriptHandler.IngestAgentSegments) // LiveKit token auth
+
+			// Semantic search routes
+			rooms.GET("/:id/search", authHandler.AuthMiddleware(), searchHandler.Search)
+			rooms.POST("/:id/index", authHandler.AuthMiddleware(), searchHandler.Reindex)
+
+			// Participant and role routes
+			rooms.GET("/:id/participants", authHandler.AuthMiddleware(), roomHandler.GetParticipants)
+			rooms.PATCH("/:id/participants/:userId/role", authHandler.AuthMiddleware(), roomHandler.UpdateParticipantRole)
+			rooms.POST("/:id/recorder-token", authHandler.AuthMiddleware(), roomHandler.IssueRecorderToken)
+
+			// Scheduled meeting routes
+			rooms.POST("/:id/invitations", authHandler.AuthMiddleware(), scheduleHandler.Invite)
+			rooms.GET("/:id/invitations", authHandler.AuthMiddleware(), scheduleHandler.GetInvitations)
+			rooms.GET("/:id/calendar.ics", authHandler.AuthMiddleware(), scheduleHandler.GetCalendar)
+
+			// Invite link routes (host / co-host)
+			rooms.POST("/:id/invite-links", authHandler.AuthMiddleware(), inviteLinkHandler.Create)
+			rooms.GET("/:id/invite-links", authHandler.AuthMiddleware(), inviteLinkHandler.List)
+			rooms.DELETE("/:id/invite-links/:linkId", authHandler.AuthMiddleware(), inviteLinkHandler.Revoke)
+
+			// Attendance report (JSON or CSV with ?format=csv)
+			rooms.GET("/:id/attendance", authHandler.AuthMiddleware(), attendanceHandler.GetAttendance)
+
+			// Recording routes (host)
+			rooms.POST("/:id/recordings", authHandler.AuthMiddleware(), recordingHandler.Start)
+			rooms.GET("/:id/recordings", authHandler.AuthMiddleware(), recordingHandler.List)
+			rooms.POST("/:id/recordings/:recordingId/stop", authHandler.AuthMiddleware(), recordingHandler.Stop)
+			rooms.GET("/:id/recordings/:recordingId/download", authHandler.AuthMiddleware(), recordingHandler.Download)
+
+			// Breakout room routes (host / co-host, except viewing and joining)
+			rooms.POST("/:id/breakouts", authHandler.AuthMiddleware(), breakoutHandler.Create)
+			rooms.GET("/:id/breakouts", authHandler.AuthMiddleware(), breakoutHandler.Get)
+			rooms.DELETE("/:id/breakouts", authHandler.AuthMiddleware(), breakoutHandler.Close)
+			rooms.PUT("/:id/breakouts/assignments", authHandler.AuthMiddleware(), breakoutHandler.Assign)
+			rooms.POST("/:id/breakouts/:breakoutId/join", authHandler.AuthMiddleware(), breakoutHandler.Join)
+
+			// Poll routes (host / co-host create and close, everyone votes)
+			rooms.POST("/:id/polls", authHandler.AuthMiddleware(), pollHandler.Create)
+			rooms.GET("/:id/polls", authHandler.AuthMiddleware(), pollHandler.List)
+			rooms.GET("/:id/polls/:pollId", authHandler.AuthMiddleware(), pollHandler.Get)
+			rooms.POST("/:id/polls/:pollId/votes", authHandler.AuthMiddleware(), pollHandler.Vote)
+			rooms.POST("/:id/polls/:pollId/close", authHandler.AuthMiddleware(), pollHandler.Close)
+
+			// Lobby routes (host / co-host)
+			lobby := rooms.Group("/:id/lobby", authHandler.AuthMiddleware())
+			{
+				lobby.GET("", lobbyHandler.GetWaiting)
+				lobby.PATCH("", lobbyHandler.UpdateLobby)
+				lobby.POST("/admit", lobbyHandler.Admit)
+				lobby.POST("/deny", lobbyHandler.Deny)
+			}
+
+			// Moderation routes (host / co-host)
+			moderation := rooms.Group("/:id/moderation", authHandler.AuthMiddleware())
+			{
+				moderation.POST("/mute", moderationHandler.MuteParticipant)
+				moderation.POST("/remove", moderationHandler.RemoveParticipant)
+				moderation.POST("/end", moderationHandler.EndMeeting)
+			}
+
 			// Room action routes
 			rooms.POST("/:id/join", authHandler.AuthMiddleware(), roomHandler.JoinRoom)
 			rooms.POST("/:id/leave", authHandler.AuthMiddleware(), roomHandler.LeaveRoom)
+			rooms.PATCH("/:id", authHandler.AuthMiddleware(), roomHandler.UpdateRoom)
 			rooms.DELETE("/:id", authHandler.AuthMiddleware(), roomHandler.DeleteRoom)
 
 			// General :id route - must be last
-			rooms.GET("/:id", roomHandler.GetRoom)
+			rooms.GET("/:id", authHandler.OptionalAuthMiddleware(), roomHandler.GetRoom)
 		}
 	}
 
@@ -176,6 +352,9 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Public keys for verifying auth tokens
+	r.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	// NoRoute handler - catch 404 and check if it's test-kolosal
 	// This is a fallback in case the route is not registered properly
 	log.Println("[ROUTER] Registering NoRoute handler...")
@@ -258,6 +437,53 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 	return r
 }
 
+// migrateRoomStatus replaces the old rooms.is_active flag with lifecycle
+// states. Inactive rooms become ended; active ones are live, or scheduled if
+// their meeting hasn't started yet or recurs.
+func migrateRoomStatus(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&model.Room{}, "is_active") {
+		return nil
+	}
+
+	err := db.Exec(`UPDATE rooms SET status = CASE
+		WHEN NOT is_active THEN ?
+		WHEN scheduled_start IS NOT NULL AND (scheduled_start > NOW() OR recurrence_rule <> '') THEN ?
+		ELSE ? END`, model.RoomEnded, model.RoomScheduled, model.RoomLive).Error
+	if err != nil {
+		return err
+	}
+	return db.Migrator().DropColumn(&model.Room{}, "is_active")
+}
+
+// backfillParticipantSessions gives participants recorded before attendance
+// sessions existed one session from their last join. Participants that
+// already have sessions are skipped, so this is safe to run on every start.
+func backfillParticipantSessions(db *gorm.DB) error {
+	return db.Exec(`INSERT INTO participant_sessions (id, room_id, user_id, joined_at, left_at)
+		SELECT gen_random_uuid(), rp.room_id, rp.user_id, rp.joined_at, CASE WHEN rp.is_active THEN NULL ELSE COALESCE(rp.left_at, rp.joined_at) END
+		FROM room_participants rp
+		WHERE NOT EXISTS (
+			SELECT 1 FROM participant_sessions ps
+			WHERE ps.room_id = rp.room_id AND ps.user_id = rp.user_id
+		)`).Error
+}
+
+// seedAIAgentUser creates the user AI answers are saved under. It is
+// inactive and has no password, so nobody can sign in as it.
+func seedAIAgentUser(db *gorm.DB) error {
+	user := model.User{
+		ID:         service.AIAgentUserID,
+		Email:      service.AIAgentEmail,
+		FullName:   service.AIAgentName,
+		UserType:   "system",
+		LoginType:  "system",
+		IsActive:   false,
+		IsVerified: true,
+	}
+	// Select("*") so the false IsActive is written instead of the column default
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Select("*").Create(&user).Error
+}
+
 func initDB(cfg *config.Config) (*gorm.DB, error) {
 	dsn := cfg.DatabaseURL
 	if dsn == "" {
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleHandler struct {
+	scheduleService service.ScheduleService
+}
+
+func NewScheduleHandler(scheduleService service.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// Invite handles inviting emails to a scheduled meeting
+// POST /api/v1/rooms/:id/invitations
+func (h *ScheduleHandler) Invite(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.InviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	invitations, err := h.scheduleService.Invite(c.Param("id"), userID.(string), req.Emails)
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "Invitations sent successfully", invitations)
+}
+
+// GetInvitations handles listing the invite list of a meeting
+// GET /api/v1/rooms/:id/invitations
+func (h *ScheduleHandler) GetInvitations(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	invitations, err := h.scheduleService.GetInvitations(c.Param("id"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Invitations retrieved successfully", invitations)
+}
+
+// GetCalendar handles downloading the iCalendar file of a scheduled meeting
+// GET /api/v1/rooms/:id/calendar.ics
+func (h *ScheduleHandler) GetCalendar(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	ics, err := h.scheduleService.GetCalendar(c.Param("id"), userID.(string))
+	if err != nil {
+		roomError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"meeting-%s.ics\"", c.Param("id")))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(ics))
+}
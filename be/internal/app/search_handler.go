@@ -0,0 +1,84 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	ragService service.RAGService
+}
+
+func NewSearchHandler(ragService service.RAGService) *SearchHandler {
+	return &SearchHandler{
+		ragService: ragService,
+	}
+}
+
+// Search handles semantic search over the room history (chat, transcript, OCR)
+// GET /api/v1/rooms/:id/search?q=&k=
+func (h *SearchHandler) Search(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	query := c.Query("q")
+	if query == "" {
+		util.BadRequest(c, "q is required")
+		return
+	}
+
+	k := 0
+	if kStr := c.Query("k"); kStr != "" {
+		if parsed, err := strconv.Atoi(kStr); err == nil && parsed > 0 {
+			k = parsed
+		}
+	}
+
+	snippets, err := h.ragService.Search(c.Param("id"), userID.(string), query, k)
+	if err != nil {
+		searchError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Search completed successfully", snippets)
+}
+
+// Reindex handles rebuilding the embeddings of a room's chat and transcript
+// POST /api/v1/rooms/:id/index
+func (h *SearchHandler) Reindex(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	indexed, err := h.ragService.ReindexRoom(c.Param("id"), userID.(string))
+	if err != nil {
+		searchError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Room indexed successfully", gin.H{
+		"indexed": indexed,
+	})
+}
+
+// searchError maps retrieval service errors to HTTP status codes
+func searchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRoomNotFound):
+		util.NotFound(c, err.Error())
+	case errors.Is(err, service.ErrRoomAccessDenied), errors.Is(err, service.ErrRoomPermissionDenied):
+		util.Forbidden(c, err.Error())
+	default:
+		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+	}
+}
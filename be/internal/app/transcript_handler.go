@@ -0,0 +1,141 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/service"
+	"yourapp/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TranscriptHandler struct {
+	transcriptService service.TranscriptService
+	cfg               *config.Config
+}
+
+func NewTranscriptHandler(transcriptService service.TranscriptService, cfg *config.Config) *TranscriptHandler {
+	return &TranscriptHandler{
+		transcriptService: transcriptService,
+		cfg:               cfg,
+	}
+}
+
+// IngestSegments handles a participant pushing transcript segments of their own speech
+// POST /api/v1/rooms/:id/transcripts
+func (h *TranscriptHandler) IngestSegments(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.IngestTranscriptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	segments, err := h.transcriptService.IngestSegments(c.Param("id"), service.TranscriptSource{
+		UserID: userID.(string),
+	}, req.Segments)
+	if err != nil {
+		transcriptError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "Transcript segments received", segments)
+}
+
+// IngestAgentSegments handles a LiveKit agent pushing transcript segments for any speaker.
+// The agent authenticates with a recorder token for the room (hidden recorder
+// grant signed with the LiveKit API secret). The roomAdmin grant that hosts and
+// co-hosts join with is not enough.
+// POST /api/v1/rooms/:id/transcripts/agent
+func (h *TranscriptHandler) IngestAgentSegments(c *gin.Context) {
+	roomID := c.Param("id")
+
+	authHeader := c.GetHeader("Authorization")
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		util.Unauthorized(c, "Authorization header required")
+		return
+	}
+
+	claims, err := util.VerifyLiveKitToken(parts[1], h.cfg.LiveKitAPIKey, h.cfg.LiveKitAPISecret)
+	if err != nil {
+		util.Unauthorized(c, err.Error())
+		return
+	}
+	if claims.Video == nil || claims.Video.Room != roomID || !claims.Video.Hidden || !claims.Video.Recorder {
+		util.Forbidden(c, "LiveKit token is not a recorder token for this room")
+		return
+	}
+
+	var req service.IngestTranscriptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	segments, err := h.transcriptService.IngestSegments(roomID, service.TranscriptSource{
+		IsAgent: true,
+	}, req.Segments)
+	if err != nil {
+		transcriptError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusCreated, "Transcript segments received", segments)
+}
+
+// GetSegments handles getting the stored transcript of a room
+// GET /api/v1/rooms/:id/transcripts?since=RFC3339&limit=
+func (h *TranscriptHandler) GetSegments(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		util.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	limit := 200
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= 1000 {
+			limit = parsed
+		}
+	}
+
+	var since *time.Time
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		parsed, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			util.BadRequest(c, "since must be an RFC3339 timestamp")
+			return
+		}
+		since = &parsed
+	}
+
+	segments, err := h.transcriptService.GetSegments(c.Param("id"), userID.(string), since, limit)
+	if err != nil {
+		transcriptError(c, err)
+		return
+	}
+
+	util.SuccessResponse(c, http.StatusOK, "Transcript retrieved successfully", segments)
+}
+
+// transcriptError maps transcript service errors to HTTP status codes
+func transcriptError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRoomNotFound):
+		util.NotFound(c, err.Error())
+	case errors.Is(err, service.ErrRoomAccessDenied):
+		util.Forbidden(c, err.Error())
+	default:
+		util.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+	}
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livekit/protocol/auth"
+)
+
+// agentTranscripts counts the agent segments that got through
+type agentTranscripts struct {
+	service.TranscriptService
+	ingested int
+}
+
+func (s *agentTranscripts) IngestSegments(roomID string, source service.TranscriptSource, segments []service.TranscriptSegmentRequest) ([]service.TranscriptSegmentResponse, error) {
+	s.ingested += len(segments)
+	return nil, nil
+}
+
+func TestIngestAgentSegmentsRequiresRecorderToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{LiveKitAPIKey: "test-key", LiveKitAPISecret: "test-secret-that-is-long-enough-for-hs256"}
+	transcripts := &agentTranscripts{}
+	router := gin.New()
+	router.POST("/rooms/:id/transcripts/agent", NewTranscriptHandler(transcripts, cfg).IngestAgentSegments)
+
+	token := func(grant *auth.VideoGrant) string {
+		jwt, err := auth.NewAccessToken(cfg.LiveKitAPIKey, cfg.LiveKitAPISecret).
+			AddGrant(grant).
+			SetIdentity("bot").
+			SetValidFor(time.Minute).
+			ToJWT()
+		if err != nil {
+			t.Fatalf("ToJWT: %v", err)
+		}
+		return jwt
+	}
+	body := `{"segments":[{"speaker_identity":"alice","text":"hello","start_time":"2026-01-01T10:00:00Z","end_time":"2026-01-01T10:00:02Z"}]}`
+
+	tests := []struct {
+		name  string
+		grant *auth.VideoGrant
+		want  int
+	}{
+		{"host join token", &auth.VideoGrant{RoomJoin: true, Room: "room-1", RoomAdmin: true}, http.StatusForbidden},
+		{"recorder token of another room", &auth.VideoGrant{RoomJoin: true, Room: "room-2", RoomAdmin: true, Hidden: true, Recorder: true}, http.StatusForbidden},
+		{"recorder token", &auth.VideoGrant{RoomJoin: true, Room: "room-1", RoomAdmin: true, Hidden: true, Recorder: true}, http.StatusCreated},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/rooms/room-1/transcripts/agent", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token(tc.grant))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tc.name, w.Code, tc.want, w.Body)
+		}
+	}
+	if transcripts.ingested != 1 {
+		t.Errorf("ingested %d segments, want only the recorder's", transcripts.ingested)
+	}
+}
@@ -3,7 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +16,8 @@ type Config struct {
 	ServerHost string
 	ClientURL  string
 
+	TrustedProxies []string // Reverse proxies whose X-Forwarded-For is trusted for client IPs
+
 	// Database
 	PostgresHost     string
 	PostgresPort     string
@@ -24,11 +28,17 @@ type Config struct {
 	DatabaseURL      string
 
 	// JWT
-	JWTSecret string
+	JWTSecret           string
+	JWTPrivateKeyFile   string   // PEM RSA or Ed25519 key to sign tokens with; HS256 with JWTSecret if empty
+	JWTPreviousKeyFiles []string // Keys rotated out, still accepted until the tokens they signed expire
+	JWTSecretRetiredAt  string   // RFC3339 time JWT_SECRET stopped signing; its tokens are accepted for a refresh TTL after
 
 	// Google OAuth
 	GoogleClientID     string
 	GoogleClientSecret string
+	GoogleRedirectURL  string // Redirect URI the client used to obtain auth codes ("postmessage" for popup sign-in)
+	GoogleJWKSURL      string // Keys used to verify Google ID tokens
+	GoogleTokenURL     string // Endpoint that exchanges auth codes for ID tokens
 
 	// Redis
 	RedisHost     string
@@ -49,14 +59,34 @@ type Config struct {
 	SMTPUsername string
 	SMTPPassword string
 
+	// Scheduled meetings
+	MeetingReminderLead time.Duration // How long before a meeting starts reminders are sent
+	MeetingEarlyJoin    time.Duration // How early invitees may join before the scheduled start
+
+	// Room cleanup
+	RoomIdleGrace       time.Duration // How long a live room may stay empty before it is ended
+	RoomArchiveAfter    time.Duration // How long an ended room is kept before it is archived or deleted
+	RoomCleanupAction   string        // What happens to ended rooms: "archive" (default) or "delete" (purge with all their data)
+	RoomRetention       time.Duration // How long archived rooms are kept before they are purged with their messages
+	RoomJanitorInterval time.Duration
+
 	// LiveKit
 	LiveKitURL       string
+	LiveKitAPIURL    string // HTTP(S) URL of the LiveKit server API, derived from LiveKitURL if empty
 	LiveKitAPIKey    string
 	LiveKitAPISecret string
+	LiveKitTokenTTL  time.Duration // Validity of LiveKit join tokens
+	RecordingsDir    string        // Local mount of the egress file storage, used to serve recording downloads
 
 	// Kolosal AI
 	KolosalAPIURL string
 	KolosalAPIKey string
+
+	// Embeddings / retrieval (RAG)
+	EmbeddingProvider   string // "local" (deterministic hashing embedder) or "kolosal"
+	EmbeddingModel      string
+	EmbeddingDimensions int
+	RAGTopK             int
 }
 
 func Load() (*Config, error) {
@@ -69,6 +99,8 @@ func Load() (*Config, error) {
 		ServerHost: getEnv("SERVER_HOST", "0.0.0.0"),
 		ClientURL:  getEnv("CLIENT_URL", "http://localhost:3000"),
 
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
+
 		// Database
 		PostgresHost:     getEnv("POSTGRES_HOST", "localhost"),
 		PostgresPort:     getEnv("POSTGRES_PORT", "5432"),
@@ -79,11 +111,17 @@ func Load() (*Config, error) {
 		DatabaseURL:      getEnv("DATABASE_URL", ""),
 
 		// JWT
-		JWTSecret: getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+		JWTSecret:           getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+		JWTPrivateKeyFile:   getEnv("JWT_PRIVATE_KEY_FILE", ""),
+		JWTPreviousKeyFiles: getEnvList("JWT_PREVIOUS_KEY_FILES"),
+		JWTSecretRetiredAt:  getEnv("JWT_SECRET_RETIRED_AT", ""),
 
 		// Google OAuth
 		GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
 		GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
+		GoogleRedirectURL:  getEnv("GOOGLE_REDIRECT_URL", "postmessage"),
+		GoogleJWKSURL:      getEnv("GOOGLE_JWKS_URL", "https://www.googleapis.com/oauth2/v3/certs"),
+		GoogleTokenURL:     getEnv("GOOGLE_TOKEN_URL", "https://oauth2.googleapis.com/token"),
 
 		// Redis
 		RedisHost:     getEnv("REDIS_HOST", "localhost"),
@@ -104,14 +142,34 @@ func Load() (*Config, error) {
 		SMTPUsername: getEnv("SMTP_USERNAME", ""),
 		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
 
+		// Scheduled meetings
+		MeetingReminderLead: getEnvDuration("MEETING_REMINDER_LEAD", 15*time.Minute),
+		MeetingEarlyJoin:    getEnvDuration("MEETING_EARLY_JOIN", 10*time.Minute),
+
+		// Room cleanup
+		RoomIdleGrace:       getEnvDuration("ROOM_IDLE_GRACE", 10*time.Minute),
+		RoomArchiveAfter:    getEnvDuration("ROOM_ARCHIVE_AFTER", 7*24*time.Hour),
+		RoomCleanupAction:   getEnv("ROOM_CLEANUP_ACTION", "archive"),
+		RoomRetention:       getEnvDuration("ROOM_RETENTION", 90*24*time.Hour),
+		RoomJanitorInterval: getEnvDuration("ROOM_JANITOR_INTERVAL", time.Minute),
+
 		// LiveKit - gunakan wss untuk production dengan nginx proxy
 		LiveKitURL:       getEnv("LIVEKIT_URL", "wss://zoom.zacloth.com/rtc"),
+		LiveKitAPIURL:    getEnv("LIVEKIT_API_URL", ""),
 		LiveKitAPIKey:    getEnv("LIVEKIT_API_KEY", "devkey"),
 		LiveKitAPISecret: getEnv("LIVEKIT_API_SECRET", ""),
+		LiveKitTokenTTL:  getEnvDuration("LIVEKIT_TOKEN_TTL", 24*time.Hour),
+		RecordingsDir:    getEnv("RECORDINGS_DIR", ""),
 
 		// Kolosal AI - base URL (endpoint will be appended in service)
 		KolosalAPIURL: getEnv("KOLOSAL_API_URL", "https://api.kolosal.ai"),
 		KolosalAPIKey: strings.TrimSpace(getEnv("KOLOSAL_API_KEY", "")), // Trim whitespace
+
+		// Embeddings / retrieval (RAG)
+		EmbeddingProvider:   getEnv("EMBEDDING_PROVIDER", "local"),
+		EmbeddingModel:      getEnv("EMBEDDING_MODEL", "text-embedding-3-small"),
+		EmbeddingDimensions: getEnvInt("EMBEDDING_DIMENSIONS", 256),
+		RAGTopK:             getEnvInt("RAG_TOP_K", 5),
 	}
 
 	// Build database URL if not provided
@@ -149,3 +207,33 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration parses a Go duration string such as "6h" or "90m"
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList parses a comma-separated list, skipping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,34 @@
+package model
+
+// AI answer modes recorded in provenance
+const (
+	AIModeChat      = "chat"
+	AIModeRoomTools = "room_tools"
+	AIModeAgent     = "agent"
+	AIModeOCR       = "ocr"
+)
+
+// AIProvenance records how an AI chat message was produced so clients can
+// render its sources and audit the answer
+type AIProvenance struct {
+	Model            string     `json:"model"`
+	Mode             string     `json:"mode"` // chat, room_tools, agent, ocr
+	RequestedByID    string     `json:"requested_by_id"`
+	PromptMessageIDs []string   `json:"prompt_message_ids,omitempty"` // Room messages placed in the prompt
+	RetrievedSources []AISource `json:"retrieved_sources,omitempty"`
+	ToolCalls        []string   `json:"tool_calls,omitempty"`
+	UsedTranscript   bool       `json:"used_transcript,omitempty"`
+	PromptTokens     int        `json:"prompt_tokens"`
+	CompletionTokens int        `json:"completion_tokens"`
+	TotalTokens      int        `json:"total_tokens"`
+	LatencyMs        int64      `json:"latency_ms"`
+}
+
+// AISource is a piece of room content the model was given as context.
+// Citation is the number the model was asked to cite it with, if any.
+type AISource struct {
+	Citation   int     `json:"citation,omitempty"`
+	SourceType string  `json:"source_type"` // message, transcript, ocr
+	SourceID   string  `json:"source_id"`
+	Score      float64 `json:"score,omitempty"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ParticipantSession is one interval a user spent in a room, from joining
+// until leaving. Every rejoin starts a new session; LeftAt is nil while the
+// user is still in the room.
+type ParticipantSession struct {
+	ID       string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RoomID   string     `gorm:"type:uuid;not null;index:idx_participant_sessions_room_user" json:"room_id"`
+	UserID   string     `gorm:"type:uuid;not null;index:idx_participant_sessions_room_user" json:"user_id"`
+	JoinedAt time.Time  `gorm:"not null;index" json:"joined_at"`
+	LeftAt   *time.Time `json:"left_at,omitempty"`
+}
+
+// TableName specifies the table name
+func (ParticipantSession) TableName() string {
+	return "participant_sessions"
+}
+
+// BeforeCreate hook to generate UUID
+func (s *ParticipantSession) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// BreakoutAssignment places a user of a meeting in one of its breakout rooms.
+// A user is in at most one breakout room per meeting at a time.
+type BreakoutAssignment struct {
+	ParentRoomID string    `gorm:"type:uuid;primaryKey" json:"parent_room_id"`
+	UserID       string    `gorm:"type:uuid;primaryKey" json:"user_id"`
+	RoomID       string    `gorm:"type:uuid;not null;index" json:"room_id"` // The breakout room
+	AssignedByID string    `gorm:"type:uuid;not null" json:"assigned_by_id"`
+	AssignedAt   time.Time `gorm:"not null" json:"assigned_at"`
+}
+
+// TableName specifies the table name
+func (BreakoutAssignment) TableName() string {
+	return "breakout_assignments"
+}
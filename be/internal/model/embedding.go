@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Embedding source types
+const (
+	EmbeddingSourceMessage    = "message"
+	EmbeddingSourceTranscript = "transcript"
+	EmbeddingSourceOCR        = "ocr"
+)
+
+// EmbeddingChunk is a chunk of room content with its vector embedding.
+// Vectors are stored as JSON so the table works on plain Postgres; similarity
+// is computed in the application.
+type EmbeddingChunk struct {
+	ID         string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RoomID     string    `gorm:"type:uuid;not null;index:idx_embedding_room_provider" json:"room_id"`
+	SourceType string    `gorm:"type:varchar(20);not null;index:idx_embedding_source" json:"source_type"` // message, transcript, ocr
+	SourceID   string    `gorm:"type:varchar(100);not null;index:idx_embedding_source" json:"source_id"`
+	ChunkIndex int       `gorm:"not null;default:0" json:"chunk_index"`
+	Content    string    `gorm:"type:text;not null" json:"content"`
+	Provider   string    `gorm:"type:varchar(100);not null;index:idx_embedding_room_provider" json:"provider"` // provider/model that produced the vector
+	Embedding  []float32 `gorm:"type:jsonb;serializer:json;not null" json:"-"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (EmbeddingChunk) TableName() string {
+	return "embedding_chunks"
+}
+
+// BeforeCreate hook to generate UUID
+func (e *EmbeddingChunk) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return nil
+}
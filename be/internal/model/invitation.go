@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Invitation delivery states
+const (
+	InvitationPending = "pending"
+	InvitationSent    = "sent"
+	InvitationFailed  = "failed"
+)
+
+// RoomInvitation is an email address invited to a scheduled meeting
+type RoomInvitation struct {
+	ID          string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RoomID      string     `gorm:"type:uuid;not null;uniqueIndex:idx_room_invitations_room_email" json:"room_id"`
+	Email       string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_room_invitations_room_email" json:"email"`
+	UserID      *string    `gorm:"type:uuid;index" json:"user_id,omitempty"` // Set when the email belongs to a registered user
+	InvitedByID string     `gorm:"type:uuid;not null" json:"invited_by_id"`
+	Status      string     `gorm:"type:varchar(20);not null;default:pending" json:"status"`
+	SentAt      *time.Time `json:"sent_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (RoomInvitation) TableName() string {
+	return "room_invitations"
+}
+
+// BeforeCreate hook to generate UUID
+func (i *RoomInvitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoomInviteLink is a shareable link that lets anyone holding it join a room
+type RoomInviteLink struct {
+	ID          string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RoomID      string     `gorm:"type:uuid;not null;index" json:"room_id"`
+	CreatedByID string     `gorm:"type:uuid;not null" json:"created_by_id"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	MaxUses     *int       `gorm:"type:integer" json:"max_uses,omitempty"` // Nil for unlimited
+	Uses        int        `gorm:"not null;default:0" json:"uses"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (RoomInviteLink) TableName() string {
+	return "room_invite_links"
+}
+
+// BeforeCreate hook to generate UUID
+func (l *RoomInviteLink) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == "" {
+		l.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Lobby entry states
+const (
+	LobbyWaiting  = "waiting"
+	LobbyAdmitted = "admitted"
+	LobbyDenied   = "denied"
+)
+
+// LobbyEntry is a user waiting for (or decided on) admission to a room with
+// the lobby enabled
+type LobbyEntry struct {
+	ID          string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RoomID      string     `gorm:"type:uuid;not null;uniqueIndex:idx_lobby_entries_room_user" json:"room_id"`
+	UserID      string     `gorm:"type:uuid;not null;uniqueIndex:idx_lobby_entries_room_user" json:"user_id"`
+	User        User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Status      string     `gorm:"type:varchar(20);not null;default:waiting;index" json:"status"`
+	DecidedByID *string    `gorm:"type:uuid" json:"decided_by_id,omitempty"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+	RequestedAt time.Time  `json:"requested_at"`
+	// InviteLinkID is the invite link the user joined with; it is counted
+	// as used once the user is admitted
+	InviteLinkID *string   `gorm:"type:uuid" json:"-"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (LobbyEntry) TableName() string {
+	return "lobby_entries"
+}
+
+// BeforeCreate hook to generate UUID
+func (l *LobbyEntry) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == "" {
+		l.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MeetingNote represents meeting notes attached to a room
+type MeetingNote struct {
+	ID               string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RoomID           string         `gorm:"type:uuid;not null;index" json:"room_id"`
+	CreatedByID      string         `gorm:"type:uuid;not null" json:"created_by_id"`
+	CreatedBy        User           `gorm:"foreignKey:CreatedByID" json:"created_by,omitempty"`
+	UpdatedByID      *string        `gorm:"type:uuid" json:"updated_by_id,omitempty"`
+	Title            string         `gorm:"type:varchar(255);not null" json:"title"`
+	Body             string         `gorm:"type:text" json:"body"` // Markdown
+	ActionItems      []ActionItem   `gorm:"foreignKey:NoteID;constraint:OnDelete:CASCADE" json:"action_items"`
+	SourceMessageIDs []string       `gorm:"type:jsonb;serializer:json" json:"source_message_ids"`
+	GeneratedByAI    bool           `gorm:"default:false" json:"generated_by_ai"`
+	Version          int            `gorm:"not null;default:1" json:"version"` // Incremented on every update for optimistic locking
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// ActionItem represents a follow-up task captured in meeting notes
+type ActionItem struct {
+	ID           string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	NoteID       string     `gorm:"type:uuid;not null;index" json:"note_id"`
+	Description  string     `gorm:"type:text;not null" json:"description"`
+	AssigneeID   *string    `gorm:"type:uuid" json:"assignee_id,omitempty"`
+	AssigneeName *string    `gorm:"type:varchar(255)" json:"assignee_name,omitempty"`
+	DueDate      *time.Time `gorm:"type:date" json:"due_date,omitempty"`
+	Completed    bool       `gorm:"default:false" json:"completed"`
+	Position     int        `gorm:"not null;default:0" json:"position"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (MeetingNote) TableName() string {
+	return "meeting_notes"
+}
+
+// TableName specifies the table name for ActionItem
+func (ActionItem) TableName() string {
+	return "action_items"
+}
+
+// BeforeCreate hook to generate UUID
+func (n *MeetingNote) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate hook to generate UUID
+func (a *ActionItem) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Poll kinds
+const (
+	PollSingleChoice   = "single"
+	PollMultipleChoice = "multiple"
+)
+
+// Poll is a question put to a meeting room. Anonymous polls still record who
+// voted, to allow one ballot per user, but never reveal it.
+type Poll struct {
+	ID          string       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RoomID      string       `gorm:"type:uuid;not null;index" json:"room_id"`
+	CreatedByID string       `gorm:"type:uuid;not null" json:"created_by_id"`
+	Question    string       `gorm:"type:varchar(500);not null" json:"question"`
+	Kind        string       `gorm:"type:varchar(20);not null;default:single" json:"kind"`
+	Anonymous   bool         `gorm:"default:false" json:"anonymous"`
+	ClosesAt    *time.Time   `json:"closes_at,omitempty"` // Closes automatically at this time
+	ClosedAt    *time.Time   `json:"closed_at,omitempty"`
+	Options     []PollOption `gorm:"foreignKey:PollID;constraint:OnDelete:CASCADE" json:"options"`
+	Votes       []PollVote   `gorm:"foreignKey:PollID;constraint:OnDelete:CASCADE" json:"-"`
+	CreatedAt   time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time    `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// PollOption is one of the answers to a poll
+type PollOption struct {
+	ID       string `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PollID   string `gorm:"type:uuid;not null;index" json:"poll_id"`
+	Text     string `gorm:"type:varchar(255);not null" json:"text"`
+	Position int    `gorm:"not null;default:0" json:"position"`
+}
+
+// PollVote is a user's vote for one option. Multiple choice polls have one
+// row per chosen option.
+type PollVote struct {
+	ID        string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PollID    string    `gorm:"type:uuid;not null;uniqueIndex:idx_poll_votes_ballot" json:"poll_id"`
+	UserID    string    `gorm:"type:uuid;not null;uniqueIndex:idx_poll_votes_ballot" json:"user_id"`
+	OptionID  string    `gorm:"type:uuid;not null;uniqueIndex:idx_poll_votes_ballot" json:"option_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// IsClosed reports whether voting has ended at now
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.ClosedAt != nil || (p.ClosesAt != nil && !now.Before(*p.ClosesAt))
+}
+
+// TableName specifies the table name
+func (Poll) TableName() string {
+	return "polls"
+}
+
+// TableName specifies the table name for PollOption
+func (PollOption) TableName() string {
+	return "poll_options"
+}
+
+// TableName specifies the table name for PollVote
+func (PollVote) TableName() string {
+	return "poll_votes"
+}
+
+// BeforeCreate hook to generate UUID
+func (p *Poll) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate hook to generate UUID
+func (o *PollOption) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == "" {
+		o.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate hook to generate UUID
+func (v *PollVote) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == "" {
+		v.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Recording states, following the LiveKit egress lifecycle
+const (
+	RecordingStarting = "starting"
+	RecordingActive   = "active"
+	RecordingEnding   = "ending"
+	RecordingComplete = "complete"
+	RecordingFailed   = "failed"
+	RecordingAborted  = "aborted"
+)
+
+// Recording is a room composite recording made by LiveKit Egress
+type Recording struct {
+	ID              string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RoomID          string     `gorm:"type:uuid;not null;index" json:"room_id"`
+	EgressID        string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"egress_id"`
+	StartedByID     string     `gorm:"type:uuid;not null" json:"started_by_id"`
+	Status          string     `gorm:"type:varchar(20);not null;default:starting;index" json:"status"`
+	Filepath        string     `gorm:"type:varchar(512);not null" json:"filepath"` // Requested path on the egress storage
+	Location        string     `gorm:"type:text" json:"location,omitempty"`        // Where egress uploaded the file, once complete
+	DurationSeconds int64      `gorm:"default:0" json:"duration_seconds"`
+	SizeBytes       int64      `gorm:"default:0" json:"size_bytes"`
+	Error           string     `gorm:"type:text" json:"error,omitempty"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// IsRunning reports whether the egress may still be recording
+func (r *Recording) IsRunning() bool {
+	return r.Status == RecordingStarting || r.Status == RecordingActive || r.Status == RecordingEnding
+}
+
+// TableName specifies the table name
+func (Recording) TableName() string {
+	return "recordings"
+}
+
+// BeforeCreate hook to generate UUID
+func (r *Recording) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
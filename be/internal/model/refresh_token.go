@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken is an issued refresh token, stored as a hash. Every login
+// starts a token family, identified by its Session; each refresh rotates the
+// presented token out and issues the next one in the same family.
+type RefreshToken struct {
+	ID        string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	FamilyID  string     `gorm:"type:uuid;not null;index" json:"family_id"` // ID of the Session
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"` // Set once exchanged for a new token; presenting it again means it leaked
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// BeforeCreate hook to generate UUID
+func (t *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -14,21 +14,74 @@ type Room struct {
 	Description     *string        `gorm:"type:text" json:"description,omitempty"`
 	CreatedByID     string         `gorm:"type:uuid;not null" json:"created_by_id"`
 	CreatedBy       User           `gorm:"foreignKey:CreatedByID" json:"created_by,omitempty"`
-	IsActive        bool           `gorm:"default:true" json:"is_active"`
+	Status          string         `gorm:"type:varchar(20);not null;default:live;index" json:"status"`
+	StatusChangedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP;index" json:"status_changed_at"`
 	MaxParticipants *int           `gorm:"type:integer" json:"max_participants,omitempty"`
+	LobbyEnabled    bool           `gorm:"default:false" json:"lobby_enabled"` // Joiners wait for a host to admit them
+	Visibility      string         `gorm:"type:varchar(20);not null;default:public;index" json:"visibility"`
+	PasscodeHash    string         `gorm:"type:varchar(255)" json:"-"`             // bcrypt hash; empty when no passcode is set
+	ScheduledStart  *time.Time     `gorm:"index" json:"scheduled_start,omitempty"` // Nil for instant meetings
+	ScheduledEnd    *time.Time     `json:"scheduled_end,omitempty"`
+	Timezone        string         `gorm:"type:varchar(64)" json:"timezone,omitempty"`         // IANA name, used for recurrence and emails
+	RecurrenceRule  string         `gorm:"type:varchar(255)" json:"recurrence_rule,omitempty"` // iCalendar RRULE, e.g. FREQ=WEEKLY;BYDAY=MO
+	LastReminderFor *time.Time     `json:"-"`                                                  // Occurrence start the last reminder was sent for
+	ParentRoomID    *string        `gorm:"type:uuid;index" json:"parent_room_id,omitempty"`    // Set on breakout rooms
+	BreakoutsEndAt  *time.Time     `json:"breakouts_end_at,omitempty"`                         // When the open breakout rooms close automatically
 	Participants    []User         `gorm:"many2many:room_participants;" json:"participants,omitempty"`
 	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// Room visibility. Public rooms are listed; unlisted rooms can be joined by
+// anyone with the ID; private rooms need an invitation or invite link.
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+)
+
+// Room lifecycle states. Scheduled rooms go live when the first person joins;
+// ended rooms can be reopened or archived, and archived rooms are read-only.
+const (
+	RoomScheduled = "scheduled"
+	RoomLive      = "live"
+	RoomEnded     = "ended"
+	RoomArchived  = "archived"
+)
+
+// IsBreakout reports whether the room is a breakout room of another meeting
+func (r *Room) IsBreakout() bool {
+	return r.ParentRoomID != nil
+}
+
+// IsOpen reports whether people can still join the room
+func (r *Room) IsOpen() bool {
+	return r.Status == RoomScheduled || r.Status == RoomLive
+}
+
+// Room roles, from most to least privileged
+const (
+	RoleHost        = "host"
+	RoleCoHost      = "co_host"
+	RoleParticipant = "participant"
+	RoleViewer      = "viewer"
+)
+
 // RoomParticipant represents the many-to-many relationship between Room and User
 type RoomParticipant struct {
-	RoomID   string     `gorm:"type:uuid;primaryKey" json:"room_id"`
-	UserID   string     `gorm:"type:uuid;primaryKey" json:"user_id"`
-	JoinedAt time.Time  `gorm:"autoCreateTime" json:"joined_at"`
-	LeftAt   *time.Time `gorm:"type:timestamp" json:"left_at,omitempty"`
-	IsActive bool       `gorm:"default:true" json:"is_active"`
+	RoomID      string     `gorm:"type:uuid;primaryKey" json:"room_id"`
+	UserID      string     `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Role        string     `gorm:"type:varchar(20);not null;default:participant" json:"role"`
+	JoinedAt    time.Time  `gorm:"autoCreateTime" json:"joined_at"`
+	LeftAt      *time.Time `gorm:"type:timestamp" json:"left_at,omitempty"`
+	IsActive    bool       `gorm:"default:true" json:"is_active"`
+	IsMuted     bool       `gorm:"default:false" json:"is_muted"`            // Microphone muted by a moderator
+	RemovedByID *string    `gorm:"type:uuid" json:"removed_by_id,omitempty"` // Set when a moderator removed the participant
+
+	// Media session tracking, driven by LiveKit webhooks
+	SessionStartedAt     *time.Time `gorm:"type:timestamp" json:"session_started_at,omitempty"`
+	TotalDurationSeconds int64      `gorm:"default:0" json:"total_duration_seconds"`
 }
 
 // TableName specifies the table name
@@ -46,5 +99,8 @@ func (r *Room) BeforeCreate(tx *gorm.DB) error {
 	if r.ID == "" {
 		r.ID = uuid.New().String()
 	}
+	if r.StatusChangedAt.IsZero() {
+		r.StatusChangedAt = time.Now()
+	}
 	return nil
 }
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Session is one login of a user on a device. Its refresh tokens share the
+// session ID as their family ID, and access tokens carry it, so revoking the
+// session signs the device out.
+type Session struct {
+	ID         string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID     string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	UserAgent  string     `gorm:"type:text" json:"user_agent"`
+	IPAddress  string     `gorm:"type:varchar(45)" json:"ip_address"`
+	LastUsedAt time.Time  `gorm:"not null" json:"last_used_at"` // Last login or token refresh
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`   // When the latest refresh token expires
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// IsActive reports whether the session can still be used at now
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// TableName specifies the table name
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// BeforeCreate hook to generate UUID
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TranscriptSegment represents a piece of live transcription for a room
+type TranscriptSegment struct {
+	ID              string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RoomID          string    `gorm:"type:uuid;not null;index:idx_transcript_room_start" json:"room_id"`
+	SpeakerIdentity string    `gorm:"type:varchar(255);not null" json:"speaker_identity"` // LiveKit participant identity
+	SpeakerUserID   *string   `gorm:"type:uuid;index" json:"speaker_user_id,omitempty"`
+	SpeakerName     string    `gorm:"type:varchar(255)" json:"speaker_name"`
+	Text            string    `gorm:"type:text;not null" json:"text"`
+	Language        string    `gorm:"type:varchar(20)" json:"language,omitempty"`
+	Source          string    `gorm:"type:varchar(50);default:'client'" json:"source"` // client, agent
+	StartTime       time.Time `gorm:"not null;index:idx_transcript_room_start" json:"start_time"`
+	EndTime         time.Time `gorm:"not null" json:"end_time"`
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (TranscriptSegment) TableName() string {
+	return "transcript_segments"
+}
+
+// BeforeCreate hook to generate UUID
+func (t *TranscriptSegment) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -25,8 +25,11 @@ type User struct {
 	GoogleID       *string        `gorm:"type:varchar(255);uniqueIndex" json:"-"`
 	OTPCode        *string        `gorm:"type:varchar(6)" json:"-"`
 	OTPExpiresAt   *time.Time     `gorm:"type:timestamp" json:"-"`
+	OTPAttempts    int            `gorm:"not null;default:0" json:"-"` // Wrong codes entered for the current OTP
 	ResetToken     *string        `gorm:"type:text" json:"-"`
 	ResetExpiresAt *time.Time     `gorm:"type:timestamp" json:"-"`
+	FailedLogins   int            `gorm:"not null;default:0" json:"-"` // Wrong passwords since the last successful login or lockout
+	LockedUntil    *time.Time     `gorm:"type:timestamp" json:"-"`     // Logins are refused until then
 	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"time"
+	"yourapp/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BreakoutRepository interface {
+	FindOpenBreakouts(parentRoomID string) ([]model.Room, error)
+	SetDeadline(parentRoomID string, endsAt *time.Time) error
+	FindWithDeadline() ([]model.Room, error)
+	Assign(assignments []model.BreakoutAssignment) error
+	Unassign(parentRoomID string, userIDs []string) error
+	ClearAssignments(parentRoomID string) error
+	FindAssignment(parentRoomID, userID string) (*model.BreakoutAssignment, error)
+	FindAssignments(parentRoomID string) ([]AssignmentDetail, error)
+}
+
+// AssignmentDetail is a breakout assignment joined with the user's profile
+type AssignmentDetail struct {
+	model.BreakoutAssignment
+	FullName string  `json:"full_name"`
+	Email    string  `json:"email"`
+	Username *string `json:"username,omitempty"`
+}
+
+type breakoutRepository struct {
+	db *gorm.DB
+}
+
+func NewBreakoutRepository(db *gorm.DB) BreakoutRepository {
+	return &breakoutRepository{db: db}
+}
+
+// FindOpenBreakouts returns the scheduled and live breakout rooms of a meeting
+func (r *breakoutRepository) FindOpenBreakouts(parentRoomID string) ([]model.Room, error) {
+	var rooms []model.Room
+	err := r.db.Where("parent_room_id = ? AND status IN ?", parentRoomID, []string{model.RoomScheduled, model.RoomLive}).
+		Order("created_at ASC, name ASC").
+		Find(&rooms).Error
+	return rooms, err
+}
+
+// SetDeadline sets or clears (nil) when the meeting's breakout rooms close
+func (r *breakoutRepository) SetDeadline(parentRoomID string, endsAt *time.Time) error {
+	return r.db.Model(&model.Room{}).
+		Where("id = ?", parentRoomID).
+		Update("breakouts_end_at", endsAt).Error
+}
+
+// FindWithDeadline returns the meetings whose breakout rooms are set to close
+func (r *breakoutRepository) FindWithDeadline() ([]model.Room, error) {
+	var rooms []model.Room
+	err := r.db.Where("breakouts_end_at IS NOT NULL").Find(&rooms).Error
+	return rooms, err
+}
+
+// Assign stores assignments, moving users that were already assigned
+func (r *breakoutRepository) Assign(assignments []model.BreakoutAssignment) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "parent_room_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"room_id", "assigned_by_id", "assigned_at"}),
+	}).Create(&assignments).Error
+}
+
+func (r *breakoutRepository) Unassign(parentRoomID string, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	return r.db.Where("parent_room_id = ? AND user_id IN ?", parentRoomID, userIDs).
+		Delete(&model.BreakoutAssignment{}).Error
+}
+
+func (r *breakoutRepository) ClearAssignments(parentRoomID string) error {
+	return r.db.Where("parent_room_id = ?", parentRoomID).Delete(&model.BreakoutAssignment{}).Error
+}
+
+func (r *breakoutRepository) FindAssignment(parentRoomID, userID string) (*model.BreakoutAssignment, error) {
+	var assignment model.BreakoutAssignment
+	err := r.db.Where("parent_room_id = ? AND user_id = ?", parentRoomID, userID).First(&assignment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+func (r *breakoutRepository) FindAssignments(parentRoomID string) ([]AssignmentDetail, error) {
+	var assignments []AssignmentDetail
+	err := r.db.Model(&model.BreakoutAssignment{}).
+		Select("breakout_assignments.*, users.full_name, users.email, users.username").
+		Joins("JOIN users ON users.id = breakout_assignments.user_id").
+		Where("breakout_assignments.parent_room_id = ?", parentRoomID).
+		Order("breakout_assignments.assigned_at ASC").
+		Scan(&assignments).Error
+	return assignments, err
+}
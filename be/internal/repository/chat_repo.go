@@ -11,6 +11,8 @@ type ChatRepository interface {
 	FindByRoomID(roomID string, limit, offset int) ([]model.ChatMessage, error)
 	FindByID(id string) (*model.ChatMessage, error)
 	GetMessageCount(roomID string) (int64, error)
+	SearchByRoomID(roomID, query, author string, limit int) ([]model.ChatMessage, error)
+	FindByIDsInRoom(roomID string, ids []string) ([]model.ChatMessage, error)
 }
 
 type chatRepository struct {
@@ -63,3 +65,31 @@ func (r *chatRepository) GetMessageCount(roomID string) (int64, error) {
 	return count, err
 }
 
+// SearchByRoomID finds the latest messages containing query. A non-empty
+// author must match the sender's name, username or email.
+func (r *chatRepository) SearchByRoomID(roomID, query, author string, limit int) ([]model.ChatMessage, error) {
+	var messages []model.ChatMessage
+	db := r.db.Preload("User").
+		Where("chat_messages.room_id = ? AND chat_messages.message ILIKE ?", roomID, containsPattern(query))
+	if author != "" {
+		pattern := containsPattern(author)
+		db = db.Joins("JOIN users ON users.id = chat_messages.user_id").
+			Where("(users.full_name ILIKE ? OR users.username ILIKE ? OR users.email ILIKE ?)", pattern, pattern, pattern)
+	}
+	err := db.Order("chat_messages.created_at DESC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *chatRepository) FindByIDsInRoom(roomID string, ids []string) ([]model.ChatMessage, error) {
+	var messages []model.ChatMessage
+	if len(ids) == 0 {
+		return messages, nil
+	}
+	err := r.db.Preload("User").
+		Where("room_id = ? AND id IN ?", roomID, ids).
+		Order("created_at ASC").
+		Find(&messages).Error
+	return messages, err
+}
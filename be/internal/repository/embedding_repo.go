@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"yourapp/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type EmbeddingRepository interface {
+	ReplaceSource(sourceType, sourceID string, chunks []model.EmbeddingChunk) error
+	FindByRoomID(roomID, provider string, limit int) ([]model.EmbeddingChunk, error)
+	DeleteByRoomID(roomID string) error
+}
+
+type embeddingRepository struct {
+	db *gorm.DB
+}
+
+func NewEmbeddingRepository(db *gorm.DB) EmbeddingRepository {
+	return &embeddingRepository{db: db}
+}
+
+// ReplaceSource removes existing chunks of a source and stores the new ones
+func (r *embeddingRepository) ReplaceSource(sourceType, sourceID string, chunks []model.EmbeddingChunk) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("source_type = ? AND source_id = ?", sourceType, sourceID).
+			Delete(&model.EmbeddingChunk{}).Error; err != nil {
+			return err
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+		return tx.Create(&chunks).Error
+	})
+}
+
+// FindByRoomID returns up to limit of the room's most recent chunks
+func (r *embeddingRepository) FindByRoomID(roomID, provider string, limit int) ([]model.EmbeddingChunk, error) {
+	var chunks []model.EmbeddingChunk
+	err := r.db.Where("room_id = ? AND provider = ?", roomID, provider).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&chunks).Error
+	return chunks, err
+}
+
+func (r *embeddingRepository) DeleteByRoomID(roomID string) error {
+	return r.db.Where("room_id = ?", roomID).Delete(&model.EmbeddingChunk{}).Error
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"time"
+	"yourapp/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvitationRepository interface {
+	CreateBatch(invitations []model.RoomInvitation) error
+	FindByRoomID(roomID string) ([]model.RoomInvitation, error)
+	FindByRoomAndEmails(roomID string, emails []string) ([]model.RoomInvitation, error)
+	UpdateStatus(id, status string, sentAt *time.Time) error
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+// CreateBatch inserts invitations, skipping emails already invited to the room
+func (r *invitationRepository) CreateBatch(invitations []model.RoomInvitation) error {
+	if len(invitations) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&invitations).Error
+}
+
+func (r *invitationRepository) FindByRoomID(roomID string) ([]model.RoomInvitation, error) {
+	var invitations []model.RoomInvitation
+	err := r.db.Where("room_id = ?", roomID).Order("created_at ASC").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *invitationRepository) FindByRoomAndEmails(roomID string, emails []string) ([]model.RoomInvitation, error) {
+	var invitations []model.RoomInvitation
+	err := r.db.Where("room_id = ? AND email IN ?", roomID, emails).Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *invitationRepository) UpdateStatus(id, status string, sentAt *time.Time) error {
+	return r.db.Model(&model.RoomInvitation{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":  status,
+			"sent_at": sentAt,
+		}).Error
+}
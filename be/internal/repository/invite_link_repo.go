@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"time"
+	"yourapp/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type InviteLinkRepository interface {
+	Create(link *model.RoomInviteLink) error
+	FindByID(id string) (*model.RoomInviteLink, error)
+	FindByRoomID(roomID string) ([]model.RoomInviteLink, error)
+	Revoke(id string, at time.Time) error
+	Redeem(id, roomID string, at time.Time) (bool, error)
+	Release(id string) error
+}
+
+type inviteLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewInviteLinkRepository(db *gorm.DB) InviteLinkRepository {
+	return &inviteLinkRepository{db: db}
+}
+
+func (r *inviteLinkRepository) Create(link *model.RoomInviteLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *inviteLinkRepository) FindByID(id string) (*model.RoomInviteLink, error) {
+	var link model.RoomInviteLink
+	err := r.db.Where("id = ?", id).First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *inviteLinkRepository) FindByRoomID(roomID string) ([]model.RoomInviteLink, error) {
+	var links []model.RoomInviteLink
+	err := r.db.Where("room_id = ?", roomID).Order("created_at DESC").Find(&links).Error
+	return links, err
+}
+
+func (r *inviteLinkRepository) Revoke(id string, at time.Time) error {
+	return r.db.Model(&model.RoomInviteLink{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+// Redeem counts one use of a link in a single statement, so concurrent joins
+// cannot exceed max_uses. Returns false if the link is expired, revoked or
+// used up.
+func (r *inviteLinkRepository) Redeem(id, roomID string, at time.Time) (bool, error) {
+	result := r.db.Model(&model.RoomInviteLink{}).
+		Where("id = ? AND room_id = ? AND revoked_at IS NULL", id, roomID).
+		Where("expires_at IS NULL OR expires_at > ?", at).
+		Where("max_uses IS NULL OR uses < max_uses").
+		Update("uses", gorm.Expr("uses + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// Release gives back a use counted by Redeem for a join that didn't go through
+func (r *inviteLinkRepository) Release(id string) error {
+	return r.db.Model(&model.RoomInviteLink{}).
+		Where("id = ? AND uses > 0", id).
+		Update("uses", gorm.Expr("uses - 1")).Error
+}
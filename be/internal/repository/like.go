@@ -0,0 +1,11 @@
+package repository
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// containsPattern builds an ILIKE pattern matching s anywhere, with the LIKE
+// wildcards in s matched literally
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
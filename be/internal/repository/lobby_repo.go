@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"time"
+	"yourapp/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type LobbyRepository interface {
+	Create(entry *model.LobbyEntry) error
+	Find(roomID, userID string) (*model.LobbyEntry, error)
+	FindByStatus(roomID, status string) ([]model.LobbyEntry, error)
+	Requeue(roomID, userID string, at time.Time) error
+	Decide(roomID string, userIDs []string, status, decidedByID string, at time.Time) error
+	Delete(roomID, userID string) error
+}
+
+type lobbyRepository struct {
+	db *gorm.DB
+}
+
+func NewLobbyRepository(db *gorm.DB) LobbyRepository {
+	return &lobbyRepository{db: db}
+}
+
+func (r *lobbyRepository) Create(entry *model.LobbyEntry) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *lobbyRepository) Find(roomID, userID string) (*model.LobbyEntry, error) {
+	var entry model.LobbyEntry
+	err := r.db.Where("room_id = ? AND user_id = ?", roomID, userID).First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// FindByStatus returns the room's lobby entries in a state, oldest request first
+func (r *lobbyRepository) FindByStatus(roomID, status string) ([]model.LobbyEntry, error) {
+	var entries []model.LobbyEntry
+	err := r.db.Preload("User").
+		Where("room_id = ? AND status = ?", roomID, status).
+		Order("requested_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// Requeue puts an existing entry back in the waiting state
+func (r *lobbyRepository) Requeue(roomID, userID string, at time.Time) error {
+	return r.db.Model(&model.LobbyEntry{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Updates(map[string]interface{}{
+			"status":        model.LobbyWaiting,
+			"decided_by_id": nil,
+			"decided_at":    nil,
+			"requested_at":  at,
+		}).Error
+}
+
+// Decide admits or denies waiting users. Entries that are no longer waiting
+// are left untouched.
+func (r *lobbyRepository) Decide(roomID string, userIDs []string, status, decidedByID string, at time.Time) error {
+	return r.db.Model(&model.LobbyEntry{}).
+		Where("room_id = ? AND user_id IN ? AND status = ?", roomID, userIDs, model.LobbyWaiting).
+		Updates(map[string]interface{}{
+			"status":        status,
+			"decided_by_id": decidedByID,
+			"decided_at":    at,
+		}).Error
+}
+
+func (r *lobbyRepository) Delete(roomID, userID string) error {
+	return r.db.Where("room_id = ? AND user_id = ?", roomID, userID).Delete(&model.LobbyEntry{}).Error
+}
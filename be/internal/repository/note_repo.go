@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"yourapp/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ErrNoteVersionConflict is returned when a note was changed since the caller last read it
+var ErrNoteVersionConflict = errors.New("note was modified by someone else, please reload")
+
+type NoteRepository interface {
+	Create(note *model.MeetingNote) error
+	FindByID(id string) (*model.MeetingNote, error)
+	FindByRoomID(roomID string) ([]model.MeetingNote, error)
+	Update(note *model.MeetingNote, expectedVersion int) error
+	Delete(id string) error
+}
+
+type noteRepository struct {
+	db *gorm.DB
+}
+
+func NewNoteRepository(db *gorm.DB) NoteRepository {
+	return &noteRepository{db: db}
+}
+
+func (r *noteRepository) Create(note *model.MeetingNote) error {
+	return r.db.Create(note).Error
+}
+
+func (r *noteRepository) FindByID(id string) (*model.MeetingNote, error) {
+	var note model.MeetingNote
+	err := r.db.Preload("CreatedBy").
+		Preload("ActionItems", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Where("id = ?", id).
+		First(&note).Error
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+func (r *noteRepository) FindByRoomID(roomID string) ([]model.MeetingNote, error) {
+	var notes []model.MeetingNote
+	err := r.db.Preload("CreatedBy").
+		Preload("ActionItems", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Where("room_id = ?", roomID).
+		Order("created_at DESC").
+		Find(&notes).Error
+	return notes, err
+}
+
+// Update saves the note and replaces its action items, but only if the stored
+// version still matches expectedVersion. The note's Version is bumped on success.
+func (r *noteRepository) Update(note *model.MeetingNote, expectedVersion int) error {
+	sourceIDs, err := json.Marshal(note.SourceMessageIDs)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.MeetingNote{}).
+			Where("id = ? AND version = ?", note.ID, expectedVersion).
+			Updates(map[string]interface{}{
+				"title":              note.Title,
+				"body":               note.Body,
+				"source_message_ids": string(sourceIDs),
+				"updated_by_id":      note.UpdatedByID,
+				"version":            gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNoteVersionConflict
+		}
+
+		if err := tx.Where("note_id = ?", note.ID).Delete(&model.ActionItem{}).Error; err != nil {
+			return err
+		}
+		for i := range note.ActionItems {
+			note.ActionItems[i].ID = ""
+			note.ActionItems[i].NoteID = note.ID
+			note.ActionItems[i].Position = i
+		}
+		if len(note.ActionItems) > 0 {
+			if err := tx.Create(&note.ActionItems).Error; err != nil {
+				return err
+			}
+		}
+
+		note.Version = expectedVersion + 1
+		return nil
+	})
+}
+
+func (r *noteRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("note_id = ?", id).Delete(&model.ActionItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.MeetingNote{}).Error
+	})
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"errors"
+	"time"
+	"yourapp/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrPollClosed is returned when voting on a poll that has closed
+var ErrPollClosed = errors.New("poll is closed")
+
+type PollRepository interface {
+	Create(poll *model.Poll) error
+	FindByID(id string) (*model.Poll, error)
+	FindByRoomID(roomID string) ([]model.Poll, error)
+	FindPendingClose() ([]model.Poll, error)
+	Vote(pollID, userID string, optionIDs []string, at time.Time) error
+	Close(pollID string, at time.Time) (bool, error)
+	FindVotes(pollIDs []string) ([]PollVoteDetail, error)
+}
+
+// PollVoteDetail is a vote joined with the voter's profile
+type PollVoteDetail struct {
+	model.PollVote
+	FullName string  `json:"full_name"`
+	Username *string `json:"username,omitempty"`
+}
+
+type pollRepository struct {
+	db *gorm.DB
+}
+
+func NewPollRepository(db *gorm.DB) PollRepository {
+	return &pollRepository{db: db}
+}
+
+func (r *pollRepository) Create(poll *model.Poll) error {
+	return r.db.Create(poll).Error
+}
+
+func (r *pollRepository) FindByID(id string) (*model.Poll, error) {
+	var poll model.Poll
+	err := r.db.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).
+		Where("id = ?", id).
+		First(&poll).Error
+	if err != nil {
+		return nil, err
+	}
+	return &poll, nil
+}
+
+func (r *pollRepository) FindByRoomID(roomID string) ([]model.Poll, error) {
+	var polls []model.Poll
+	err := r.db.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).
+		Where("room_id = ?", roomID).
+		Order("created_at DESC").
+		Find(&polls).Error
+	return polls, err
+}
+
+// FindPendingClose returns open polls that have a close time
+func (r *pollRepository) FindPendingClose() ([]model.Poll, error) {
+	var polls []model.Poll
+	err := r.db.Where("closed_at IS NULL AND closes_at IS NOT NULL").Find(&polls).Error
+	return polls, err
+}
+
+// Vote replaces the user's ballot. The poll row is locked so a vote cannot
+// slip in after the poll closes.
+func (r *pollRepository) Vote(pollID, userID string, optionIDs []string, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var poll model.Poll
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ? AND closed_at IS NULL", pollID).
+			Where("closes_at IS NULL OR closes_at > ?", at).
+			First(&poll).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPollClosed
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("poll_id = ? AND user_id = ?", pollID, userID).Delete(&model.PollVote{}).Error; err != nil {
+			return err
+		}
+		votes := make([]model.PollVote, len(optionIDs))
+		for i, optionID := range optionIDs {
+			votes[i] = model.PollVote{PollID: pollID, UserID: userID, OptionID: optionID}
+		}
+		return tx.Create(&votes).Error
+	})
+}
+
+// Close ends voting. Returns false if the poll was already closed.
+func (r *pollRepository) Close(pollID string, at time.Time) (bool, error) {
+	result := r.db.Model(&model.Poll{}).
+		Where("id = ? AND closed_at IS NULL", pollID).
+		Update("closed_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *pollRepository) FindVotes(pollIDs []string) ([]PollVoteDetail, error) {
+	var votes []PollVoteDetail
+	if len(pollIDs) == 0 {
+		return votes, nil
+	}
+	err := r.db.Model(&model.PollVote{}).
+		Select("poll_votes.*, users.full_name, users.username").
+		Joins("JOIN users ON users.id = poll_votes.user_id").
+		Where("poll_votes.poll_id IN ?", pollIDs).
+		Order("poll_votes.created_at ASC").
+		Scan(&votes).Error
+	return votes, err
+}
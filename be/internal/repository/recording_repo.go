@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+	"yourapp/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrRecordingRunning is returned when the room already has a running recording
+	ErrRecordingRunning = errors.New("room already has a running recording")
+	// ErrRecordingFinished is returned when updating a recording that already
+	// completed, failed or was aborted
+	ErrRecordingFinished = errors.New("recording has already finished")
+)
+
+var runningRecordingStatuses = []string{model.RecordingStarting, model.RecordingActive, model.RecordingEnding}
+
+type RecordingRepository interface {
+	Create(recording *model.Recording) error
+	FindByID(id string) (*model.Recording, error)
+	FindByEgressID(egressID string) (*model.Recording, error)
+	FindByRoomID(roomID string) ([]model.Recording, error)
+	FindRunning(roomID string) (*model.Recording, error)
+	Update(recording *model.Recording) error
+}
+
+type recordingRepository struct {
+	db *gorm.DB
+}
+
+func NewRecordingRepository(db *gorm.DB) RecordingRepository {
+	return &recordingRepository{db: db}
+}
+
+// Create stores a recording unless its room already has a running one. The
+// room row is locked for the duration of the transaction so concurrent
+// starts for the same room are serialized.
+func (r *recordingRepository) Create(recording *model.Recording) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var room model.Room
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", recording.RoomID).
+			First(&room).Error
+		if err != nil {
+			return err
+		}
+
+		var running int64
+		if err := tx.Model(&model.Recording{}).
+			Where("room_id = ? AND status IN ?", recording.RoomID, runningRecordingStatuses).
+			Count(&running).Error; err != nil {
+			return err
+		}
+		if running > 0 {
+			return ErrRecordingRunning
+		}
+		return tx.Create(recording).Error
+	})
+}
+
+func (r *recordingRepository) FindByID(id string) (*model.Recording, error) {
+	var recording model.Recording
+	err := r.db.Where("id = ?", id).First(&recording).Error
+	if err != nil {
+		return nil, err
+	}
+	return &recording, nil
+}
+
+func (r *recordingRepository) FindByEgressID(egressID string) (*model.Recording, error) {
+	var recording model.Recording
+	err := r.db.Where("egress_id = ?", egressID).First(&recording).Error
+	if err != nil {
+		return nil, err
+	}
+	return &recording, nil
+}
+
+func (r *recordingRepository) FindByRoomID(roomID string) ([]model.Recording, error) {
+	var recordings []model.Recording
+	err := r.db.Where("room_id = ?", roomID).Order("created_at DESC").Find(&recordings).Error
+	return recordings, err
+}
+
+// FindRunning returns the room's recording that has not finished yet
+func (r *recordingRepository) FindRunning(roomID string) (*model.Recording, error) {
+	var recording model.Recording
+	err := r.db.Where("room_id = ? AND status IN ?", roomID, runningRecordingStatuses).
+		Order("created_at DESC").
+		First(&recording).Error
+	if err != nil {
+		return nil, err
+	}
+	return &recording, nil
+}
+
+// Update saves the recording only while it is still running, so a late or
+// out-of-order egress update can't move a finished recording back
+func (r *recordingRepository) Update(recording *model.Recording) error {
+	result := r.db.Model(recording).
+		Where("status IN ?", runningRecordingStatuses).
+		Select("*").
+		Updates(recording)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordingFinished
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"time"
+	"yourapp/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	Create(token *model.RefreshToken) error
+	FindByHash(tokenHash string) (*model.RefreshToken, error)
+	Rotate(id string, at time.Time) (bool, error)
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(token *model.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *refreshTokenRepository) FindByHash(tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate marks a token as used. Returns false if it was already used or
+// revoked, so two concurrent refreshes with one token cannot both succeed.
+func (r *refreshTokenRepository) Rotate(id string, at time.Time) (bool, error) {
+	result := r.db.Model(&model.RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", id).
+		Update("rotated_at", at)
+	return result.RowsAffected == 1, result.Error
+}
@@ -1,24 +1,84 @@
 package repository
 
 import (
+	"errors"
 	"time"
 	"yourapp/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrRoomFull is returned when joining would exceed the room's max_participants
+var ErrRoomFull = errors.New("room is full")
+
 type RoomRepository interface {
 	Create(room *model.Room) error
 	FindByID(id string) (*model.Room, error)
 	FindByIDWithParticipants(id string) (*model.Room, error)
 	FindByCreatedBy(userID string) ([]model.Room, error)
-	FindAll() ([]model.Room, error)
+	List(filter RoomFilter) ([]model.Room, int64, error)
 	Update(room *model.Room) error
+	UpdateDetails(room *model.Room) error
+	SetLobbyEnabled(roomID string, enabled bool) error
+	UpdateStatus(roomID, from, to string, at time.Time) (bool, error)
+	FindIdle(emptySince time.Time) ([]model.Room, error)
+	FindByStatusBefore(status string, before time.Time) ([]model.Room, error)
+	Purge(roomID string) error
 	Delete(id string) error
-	AddParticipant(roomID, userID string) error
+	AddParticipant(roomID, userID, role string) error
 	RemoveParticipant(roomID, userID string) error
 	IsParticipant(roomID, userID string) (bool, error)
 	GetParticipantCount(roomID string) (int64, error)
+	GetParticipantCounts(roomIDs []string) (map[string]int64, error)
+	FindParticipant(roomID, userID string) (*model.RoomParticipant, error)
+	FindParticipants(roomID string) ([]ParticipantDetail, error)
+	FindParticipantSessions(roomID string, from, to *time.Time) ([]SessionDetail, error)
+	UpdateParticipantRole(roomID, userID, role string) error
+	SetParticipantMuted(roomID, userID string, muted bool) error
+	MarkParticipantRemoved(roomID, userID, removedByID string) error
+	ClearParticipantRemoved(roomID, userID string) error
+	DeactivateParticipants(roomID string, at time.Time) error
+	StartParticipantSession(roomID, userID, role string, at time.Time) error
+	EndParticipantSession(roomID, userID string, at time.Time) error
+	HasAccess(roomID, userID string) (bool, error)
+	FindScheduled(from, until time.Time) ([]model.Room, error)
+	MarkReminderSent(roomID string, occurrence time.Time) (bool, error)
+}
+
+// SessionDetail is an attendance session joined with the user's profile
+type SessionDetail struct {
+	model.ParticipantSession
+	FullName string  `json:"full_name"`
+	Email    string  `json:"email"`
+	Username *string `json:"username,omitempty"`
+}
+
+// ParticipantDetail is a room participant row joined with the user's profile
+type ParticipantDetail struct {
+	model.RoomParticipant
+	FullName string  `json:"full_name"`
+	Email    string  `json:"email"`
+	Username *string `json:"username,omitempty"`
+}
+
+// Room list sort orders
+const (
+	RoomSortNewest       = "newest"
+	RoomSortParticipants = "participants"
+)
+
+// RoomFilter selects a page of rooms. Empty fields don't filter.
+type RoomFilter struct {
+	Visibility  string // Only rooms with this visibility
+	CreatedByID string
+	Statuses    []string // Only rooms in one of these lifecycle states
+	HasCapacity bool     // Rooms without a limit or with free seats
+	Search      string   // Case-insensitive match on the name
+	Sort        string   // RoomSortNewest (default) or RoomSortParticipants
+	Summary     bool     // Load only the summary columns, without the creator
+	Limit       int
+	Offset      int
 }
 
 type roomRepository struct {
@@ -57,50 +117,231 @@ func (r *roomRepository) FindByCreatedBy(userID string) ([]model.Room, error) {
 	return rooms, err
 }
 
-func (r *roomRepository) FindAll() ([]model.Room, error) {
+// List returns a page of rooms matching the filter and the total number of
+// matches. Participant counts are joined from a single aggregate subquery.
+func (r *roomRepository) List(filter RoomFilter) ([]model.Room, int64, error) {
+	query := func() *gorm.DB {
+		counts := r.db.Model(&model.RoomParticipant{}).
+			Select("room_id, COUNT(*) AS active_count").
+			Where("is_active = ?", true).
+			Group("room_id")
+
+		// Breakout rooms are reached through their meeting, never listed
+		q := r.db.Model(&model.Room{}).
+			Joins("LEFT JOIN (?) AS pc ON pc.room_id = rooms.id", counts).
+			Where("rooms.parent_room_id IS NULL")
+		if filter.Visibility != "" {
+			q = q.Where("rooms.visibility = ?", filter.Visibility)
+		}
+		if filter.CreatedByID != "" {
+			q = q.Where("rooms.created_by_id = ?", filter.CreatedByID)
+		}
+		if len(filter.Statuses) > 0 {
+			q = q.Where("rooms.status IN ?", filter.Statuses)
+		}
+		if filter.HasCapacity {
+			q = q.Where("(rooms.max_participants IS NULL OR COALESCE(pc.active_count, 0) < rooms.max_participants)")
+		}
+		if filter.Search != "" {
+			q = q.Where("rooms.name ILIKE ?", containsPattern(filter.Search))
+		}
+		return q
+	}
+
+	var total int64
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	q := query()
+	if filter.Summary {
+		q = q.Select("rooms.id", "rooms.name", "rooms.description", "rooms.status", "rooms.max_participants", "rooms.visibility", "rooms.created_by_id", "rooms.created_at")
+	} else {
+		q = q.Select("rooms.*").Preload("CreatedBy")
+	}
+	switch filter.Sort {
+	case RoomSortParticipants:
+		q = q.Order("COALESCE(pc.active_count, 0) DESC").Order("rooms.created_at DESC")
+	default:
+		q = q.Order("rooms.created_at DESC")
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		q = q.Offset(filter.Offset)
+	}
+
 	var rooms []model.Room
-	err := r.db.Preload("CreatedBy").Order("created_at DESC").Find(&rooms).Error
-	return rooms, err
+	err := q.Find(&rooms).Error
+	return rooms, total, err
 }
 
 func (r *roomRepository) Update(room *model.Room) error {
 	return r.db.Save(room).Error
 }
 
+// UpdateDetails saves the editable room fields without touching the status
+func (r *roomRepository) UpdateDetails(room *model.Room) error {
+	return r.db.Model(room).
+		Select("name", "description", "max_participants").
+		Updates(room).Error
+}
+
+// SetLobbyEnabled turns the waiting room on or off without touching other fields
+func (r *roomRepository) SetLobbyEnabled(roomID string, enabled bool) error {
+	return r.db.Model(&model.Room{}).
+		Where("id = ?", roomID).
+		Update("lobby_enabled", enabled).Error
+}
+
+// UpdateStatus moves a room from one lifecycle state to another. It reports
+// false if the room was no longer in the from state.
+func (r *roomRepository) UpdateStatus(roomID, from, to string, at time.Time) (bool, error) {
+	result := r.db.Model(&model.Room{}).
+		Where("id = ? AND status = ?", roomID, from).
+		Updates(map[string]interface{}{
+			"status":            to,
+			"status_changed_at": at,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindIdle returns live rooms that have had nobody in them since emptySince
+func (r *roomRepository) FindIdle(emptySince time.Time) ([]model.Room, error) {
+	occupied := r.db.Model(&model.RoomParticipant{}).
+		Select("1").
+		Where("room_participants.room_id = rooms.id").
+		Where("room_participants.is_active = ? OR room_participants.left_at >= ?", true, emptySince)
+
+	var rooms []model.Room
+	err := r.db.Where("status = ? AND status_changed_at < ?", model.RoomLive, emptySince).
+		Where("NOT EXISTS (?)", occupied).
+		Find(&rooms).Error
+	return rooms, err
+}
+
+// FindByStatusBefore returns rooms that entered the status before the given time
+func (r *roomRepository) FindByStatusBefore(status string, before time.Time) ([]model.Room, error) {
+	var rooms []model.Room
+	err := r.db.Where("status = ? AND status_changed_at < ?", status, before).Find(&rooms).Error
+	return rooms, err
+}
+
+// Purge permanently deletes a room together with its messages, notes,
+// transcripts and everything else stored for it
+func (r *roomRepository) Purge(roomID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		related := []interface{}{
+			&model.ChatMessage{},
+			&model.MeetingNote{}, // Action items cascade
+			&model.TranscriptSegment{},
+			&model.EmbeddingChunk{},
+			&model.RoomInvitation{},
+			&model.RoomInviteLink{},
+			&model.LobbyEntry{},
+			&model.ParticipantSession{},
+			&model.Recording{}, // Files stay on the egress storage
+			&model.BreakoutAssignment{},
+			&model.Poll{}, // Options and votes cascade
+			&model.RoomParticipant{},
+		}
+		for _, value := range related {
+			if err := tx.Unscoped().Where("room_id = ?", roomID).Delete(value).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("parent_room_id = ?", roomID).Delete(&model.BreakoutAssignment{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id = ?", roomID).Delete(&model.Room{}).Error
+	})
+}
+
 func (r *roomRepository) Delete(id string) error {
 	return r.db.Where("id = ?", id).Delete(&model.Room{}).Error
 }
 
-func (r *roomRepository) AddParticipant(roomID, userID string) error {
-	// Check if participant already exists
-	var existing model.RoomParticipant
-	err := r.db.Where("room_id = ? AND user_id = ?", roomID, userID).First(&existing).Error
+// AddParticipant marks the user as active in the room, enforcing
+// max_participants. The role is only used on first join; returning
+// participants keep the role they already have. The room row is locked for
+// the duration of the transaction so concurrent joins are serialized and
+// cannot exceed the limit. Participants who are already active do not count
+// twice, and every (re)join opens a new attendance session. A moderator's
+// removal is kept; callers decide whether a removed participant may rejoin.
+func (r *roomRepository) AddParticipant(roomID, userID, role string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var room model.Room
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "max_participants").
+			Where("id = ?", roomID).
+			First(&room).Error
+		if err != nil {
+			return err
+		}
 
-	if err == nil {
-		// Participant exists, just update to active
-		return r.db.Model(&existing).Updates(map[string]interface{}{
-			"is_active": true,
-			"left_at":   nil,
-		}).Error
-	}
+		// Check if participant already exists
+		var existing model.RoomParticipant
+		found := tx.Where("room_id = ? AND user_id = ?", roomID, userID).First(&existing).Error == nil
 
-	// Create new participant
-	participant := model.RoomParticipant{
-		RoomID:   roomID,
-		UserID:   userID,
-		IsActive: true,
-	}
-	return r.db.Create(&participant).Error
+		if room.MaxParticipants != nil && !(found && existing.IsActive) {
+			var count int64
+			if err := tx.Model(&model.RoomParticipant{}).
+				Where("room_id = ? AND is_active = ?", roomID, true).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count >= int64(*room.MaxParticipants) {
+				return ErrRoomFull
+			}
+		}
+
+		if found {
+			// Participant exists, just update to active
+			if err := tx.Model(&existing).Updates(map[string]interface{}{
+				"is_active": true,
+				"left_at":   nil,
+				"is_muted":  false,
+			}).Error; err != nil {
+				return err
+			}
+			if existing.IsActive {
+				return nil
+			}
+		} else {
+			// Create new participant
+			participant := model.RoomParticipant{
+				RoomID:   roomID,
+				UserID:   userID,
+				Role:     role,
+				IsActive: true,
+			}
+			if err := tx.Create(&participant).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&model.ParticipantSession{
+			RoomID:   roomID,
+			UserID:   userID,
+			JoinedAt: time.Now(),
+		}).Error
+	})
 }
 
 func (r *roomRepository) RemoveParticipant(roomID, userID string) error {
 	now := time.Now()
-	return r.db.Model(&model.RoomParticipant{}).
-		Where("room_id = ? AND user_id = ?", roomID, userID).
-		Updates(map[string]interface{}{
-			"is_active": false,
-			"left_at":   &now,
-		}).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := endAttendance(tx.Where("room_id = ? AND user_id = ?", roomID, userID), now); err != nil {
+			return err
+		}
+		return tx.Model(&model.RoomParticipant{}).
+			Where("room_id = ? AND user_id = ?", roomID, userID).
+			Updates(map[string]interface{}{
+				"is_active": false,
+				"left_at":   &now,
+			}).Error
+	})
 }
 
 func (r *roomRepository) IsParticipant(roomID, userID string) (bool, error) {
@@ -118,3 +359,210 @@ func (r *roomRepository) GetParticipantCount(roomID string) (int64, error) {
 		Count(&count).Error
 	return count, err
 }
+
+// GetParticipantCounts returns the active participant count of several rooms
+// in one query. Rooms without active participants are absent from the map.
+func (r *roomRepository) GetParticipantCounts(roomIDs []string) (map[string]int64, error) {
+	counts := make(map[string]int64, len(roomIDs))
+	if len(roomIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		RoomID string
+		Count  int64
+	}
+	err := r.db.Model(&model.RoomParticipant{}).
+		Select("room_id, COUNT(*) AS count").
+		Where("room_id IN ? AND is_active = ?", roomIDs, true).
+		Group("room_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.RoomID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *roomRepository) FindParticipant(roomID, userID string) (*model.RoomParticipant, error) {
+	var participant model.RoomParticipant
+	err := r.db.Where("room_id = ? AND user_id = ?", roomID, userID).First(&participant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+func (r *roomRepository) UpdateParticipantRole(roomID, userID, role string) error {
+	return r.db.Model(&model.RoomParticipant{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Update("role", role).Error
+}
+
+func (r *roomRepository) SetParticipantMuted(roomID, userID string, muted bool) error {
+	return r.db.Model(&model.RoomParticipant{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Update("is_muted", muted).Error
+}
+
+// MarkParticipantRemoved records that a moderator removed the participant
+func (r *roomRepository) MarkParticipantRemoved(roomID, userID, removedByID string) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := endAttendance(tx.Where("room_id = ? AND user_id = ?", roomID, userID), now); err != nil {
+			return err
+		}
+		return tx.Model(&model.RoomParticipant{}).
+			Where("room_id = ? AND user_id = ?", roomID, userID).
+			Updates(map[string]interface{}{
+				"is_active":     false,
+				"left_at":       &now,
+				"removed_by_id": removedByID,
+			}).Error
+	})
+}
+
+// ClearParticipantRemoved lifts a moderator's removal, e.g. when a host
+// admits the participant from the lobby again
+func (r *roomRepository) ClearParticipantRemoved(roomID, userID string) error {
+	return r.db.Model(&model.RoomParticipant{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Update("removed_by_id", nil).Error
+}
+
+// DeactivateParticipants marks every active participant of the room as left
+// and closes any open media sessions
+func (r *roomRepository) DeactivateParticipants(roomID string, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := closeSessions(tx.Where("room_id = ?", roomID), at); err != nil {
+			return err
+		}
+		if err := endAttendance(tx.Where("room_id = ?", roomID), at); err != nil {
+			return err
+		}
+		return tx.Model(&model.RoomParticipant{}).
+			Where("room_id = ? AND is_active = ?", roomID, true).
+			Updates(map[string]interface{}{
+				"is_active": false,
+				"left_at":   &at,
+			}).Error
+	})
+}
+
+// StartParticipantSession marks the participant as connected to the media
+// session. An already open session is kept so duplicate events are harmless.
+func (r *roomRepository) StartParticipantSession(roomID, userID, role string, at time.Time) error {
+	if err := r.AddParticipant(roomID, userID, role); err != nil {
+		return err
+	}
+	return r.db.Model(&model.RoomParticipant{}).
+		Where("room_id = ? AND user_id = ? AND session_started_at IS NULL", roomID, userID).
+		Update("session_started_at", at).Error
+}
+
+// EndParticipantSession marks the participant as disconnected and adds the
+// length of the open session to their total duration
+func (r *roomRepository) EndParticipantSession(roomID, userID string, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := closeSessions(tx.Where("room_id = ? AND user_id = ?", roomID, userID), at); err != nil {
+			return err
+		}
+		if err := endAttendance(tx.Where("room_id = ? AND user_id = ?", roomID, userID), at); err != nil {
+			return err
+		}
+		return tx.Model(&model.RoomParticipant{}).
+			Where("room_id = ? AND user_id = ?", roomID, userID).
+			Updates(map[string]interface{}{
+				"is_active": false,
+				"left_at":   &at,
+			}).Error
+	})
+}
+
+// closeSessions adds open session time to total_duration_seconds for the
+// participants matched by scope
+func closeSessions(scope *gorm.DB, at time.Time) error {
+	return scope.Model(&model.RoomParticipant{}).
+		Where("session_started_at IS NOT NULL").
+		Updates(map[string]interface{}{
+			"total_duration_seconds": gorm.Expr("total_duration_seconds + GREATEST(0, EXTRACT(EPOCH FROM (? - session_started_at)))::bigint", at),
+			"session_started_at":     nil,
+		}).Error
+}
+
+// endAttendance closes the open attendance sessions matched by scope
+func endAttendance(scope *gorm.DB, at time.Time) error {
+	return scope.Model(&model.ParticipantSession{}).
+		Where("left_at IS NULL").
+		Update("left_at", at).Error
+}
+
+// FindParticipantSessions returns the room's attendance sessions that overlap
+// the optional [from, to) window, oldest first
+func (r *roomRepository) FindParticipantSessions(roomID string, from, to *time.Time) ([]SessionDetail, error) {
+	query := r.db.Model(&model.ParticipantSession{}).
+		Select("participant_sessions.*, users.full_name, users.email, users.username").
+		Joins("JOIN users ON users.id = participant_sessions.user_id").
+		Where("participant_sessions.room_id = ?", roomID)
+	if from != nil {
+		query = query.Where("participant_sessions.left_at IS NULL OR participant_sessions.left_at > ?", *from)
+	}
+	if to != nil {
+		query = query.Where("participant_sessions.joined_at < ?", *to)
+	}
+
+	var sessions []SessionDetail
+	err := query.Order("participant_sessions.joined_at ASC").Scan(&sessions).Error
+	return sessions, err
+}
+
+func (r *roomRepository) FindParticipants(roomID string) ([]ParticipantDetail, error) {
+	var participants []ParticipantDetail
+	err := r.db.Model(&model.RoomParticipant{}).
+		Select("room_participants.*, users.full_name, users.email, users.username").
+		Joins("JOIN users ON users.id = room_participants.user_id").
+		Where("room_participants.room_id = ?", roomID).
+		Order("room_participants.joined_at ASC").
+		Scan(&participants).Error
+	return participants, err
+}
+
+// HasAccess reports whether the user created the room or has joined it and
+// was not removed by a moderator since
+func (r *roomRepository) HasAccess(roomID, userID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Room{}).
+		Where("id = ? AND created_by_id = ?", roomID, userID).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+
+	err = r.db.Model(&model.RoomParticipant{}).
+		Where("room_id = ? AND user_id = ? AND removed_by_id IS NULL", roomID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindScheduled returns open scheduled rooms that may have an occurrence
+// between from and until. Recurring rooms are always included; callers work
+// out the actual occurrence.
+func (r *roomRepository) FindScheduled(from, until time.Time) ([]model.Room, error) {
+	var rooms []model.Room
+	err := r.db.Preload("CreatedBy").
+		Where("status IN ? AND scheduled_start IS NOT NULL AND scheduled_start <= ?", []string{model.RoomScheduled, model.RoomLive}, until).
+		Where("recurrence_rule <> '' OR scheduled_end >= ?", from).
+		Find(&rooms).Error
+	return rooms, err
+}
+
+// MarkReminderSent claims the reminder of an occurrence. It reports false if
+// the reminder was already claimed, e.g. by another instance.
+func (r *roomRepository) MarkReminderSent(roomID string, occurrence time.Time) (bool, error) {
+	result := r.db.Model(&model.Room{}).
+		Where("id = ? AND last_reminder_for IS DISTINCT FROM ?", roomID, occurrence).
+		Update("last_reminder_for", occurrence)
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"yourapp/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB connects to the Postgres database in TEST_DATABASE_URL. Tests
+// that need real row locking are skipped when it is not set.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connecting to the test database: %v", err)
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.Room{}, &model.RoomParticipant{}, &model.ParticipantSession{}); err != nil {
+		t.Fatalf("migrating the test database: %v", err)
+	}
+	return db
+}
+
+func TestAddParticipantEnforcesCapacityUnderConcurrentJoins(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewRoomRepository(db)
+
+	const maxParticipants, joiners = 3, 12
+	run := uuid.New().String()[:8]
+	users := make([]model.User, joiners+1)
+	for i := range users {
+		users[i] = model.User{Email: fmt.Sprintf("join-%s-%d@example.com", run, i), FullName: "Joiner"}
+	}
+	if err := db.Create(&users).Error; err != nil {
+		t.Fatalf("creating users: %v", err)
+	}
+	limit := maxParticipants
+	room := model.Room{Name: "Capacity " + run, CreatedByID: users[0].ID, MaxParticipants: &limit}
+	if err := db.Create(&room).Error; err != nil {
+		t.Fatalf("creating room: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Unscoped().Where("room_id = ?", room.ID).Delete(&model.ParticipantSession{})
+		db.Unscoped().Where("room_id = ?", room.ID).Delete(&model.RoomParticipant{})
+		db.Unscoped().Delete(&room)
+		db.Unscoped().Delete(&users)
+	})
+
+	var (
+		wg              sync.WaitGroup
+		mu              sync.Mutex
+		joined, refused int
+	)
+	start := make(chan struct{})
+	for _, user := range users[1:] {
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			<-start
+			err := repo.AddParticipant(room.ID, userID, model.RoleParticipant)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				joined++
+			case errors.Is(err, ErrRoomFull):
+				refused++
+			default:
+				t.Errorf("AddParticipant(%s): %v", userID, err)
+			}
+		}(user.ID)
+	}
+	close(start)
+	wg.Wait()
+
+	if joined != maxParticipants || refused != joiners-maxParticipants {
+		t.Errorf("%d joined and %d were refused, want %d and %d", joined, refused, maxParticipants, joiners-maxParticipants)
+	}
+	count, err := repo.GetParticipantCount(room.ID)
+	if err != nil {
+		t.Fatalf("GetParticipantCount: %v", err)
+	}
+	if count != maxParticipants {
+		t.Errorf("%d active participants, want %d", count, maxParticipants)
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"time"
+	"yourapp/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(session *model.Session) error
+	FindByID(id string) (*model.Session, error)
+	FindActiveByUserID(userID string, now time.Time) ([]model.Session, error)
+	Touch(id, userAgent, ipAddress string, at, expiresAt time.Time) error
+	Revoke(userID, id string, at time.Time) (bool, error)
+	RevokeAllForUser(userID string, at time.Time) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(session *model.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepository) FindByID(id string) (*model.Session, error) {
+	var session model.Session
+	err := r.db.Where("id = ?", id).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindActiveByUserID returns sessions that are neither revoked nor expired,
+// most recently used first
+func (r *sessionRepository) FindActiveByUserID(userID string, now time.Time) ([]model.Session, error) {
+	var sessions []model.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Touch records a token refresh from the session's device
+func (r *sessionRepository) Touch(id, userAgent, ipAddress string, at, expiresAt time.Time) error {
+	return r.db.Model(&model.Session{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"user_agent":   userAgent,
+			"ip_address":   ipAddress,
+			"last_used_at": at,
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+// Revoke ends one of the user's sessions along with its refresh tokens.
+// Returns false if the user has no such active session.
+func (r *sessionRepository) Revoke(userID, id string, at time.Time) (bool, error) {
+	revoked := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Session{}).
+			Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+			Update("revoked_at", at)
+		if result.Error != nil {
+			return result.Error
+		}
+		revoked = result.RowsAffected == 1
+
+		return tx.Model(&model.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", at).Error
+	})
+	return revoked, err
+}
+
+// RevokeAllForUser ends every session of the user along with their refresh tokens
+func (r *sessionRepository) RevokeAllForUser(userID string, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", at).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", at).Error
+	})
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"time"
+	"yourapp/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type TranscriptRepository interface {
+	CreateBatch(segments []model.TranscriptSegment) error
+	FindByRoomID(roomID string, since *time.Time, limit int) ([]model.TranscriptSegment, error)
+	FindRecent(roomID string, limit int) ([]model.TranscriptSegment, error)
+	Search(roomID, query, speaker string, limit int) ([]model.TranscriptSegment, error)
+}
+
+type transcriptRepository struct {
+	db *gorm.DB
+}
+
+func NewTranscriptRepository(db *gorm.DB) TranscriptRepository {
+	return &transcriptRepository{db: db}
+}
+
+func (r *transcriptRepository) CreateBatch(segments []model.TranscriptSegment) error {
+	if len(segments) == 0 {
+		return nil
+	}
+	return r.db.Create(&segments).Error
+}
+
+func (r *transcriptRepository) FindByRoomID(roomID string, since *time.Time, limit int) ([]model.TranscriptSegment, error) {
+	var segments []model.TranscriptSegment
+	query := r.db.Where("room_id = ?", roomID)
+	if since != nil {
+		query = query.Where("start_time > ?", *since)
+	}
+	err := query.Order("start_time ASC").Limit(limit).Find(&segments).Error
+	return segments, err
+}
+
+// FindRecent returns the latest segments of a room in chronological order
+func (r *transcriptRepository) FindRecent(roomID string, limit int) ([]model.TranscriptSegment, error) {
+	var segments []model.TranscriptSegment
+	err := r.db.Where("room_id = ?", roomID).
+		Order("start_time DESC").
+		Limit(limit).
+		Find(&segments).Error
+
+	// Reverse order to show oldest first
+	for i, j := 0, len(segments)-1; i < j; i, j = i+1, j-1 {
+		segments[i], segments[j] = segments[j], segments[i]
+	}
+
+	return segments, err
+}
+
+func (r *transcriptRepository) Search(roomID, query, speaker string, limit int) ([]model.TranscriptSegment, error) {
+	var segments []model.TranscriptSegment
+	db := r.db.Where("room_id = ? AND text ILIKE ?", roomID, containsPattern(query))
+	if speaker != "" {
+		pattern := containsPattern(speaker)
+		db = db.Where("(speaker_name ILIKE ? OR speaker_identity ILIKE ?)", pattern, pattern)
+	}
+	err := db.Order("start_time ASC").Limit(limit).Find(&segments).Error
+	return segments, err
+}
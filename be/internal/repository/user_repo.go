@@ -1,12 +1,14 @@
 package repository
 
 import (
+	"crypto/subtle"
 	"errors"
 	"time"
 
 	"yourapp/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
@@ -17,13 +19,22 @@ type UserRepository interface {
 	FindByGoogleID(googleID string) (*model.User, error)
 	Update(user *model.User) error
 	UpdateOTP(email string, otpCode string, expiresAt time.Time) error
-	VerifyOTP(email string, otpCode string) (*model.User, error)
+	VerifyOTP(email string, otpCode string, maxAttempts int) (*model.User, error)
 	UpdateResetToken(email string, token string, expiresAt time.Time) error
 	FindByResetToken(token string) (*model.User, error)
 	UpdatePassword(userID string, passwordHash string) error
 	UpdateLastLogin(userID string) error
+	RecordFailedLogin(userID string, maxFailures int, lockFor time.Duration) (*time.Time, error)
+	ResetFailedLogins(userID string) error
 }
 
+var (
+	ErrInvalidOTP = errors.New("invalid or expired OTP")
+	// ErrOTPAttemptsExceeded is returned when a wrong code used up the last
+	// attempt; the OTP is discarded and a new one must be requested
+	ErrOTPAttemptsExceeded = errors.New("too many incorrect codes, please request a new OTP")
+)
+
 type userRepository struct {
 	db *gorm.DB
 }
@@ -82,24 +93,52 @@ func (r *userRepository) UpdateOTP(email string, otpCode string, expiresAt time.
 		Updates(map[string]interface{}{
 			"otp_code":       otpCode,
 			"otp_expires_at": expiresAt,
+			"otp_attempts":   0,
 		}).Error
 }
 
-func (r *userRepository) VerifyOTP(email string, otpCode string) (*model.User, error) {
+// VerifyOTP checks the user's OTP. Every wrong code counts as an attempt, and
+// after maxAttempts the OTP is discarded so it cannot be guessed.
+func (r *userRepository) VerifyOTP(email string, otpCode string, maxAttempts int) (*model.User, error) {
 	var user model.User
-	err := r.db.Where("email = ? AND otp_code = ? AND otp_expires_at > ?", email, otpCode, time.Now()).First(&user).Error
+	var verifyErr error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("email = ? AND otp_code IS NOT NULL AND otp_expires_at > ?", email, time.Now()).
+			First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			verifyErr = ErrInvalidOTP
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if subtle.ConstantTimeCompare([]byte(*user.OTPCode), []byte(otpCode)) != 1 {
+			updates := map[string]interface{}{"otp_attempts": user.OTPAttempts + 1}
+			verifyErr = ErrInvalidOTP
+			if user.OTPAttempts+1 >= maxAttempts {
+				updates["otp_code"] = nil
+				updates["otp_expires_at"] = nil
+				updates["otp_attempts"] = 0
+				verifyErr = ErrOTPAttemptsExceeded
+			}
+			return tx.Model(&model.User{}).Where("id = ?", user.ID).Updates(updates).Error
+		}
+
+		// Clear OTP after verification
+		user.OTPCode = nil
+		user.OTPExpiresAt = nil
+		user.OTPAttempts = 0
+		user.IsVerified = true
+		return tx.Save(&user).Error
+	})
 	if err != nil {
-		return nil, errors.New("invalid or expired OTP")
-	}
-
-	// Clear OTP after verification
-	user.OTPCode = nil
-	user.OTPExpiresAt = nil
-	user.IsVerified = true
-
-	if err := r.db.Save(&user).Error; err != nil {
 		return nil, err
 	}
+	if verifyErr != nil {
+		return nil, verifyErr
+	}
 
 	return &user, nil
 }
@@ -138,3 +177,35 @@ func (r *userRepository) UpdateLastLogin(userID string) error {
 		Where("id = ?", userID).
 		Update("last_login", now).Error
 }
+
+// RecordFailedLogin counts a wrong password. Reaching maxFailures locks the
+// account for lockFor and returns when the lock ends; otherwise nil.
+func (r *userRepository) RecordFailedLogin(userID string, maxFailures int, lockFor time.Duration) (*time.Time, error) {
+	var lockedUntil *time.Time
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "failed_logins").
+			Where("id = ?", userID).
+			First(&user).Error
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"failed_logins": user.FailedLogins + 1}
+		if user.FailedLogins+1 >= maxFailures {
+			until := time.Now().Add(lockFor)
+			lockedUntil = &until
+			updates["failed_logins"] = 0
+			updates["locked_until"] = until
+		}
+		return tx.Model(&model.User{}).Where("id = ?", userID).Updates(updates).Error
+	})
+	return lockedUntil, err
+}
+
+func (r *userRepository) ResetFailedLogins(userID string) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ? AND failed_logins > 0", userID).
+		Update("failed_logins", 0).Error
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"yourapp/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func createTestUser(t *testing.T, repo UserRepository) *model.User {
+	t.Helper()
+	user := &model.User{Email: "user-" + uuid.New().String()[:8] + "@example.com", FullName: "Test User"}
+	if err := repo.Create(user); err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	return user
+}
+
+func TestVerifyOTPDiscardsCodeAfterMaxAttempts(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewUserRepository(db)
+	user := createTestUser(t, repo)
+	t.Cleanup(func() { db.Unscoped().Delete(user) })
+
+	if err := repo.UpdateOTP(user.Email, "123456", time.Now().Add(10*time.Minute)); err != nil {
+		t.Fatalf("UpdateOTP: %v", err)
+	}
+	const maxAttempts = 3
+	for i := 1; i < maxAttempts; i++ {
+		if _, err := repo.VerifyOTP(user.Email, "000000", maxAttempts); !errors.Is(err, ErrInvalidOTP) {
+			t.Fatalf("wrong code #%d: got %v, want ErrInvalidOTP", i, err)
+		}
+	}
+	if _, err := repo.VerifyOTP(user.Email, "000000", maxAttempts); !errors.Is(err, ErrOTPAttemptsExceeded) {
+		t.Fatalf("wrong code #%d: got %v, want ErrOTPAttemptsExceeded", maxAttempts, err)
+	}
+	// The right code no longer works once the OTP was discarded
+	if _, err := repo.VerifyOTP(user.Email, "123456", maxAttempts); !errors.Is(err, ErrInvalidOTP) {
+		t.Errorf("right code after the OTP was discarded: got %v, want ErrInvalidOTP", err)
+	}
+
+	// A new OTP starts with a fresh attempt count
+	if err := repo.UpdateOTP(user.Email, "654321", time.Now().Add(10*time.Minute)); err != nil {
+		t.Fatalf("UpdateOTP: %v", err)
+	}
+	repo.VerifyOTP(user.Email, "000000", maxAttempts)
+	verified, err := repo.VerifyOTP(user.Email, "654321", maxAttempts)
+	if err != nil {
+		t.Fatalf("right code: %v", err)
+	}
+	if !verified.IsVerified || verified.OTPCode != nil || verified.OTPAttempts != 0 {
+		t.Errorf("verified user = verified %v, otp %v, attempts %d", verified.IsVerified, verified.OTPCode, verified.OTPAttempts)
+	}
+}
+
+func TestRecordFailedLoginLocksAccount(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewUserRepository(db)
+	user := createTestUser(t, repo)
+	t.Cleanup(func() { db.Unscoped().Delete(user) })
+
+	const maxFailures, lockFor = 3, 15 * time.Minute
+	for i := 1; i < maxFailures; i++ {
+		if lockedUntil, err := repo.RecordFailedLogin(user.ID, maxFailures, lockFor); err != nil || lockedUntil != nil {
+			t.Fatalf("failure #%d: locked until %v, err %v; want not locked", i, lockedUntil, err)
+		}
+	}
+	lockedUntil, err := repo.RecordFailedLogin(user.ID, maxFailures, lockFor)
+	if err != nil || lockedUntil == nil {
+		t.Fatalf("failure #%d: locked until %v, err %v; want locked", maxFailures, lockedUntil, err)
+	}
+	if d := time.Until(*lockedUntil); d <= lockFor-time.Minute || d > lockFor {
+		t.Errorf("locked for %v, want about %v", d, lockFor)
+	}
+
+	stored, err := repo.FindByID(user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if stored.LockedUntil == nil || stored.FailedLogins != 0 {
+		t.Errorf("stored lock = %v with %d failures, want locked and the count reset", stored.LockedUntil, stored.FailedLogins)
+	}
+
+	repo.RecordFailedLogin(user.ID, maxFailures, lockFor)
+	if err := repo.ResetFailedLogins(user.ID); err != nil {
+		t.Fatalf("ResetFailedLogins: %v", err)
+	}
+	if stored, _ := repo.FindByID(user.ID); stored.FailedLogins != 0 {
+		t.Errorf("failed logins = %d after reset, want 0", stored.FailedLogins)
+	}
+}
@@ -0,0 +1,489 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"yourapp/internal/repository"
+)
+
+// maxToolIterations limits how many tool round-trips a single prompt can trigger
+const maxToolIterations = 5
+
+// ToolContext identifies the room and user a tool call is executed for.
+// Tools never take a room ID from the model, so they can only see this room.
+type ToolContext struct {
+	RoomID string
+	UserID string
+	Trace  *ToolTrace // Optional, collects provenance of the answer
+}
+
+// ToolTrace records which tools ran and which room content they returned
+type ToolTrace struct {
+	ToolCalls  []string
+	MessageIDs []string
+	SegmentIDs []string
+}
+
+// AgentTool is a server-side function exposed to the model
+type AgentTool struct {
+	Definition FunctionDefinition
+	Handler    func(ctx ToolContext, args map[string]interface{}) (interface{}, error)
+}
+
+type AgentToolService interface {
+	Definitions() []ToolDefinition
+	Execute(ctx ToolContext, call ToolCall) (string, error)
+	ChatWithTools(request *KolosalChatRequest, ctx ToolContext) (*KolosalChatResponse, error)
+}
+
+type agentToolService struct {
+	kolosalService    KolosalService
+	roomRepo          repository.RoomRepository
+	chatRepo          repository.ChatRepository
+	noteService       NoteService
+	transcriptService TranscriptService
+	tools             map[string]AgentTool
+	order             []string
+}
+
+func NewAgentToolService(kolosalService KolosalService, roomRepo repository.RoomRepository, chatRepo repository.ChatRepository, noteService NoteService, transcriptService TranscriptService) AgentToolService {
+	s := &agentToolService{
+		kolosalService:    kolosalService,
+		roomRepo:          roomRepo,
+		chatRepo:          chatRepo,
+		noteService:       noteService,
+		transcriptService: transcriptService,
+		tools:             make(map[string]AgentTool),
+	}
+
+	s.register(AgentTool{
+		Definition: FunctionDefinition{
+			Name:        "list_participants",
+			Description: "List everyone who has joined the current meeting room, with join/leave times and whether they are still in the room.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"active_only": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Only return participants currently in the room",
+					},
+				},
+			},
+		},
+		Handler: s.listParticipants,
+	})
+	s.register(AgentTool{
+		Definition: FunctionDefinition{
+			Name:        "search_room_messages",
+			Description: "Search the chat history of the current meeting room for messages containing a keyword, optionally filtered by author name.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "Keyword or phrase to search for",
+					},
+					"author": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional author name, username or email to filter by",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of messages to return (default 20, max 50)",
+					},
+				},
+				"required": []string{"query"},
+			},
+		},
+		Handler: s.searchRoomMessages,
+	})
+	s.register(AgentTool{
+		Definition: FunctionDefinition{
+			Name:        "search_transcript",
+			Description: "Search the spoken transcript of the current meeting for a keyword, optionally filtered by speaker.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "Keyword or phrase to search for",
+					},
+					"speaker": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional speaker name or identity to filter by",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of segments to return (default 20, max 50)",
+					},
+				},
+				"required": []string{"query"},
+			},
+		},
+		Handler: s.searchTranscript,
+	})
+	s.register(AgentTool{
+		Definition: FunctionDefinition{
+			Name:        "get_room_info",
+			Description: "Get details about the current meeting room: name, description, host, capacity, participant and message counts.",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		Handler: s.getRoomInfo,
+	})
+	s.register(AgentTool{
+		Definition: FunctionDefinition{
+			Name:        "create_note",
+			Description: "Save a meeting note (e.g. decisions or action items) to the current meeting room so participants can see it.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "Short title for the note",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "The note body in markdown",
+					},
+					"action_items": map[string]interface{}{
+						"type":        "array",
+						"description": "Follow-up tasks captured in the note",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"description": map[string]interface{}{"type": "string"},
+								"assignee":    map[string]interface{}{"type": "string", "description": "Participant name"},
+								"due_date":    map[string]interface{}{"type": "string", "description": "YYYY-MM-DD"},
+							},
+							"required": []string{"description"},
+						},
+					},
+				},
+				"required": []string{"content"},
+			},
+		},
+		Handler: s.createNote,
+	})
+
+	return s
+}
+
+func (s *agentToolService) register(tool AgentTool) {
+	s.tools[tool.Definition.Name] = tool
+	s.order = append(s.order, tool.Definition.Name)
+}
+
+// Definitions returns the tool list in the format expected by KolosalChatRequest.Tools
+func (s *agentToolService) Definitions() []ToolDefinition {
+	definitions := make([]ToolDefinition, 0, len(s.order))
+	for _, name := range s.order {
+		definitions = append(definitions, ToolDefinition{
+			Type:     "function",
+			Function: s.tools[name].Definition,
+		})
+	}
+	return definitions
+}
+
+// Execute runs a single tool call and returns its JSON-encoded result
+func (s *agentToolService) Execute(ctx ToolContext, call ToolCall) (string, error) {
+	tool, ok := s.tools[call.Function.Name]
+	if !ok {
+		return "", fmt.Errorf("unknown tool: %s", call.Function.Name)
+	}
+
+	if err := s.authorize(ctx); err != nil {
+		return "", err
+	}
+
+	args := map[string]interface{}{}
+	if strings.TrimSpace(call.Function.Arguments) != "" {
+		if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments for %s: %w", call.Function.Name, err)
+		}
+	}
+
+	result, err := tool.Handler(ctx, args)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode result of %s: %w", call.Function.Name, err)
+	}
+	return string(encoded), nil
+}
+
+// ChatWithTools sends the request with the room tools attached and resolves
+// tool calls locally until the model produces a final answer
+func (s *agentToolService) ChatWithTools(request *KolosalChatRequest, ctx ToolContext) (*KolosalChatResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	request.Tools = s.Definitions()
+	if request.ToolChoice == nil {
+		request.ToolChoice = "auto"
+	}
+
+	usage := Usage{}
+	for i := 0; i < maxToolIterations; i++ {
+		response, err := s.kolosalService.ChatCompletions(request)
+		if err != nil {
+			return nil, err
+		}
+		usage.PromptTokens += response.Usage.PromptTokens
+		usage.CompletionTokens += response.Usage.CompletionTokens
+		usage.TotalTokens += response.Usage.TotalTokens
+
+		if len(response.Choices) == 0 || len(response.Choices[0].Message.ToolCalls) == 0 {
+			response.Usage = usage
+			return response, nil
+		}
+
+		assistantMessage := response.Choices[0].Message
+		request.Messages = append(request.Messages, assistantMessage)
+
+		for _, call := range assistantMessage.ToolCalls {
+			log.Printf("[AgentTools] room=%s user=%s calling %s(%s)", ctx.RoomID, ctx.UserID, call.Function.Name, call.Function.Arguments)
+			if ctx.Trace != nil {
+				ctx.Trace.ToolCalls = append(ctx.Trace.ToolCalls, call.Function.Name)
+			}
+			result, err := s.Execute(ctx, call)
+			if err != nil {
+				// Let the model see the failure instead of aborting the whole answer
+				encoded, _ := json.Marshal(map[string]string{"error": err.Error()})
+				result = string(encoded)
+			}
+			request.Messages = append(request.Messages, Message{
+				Role:       "tool",
+				Name:       call.Function.Name,
+				Content:    result,
+				ToolCallID: call.ID,
+			})
+		}
+	}
+
+	// Out of iterations - ask for a final answer without tools
+	request.Tools = nil
+	request.ToolChoice = nil
+	response, err := s.kolosalService.ChatCompletions(request)
+	if err != nil {
+		return nil, err
+	}
+	usage.PromptTokens += response.Usage.PromptTokens
+	usage.CompletionTokens += response.Usage.CompletionTokens
+	usage.TotalTokens += response.Usage.TotalTokens
+	response.Usage = usage
+	return response, nil
+}
+
+// authorize ensures the user is the room creator or has joined the room
+func (s *agentToolService) authorize(ctx ToolContext) error {
+	return ensureRoomAccess(s.roomRepo, ctx.RoomID, ctx.UserID)
+}
+
+func (s *agentToolService) listParticipants(ctx ToolContext, args map[string]interface{}) (interface{}, error) {
+	activeOnly, _ := args["active_only"].(bool)
+
+	participants, err := s.roomRepo.FindParticipants(ctx.RoomID)
+	if err != nil {
+		return nil, errors.New("failed to fetch participants")
+	}
+
+	result := make([]map[string]interface{}, 0, len(participants))
+	for _, p := range participants {
+		if activeOnly && !p.IsActive {
+			continue
+		}
+		entry := map[string]interface{}{
+			"name":      displayName(p.FullName, p.Username),
+			"email":     p.Email,
+			"role":      p.Role,
+			"joined_at": p.JoinedAt.Format(time.RFC3339),
+			"is_active": p.IsActive,
+		}
+		if p.LeftAt != nil {
+			entry["left_at"] = p.LeftAt.Format(time.RFC3339)
+		}
+		result = append(result, entry)
+	}
+
+	return map[string]interface{}{
+		"participants": result,
+		"count":        len(result),
+	}, nil
+}
+
+func (s *agentToolService) searchRoomMessages(ctx ToolContext, args map[string]interface{}) (interface{}, error) {
+	query, _ := args["query"].(string)
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("query is required")
+	}
+	author, _ := args["author"].(string)
+	author = strings.TrimSpace(author)
+
+	limit := 20
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	messages, err := s.chatRepo.SearchByRoomID(ctx.RoomID, query, author, limit)
+	if err != nil {
+		return nil, errors.New("failed to search messages")
+	}
+
+	result := make([]map[string]interface{}, 0, len(messages))
+	for _, msg := range messages {
+		name := displayName(msg.User.FullName, msg.User.Username)
+		result = append(result, map[string]interface{}{
+			"id":         msg.ID,
+			"author":     name,
+			"message":    msg.Message,
+			"created_at": msg.CreatedAt.Format(time.RFC3339),
+		})
+		if ctx.Trace != nil {
+			ctx.Trace.MessageIDs = append(ctx.Trace.MessageIDs, msg.ID)
+		}
+	}
+
+	return map[string]interface{}{
+		"messages": result,
+		"count":    len(result),
+	}, nil
+}
+
+func (s *agentToolService) searchTranscript(ctx ToolContext, args map[string]interface{}) (interface{}, error) {
+	query, _ := args["query"].(string)
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("query is required")
+	}
+	speaker, _ := args["speaker"].(string)
+
+	limit := 20
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	segments, err := s.transcriptService.Search(ctx.RoomID, query, strings.TrimSpace(speaker), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]interface{}, 0, len(segments))
+	for _, segment := range segments {
+		result = append(result, map[string]interface{}{
+			"id":         segment.ID,
+			"speaker":    segment.SpeakerName,
+			"text":       segment.Text,
+			"start_time": segment.StartTime.Format(time.RFC3339),
+		})
+		if ctx.Trace != nil {
+			ctx.Trace.SegmentIDs = append(ctx.Trace.SegmentIDs, segment.ID)
+		}
+	}
+
+	return map[string]interface{}{
+		"segments": result,
+		"count":    len(result),
+	}, nil
+}
+
+func (s *agentToolService) getRoomInfo(ctx ToolContext, args map[string]interface{}) (interface{}, error) {
+	room, err := s.roomRepo.FindByIDWithParticipants(ctx.RoomID)
+	if err != nil {
+		return nil, errors.New("room not found")
+	}
+
+	participantCount, _ := s.roomRepo.GetParticipantCount(ctx.RoomID)
+	messageCount, _ := s.chatRepo.GetMessageCount(ctx.RoomID)
+
+	info := map[string]interface{}{
+		"name":              room.Name,
+		"host":              displayName(room.CreatedBy.FullName, room.CreatedBy.Username),
+		"status":            room.Status,
+		"participant_count": participantCount,
+		"message_count":     messageCount,
+		"created_at":        room.CreatedAt.Format(time.RFC3339),
+	}
+	if room.Description != nil {
+		info["description"] = *room.Description
+	}
+	if room.MaxParticipants != nil {
+		info["max_participants"] = *room.MaxParticipants
+	}
+	return info, nil
+}
+
+func (s *agentToolService) createNote(ctx ToolContext, args map[string]interface{}) (interface{}, error) {
+	content, _ := args["content"].(string)
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("content is required")
+	}
+
+	title, _ := args["title"].(string)
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = "AI note"
+	}
+
+	req := CreateNoteRequest{Title: title, Body: content}
+	if items, ok := args["action_items"].([]interface{}); ok {
+		for _, raw := range items {
+			item, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			description, _ := item["description"].(string)
+			if strings.TrimSpace(description) == "" {
+				continue
+			}
+			actionItem := ActionItemRequest{Description: description}
+			if assignee, ok := item["assignee"].(string); ok && assignee != "" {
+				actionItem.AssigneeName = &assignee
+			}
+			if dueDate, ok := item["due_date"].(string); ok && dueDate != "" {
+				if _, err := time.Parse("2006-01-02", dueDate); err == nil {
+					actionItem.DueDate = &dueDate
+				}
+			}
+			req.ActionItems = append(req.ActionItems, actionItem)
+		}
+	}
+
+	note, err := s.noteService.CreateNote(ctx.RoomID, ctx.UserID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"id":      note.ID,
+		"title":   note.Title,
+		"created": true,
+	}, nil
+}
+
+// displayName prefers the username over the full name, matching chat responses
+func displayName(fullName string, username *string) string {
+	if username != nil && *username != "" {
+		return *username
+	}
+	return fullName
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+// AttendanceService builds attendance reports from the join/leave sessions
+// recorded for each participant
+type AttendanceService interface {
+	GetAttendance(roomID, userID string, query AttendanceQuery) (*AttendanceReport, error)
+}
+
+type attendanceService struct {
+	roomRepo repository.RoomRepository
+}
+
+func NewAttendanceService(roomRepo repository.RoomRepository) AttendanceService {
+	return &attendanceService{
+		roomRepo: roomRepo,
+	}
+}
+
+// AttendanceQuery limits the report to sessions within [from, to). Both
+// accept RFC 3339 timestamps or plain dates (YYYY-MM-DD, UTC). A date-only
+// to includes that whole day, so from=2025-03-01&to=2025-03-01 covers March 1.
+type AttendanceQuery struct {
+	From   string `form:"from"`
+	To     string `form:"to"`
+	Format string `form:"format"` // json (default) or csv
+}
+
+type AttendanceReport struct {
+	RoomID      string            `json:"room_id"`
+	RoomName    string            `json:"room_name"`
+	From        *time.Time        `json:"from,omitempty"`
+	To          *time.Time        `json:"to,omitempty"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Attendees   []AttendanceEntry `json:"attendees"`
+}
+
+// AttendanceEntry summarizes one user's sessions. Time outside the report
+// window is not counted.
+type AttendanceEntry struct {
+	UserID        string     `json:"user_id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	TotalSeconds  int64      `json:"total_seconds"`
+	Sessions      int        `json:"sessions"`
+	RejoinCount   int        `json:"rejoin_count"`
+	FirstJoinedAt time.Time  `json:"first_joined_at"`
+	LastLeftAt    *time.Time `json:"last_left_at,omitempty"` // Nil while the user is still in the room
+	InRoom        bool       `json:"in_room"`
+}
+
+// GetAttendance returns per-user attendance for the room. Only the host and
+// co-hosts can see it.
+func (s *attendanceService) GetAttendance(roomID, userID string, query AttendanceQuery) (*AttendanceReport, error) {
+	room, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := parseReportTime(query.From, "from", false)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseReportTime(query.To, "to", true)
+	if err != nil {
+		return nil, err
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, errors.New("from must be before to")
+	}
+
+	sessions, err := s.roomRepo.FindParticipantSessions(roomID, from, to)
+	if err != nil {
+		return nil, errors.New("failed to fetch attendance")
+	}
+
+	now := time.Now()
+	report := &AttendanceReport{
+		RoomID:      room.ID,
+		RoomName:    room.Name,
+		From:        from,
+		To:          to,
+		GeneratedAt: now,
+		Attendees:   summarizeAttendance(sessions, from, to, now),
+	}
+	return report, nil
+}
+
+// summarizeAttendance groups sessions by user, clipping them to the window.
+// Sessions must be ordered by join time; users come out in order of first join.
+func summarizeAttendance(sessions []repository.SessionDetail, from, to *time.Time, now time.Time) []AttendanceEntry {
+	byUser := make(map[string]*AttendanceEntry)
+	var order []string
+	for _, session := range sessions {
+		entry, ok := byUser[session.UserID]
+		if !ok {
+			entry = &AttendanceEntry{
+				UserID:        session.UserID,
+				Name:          displayName(session.FullName, session.Username),
+				Email:         session.Email,
+				FirstJoinedAt: session.JoinedAt,
+			}
+			byUser[session.UserID] = entry
+			order = append(order, session.UserID)
+		}
+
+		entry.Sessions++
+		if session.LeftAt == nil {
+			entry.InRoom = true
+		} else if entry.LastLeftAt == nil || session.LeftAt.After(*entry.LastLeftAt) {
+			entry.LastLeftAt = session.LeftAt
+		}
+
+		start, end := session.JoinedAt, now
+		if session.LeftAt != nil {
+			end = *session.LeftAt
+		}
+		if from != nil && start.Before(*from) {
+			start = *from
+		}
+		if to != nil && end.After(*to) {
+			end = *to
+		}
+		if end.After(start) {
+			entry.TotalSeconds += int64(end.Sub(start) / time.Second)
+		}
+	}
+
+	entries := make([]AttendanceEntry, 0, len(order))
+	for _, id := range order {
+		entry := byUser[id]
+		entry.RejoinCount = entry.Sessions - 1
+		if entry.InRoom {
+			entry.LastLeftAt = nil
+		}
+		entries = append(entries, *entry)
+	}
+	return entries
+}
+
+// parseReportTime parses a report bound. With endOfDay, a plain date stands
+// for the end of that day (midnight of the next) rather than its start.
+func parseReportTime(value, field string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return &t, nil
+	}
+	return nil, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", field)
+}
+
+// AttendanceCSV renders the report as CSV, one row per attendee
+func AttendanceCSV(report *AttendanceReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := []string{"user_id", "name", "email", "first_joined_at", "last_left_at", "total_seconds", "total_time", "sessions", "rejoin_count", "in_room"}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, entry := range report.Attendees {
+		lastLeft := ""
+		if entry.LastLeftAt != nil {
+			lastLeft = entry.LastLeftAt.UTC().Format(time.RFC3339)
+		}
+		total := time.Duration(entry.TotalSeconds) * time.Second
+		row := []string{
+			entry.UserID,
+			csvSafe(entry.Name),
+			csvSafe(entry.Email),
+			entry.FirstJoinedAt.UTC().Format(time.RFC3339),
+			lastLeft,
+			strconv.FormatInt(entry.TotalSeconds, 10),
+			fmt.Sprintf("%02d:%02d:%02d", int(total.Hours()), int(total.Minutes())%60, int(total.Seconds())%60),
+			strconv.Itoa(entry.Sessions),
+			strconv.Itoa(entry.RejoinCount),
+			strconv.FormatBool(entry.InRoom),
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// csvSafe keeps spreadsheet apps from evaluating user-controlled cells as formulas
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
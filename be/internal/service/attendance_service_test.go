@@ -0,0 +1,178 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+func attendanceSession(userID string, joined time.Time, left *time.Time) repository.SessionDetail {
+	return repository.SessionDetail{
+		ParticipantSession: model.ParticipantSession{UserID: userID, JoinedAt: joined, LeftAt: left},
+		FullName:           "User " + userID,
+		Email:              userID + "@example.com",
+	}
+}
+
+func TestSummarizeAttendance(t *testing.T) {
+	base := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hour, min int) time.Time {
+		return base.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+	}
+	left := func(hour, min int) *time.Time { t := at(hour, min); return &t }
+
+	// Ordered by join time, as the repository returns them
+	sessions := []repository.SessionDetail{
+		attendanceSession("b", at(8, 30), left(9, 15)),  // starts before the window
+		attendanceSession("a", at(9, 0), left(9, 30)),   // inside the window
+		attendanceSession("c", at(9, 10), left(9, 20)),  // left and came back
+		attendanceSession("c", at(9, 50), nil),          // still in the room
+		attendanceSession("a", at(10, 0), left(10, 20)), // ends after the window
+	}
+	now := at(10, 30)
+
+	t.Run("window", func(t *testing.T) {
+		from, to := at(9, 0), at(10, 10)
+		entries := summarizeAttendance(sessions, &from, &to, now)
+		want := []struct {
+			userID      string
+			total       int64
+			sessions    int
+			rejoins     int
+			inRoom      bool
+			lastLeftAt  *time.Time
+			firstJoined time.Time
+		}{
+			{"b", 15 * 60, 1, 0, false, left(9, 15), at(8, 30)},
+			{"a", 30*60 + 10*60, 2, 1, false, left(10, 20), at(9, 0)},
+			{"c", 10*60 + 20*60, 2, 1, true, nil, at(9, 10)},
+		}
+		if len(entries) != len(want) {
+			t.Fatalf("got %d entries, want %d", len(entries), len(want))
+		}
+		for i, w := range want {
+			e := entries[i]
+			if e.UserID != w.userID {
+				t.Fatalf("entry %d is %s, want %s (order of first join)", i, e.UserID, w.userID)
+			}
+			if e.TotalSeconds != w.total || e.Sessions != w.sessions || e.RejoinCount != w.rejoins || e.InRoom != w.inRoom {
+				t.Errorf("%s: total %d, sessions %d, rejoins %d, in room %v; want %d, %d, %d, %v",
+					e.UserID, e.TotalSeconds, e.Sessions, e.RejoinCount, e.InRoom, w.total, w.sessions, w.rejoins, w.inRoom)
+			}
+			if (e.LastLeftAt == nil) != (w.lastLeftAt == nil) || (e.LastLeftAt != nil && !e.LastLeftAt.Equal(*w.lastLeftAt)) {
+				t.Errorf("%s: last left at %v, want %v", e.UserID, e.LastLeftAt, w.lastLeftAt)
+			}
+			if !e.FirstJoinedAt.Equal(w.firstJoined) {
+				t.Errorf("%s: first joined at %v, want %v", e.UserID, e.FirstJoinedAt, w.firstJoined)
+			}
+		}
+	})
+
+	t.Run("open session counts until now", func(t *testing.T) {
+		entries := summarizeAttendance(sessions, nil, nil, now)
+		if c := entries[2]; c.TotalSeconds != 10*60+40*60 {
+			t.Errorf("c: total %d, want %d", c.TotalSeconds, 10*60+40*60)
+		}
+	})
+
+	t.Run("session outside the window", func(t *testing.T) {
+		from, to := at(11, 0), at(12, 0)
+		entries := summarizeAttendance(sessions[:2], &from, &to, now)
+		for _, e := range entries {
+			if e.TotalSeconds != 0 {
+				t.Errorf("%s: total %d, want 0", e.UserID, e.TotalSeconds)
+			}
+		}
+	})
+}
+
+func TestAttendanceCSVEscapesFormulas(t *testing.T) {
+	joined := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	left := joined.Add(90 * time.Minute)
+	names := []string{"=HYPERLINK(\"http://example.com\")", "+1", "-1", "@SUM(A1)", "Plain Name"}
+	report := &AttendanceReport{}
+	for _, name := range names {
+		report.Attendees = append(report.Attendees, AttendanceEntry{
+			UserID:        "user",
+			Name:          name,
+			Email:         "=cmd@example.com",
+			TotalSeconds:  int64(90 * 60),
+			Sessions:      1,
+			FirstJoinedAt: joined,
+			LastLeftAt:    &left,
+		})
+	}
+
+	out, err := AttendanceCSV(report)
+	if err != nil {
+		t.Fatalf("AttendanceCSV: %v", err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	if len(rows) != len(names)+1 {
+		t.Fatalf("got %d rows, want a header and %d attendees", len(rows), len(names))
+	}
+
+	for i, name := range names {
+		row := rows[i+1]
+		want := "'" + name
+		if name == "Plain Name" {
+			want = name
+		}
+		if row[1] != want {
+			t.Errorf("name cell = %q, want %q", row[1], want)
+		}
+		if row[2] != "'=cmd@example.com" {
+			t.Errorf("email cell = %q, want it escaped", row[2])
+		}
+		if row[4] != "2025-03-01T10:30:00Z" || row[5] != "5400" || row[6] != "01:30:00" {
+			t.Errorf("times = %q, %q, %q; want 2025-03-01T10:30:00Z, 5400, 01:30:00", row[4], row[5], row[6])
+		}
+	}
+}
+
+// attendanceRoomRepo records the window sessions were requested for
+type attendanceRoomRepo struct {
+	*fakeRoomRepo
+	from, to *time.Time
+}
+
+func (r *attendanceRoomRepo) FindParticipantSessions(roomID string, from, to *time.Time) ([]repository.SessionDetail, error) {
+	r.from, r.to = from, to
+	return nil, nil
+}
+
+func TestGetAttendanceDateOnlyToIncludesThatDay(t *testing.T) {
+	rooms := &attendanceRoomRepo{fakeRoomRepo: newFakeRoomRepo()}
+	rooms.rooms["room-1"] = &model.Room{ID: "room-1", CreatedByID: "host"}
+	svc := NewAttendanceService(rooms)
+
+	if _, err := svc.GetAttendance("room-1", "host", AttendanceQuery{From: "2025-03-01", To: "2025-03-01"}); err != nil {
+		t.Fatalf("GetAttendance: %v", err)
+	}
+	wantFrom := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	wantTo := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	if rooms.from == nil || !rooms.from.Equal(wantFrom) || rooms.to == nil || !rooms.to.Equal(wantTo) {
+		t.Errorf("window = [%v, %v), want [%v, %v)", rooms.from, rooms.to, wantFrom, wantTo)
+	}
+
+	// Timestamps are taken as given
+	if _, err := svc.GetAttendance("room-1", "host", AttendanceQuery{To: "2025-03-01T12:00:00Z"}); err != nil {
+		t.Fatalf("GetAttendance: %v", err)
+	}
+	if want := wantFrom.Add(12 * time.Hour); rooms.to == nil || !rooms.to.Equal(want) {
+		t.Errorf("to = %v, want %v", rooms.to, want)
+	}
+
+	if _, err := svc.GetAttendance("room-1", "host", AttendanceQuery{From: "2025-03-02", To: "2025-03-01"}); err == nil {
+		t.Error("from after to: got nil, want an error")
+	}
+	if _, err := svc.GetAttendance("room-1", "host", AttendanceQuery{To: "yesterday"}); err == nil {
+		t.Error("invalid to: got nil, want an error")
+	}
+}
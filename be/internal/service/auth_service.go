@@ -1,10 +1,12 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"math/rand"
+	"strings"
 	"time"
 
 	"yourapp/internal/config"
@@ -15,23 +17,67 @@ import (
 
 type AuthService interface {
 	Register(req RegisterRequest) (*RegisterResponse, error)
-	Login(req LoginRequest) (*AuthResponse, error)
-	VerifyOTP(email, otpCode string) (*AuthResponse, error)
+	Login(req LoginRequest, client ClientInfo) (*AuthResponse, error)
+	VerifyOTP(email, otpCode string, client ClientInfo) (*AuthResponse, error)
 	ResendOTP(email string) error
-	GoogleOAuth(req GoogleOAuthRequest) (*AuthResponse, error)
-	RefreshToken(refreshToken string) (*AuthResponse, error)
+	GoogleOAuth(req GoogleOAuthRequest, client ClientInfo) (*AuthResponse, error)
+	RefreshToken(refreshToken string, client ClientInfo) (*AuthResponse, error)
+	Logout(refreshToken string) error
+	LogoutAll(userID string) error
+	ListSessions(userID, currentSessionID string) ([]SessionResponse, error)
+	RevokeSession(userID, sessionID string) error
+	IsSessionActive(sessionID string) bool
 	RequestResetPassword(email string) error
 	VerifyResetPassword(email, otpCode, newPassword string) error
-	ResetPassword(token, newPassword string) (*AuthResponse, error)
-	VerifyEmail(token string) (*AuthResponse, error)
+	ResetPassword(token, newPassword string, client ClientInfo) (*AuthResponse, error)
+	VerifyEmail(token string, client ClientInfo) (*AuthResponse, error)
 	GetMe(userID string) (*model.User, error)
 }
 
 type authService struct {
-	userRepo  repository.UserRepository
-	jwtSecret string
-	rabbitMQ  *util.RabbitMQClient
-	config    *config.Config
+	userRepo      repository.UserRepository
+	refreshTokens repository.RefreshTokenRepository
+	sessions      repository.SessionRepository
+	jwtKeys       *util.JWTKeySet
+	rabbitMQ      *util.RabbitMQClient
+	limiter       util.RateLimiter
+	config        *config.Config
+	google        *util.GoogleTokenVerifier // nil when Google sign-in is not configured
+}
+
+var (
+	// ErrGoogleSignInDisabled is returned when no Google client ID is configured
+	ErrGoogleSignInDisabled = errors.New("Google sign-in is not configured")
+	ErrInvalidRefreshToken  = errors.New("invalid refresh token")
+	ErrSessionNotFound      = errors.New("session not found")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token
+	// is presented again; the login it belongs to is signed out
+	ErrRefreshTokenReused = errors.New("refresh token has already been used, please log in again")
+)
+
+// Brute-force protection
+const (
+	otpMaxAttempts   = 5 // Wrong codes before an OTP is discarded
+	loginMaxFailures = 5 // Wrong passwords before the account is locked
+	loginLockout     = 15 * time.Minute
+)
+
+// Per-account limits, on top of the per-IP limits of the routes
+var (
+	loginAccountLimit = util.RateLimit{Burst: 10, Period: 15 * time.Minute}
+	otpAccountLimit   = util.RateLimit{Burst: 10, Period: 10 * time.Minute} // OTP checks
+	otpSendLimit      = util.RateLimit{Burst: 3, Period: 10 * time.Minute}  // OTP emails
+)
+
+// RateLimitError is returned when too many attempts were made; the client may
+// try again after RetryAfter
+type RateLimitError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return e.Message
 }
 
 type RegisterRequest struct {
@@ -50,11 +96,11 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// GoogleOAuthRequest carries either an ID token from Google sign-in or an
+// auth code to exchange for one
 type GoogleOAuthRequest struct {
-	Email        string `json:"email" binding:"required,email"`
-	FullName     string `json:"full_name" binding:"required"`
-	ProfilePhoto string `json:"profile_photo"`
-	GoogleID     string `json:"google_id" binding:"required"`
+	IDToken string `json:"id_token" binding:"required_without=Code"`
+	Code    string `json:"code" binding:"required_without=IDToken"`
 }
 
 type RegisterResponse struct {
@@ -64,6 +110,18 @@ type RegisterResponse struct {
 	VerificationToken    *string     `json:"verification_token,omitempty"`
 }
 
+// ClientInfo describes the device a sign-in or refresh request came from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SessionResponse is a session as shown to its owner
+type SessionResponse struct {
+	model.Session
+	Current bool `json:"current"` // The session the request was made with
+}
+
 type AuthResponse struct {
 	User         *model.User `json:"user"`
 	AccessToken  string      `json:"access_token"`
@@ -71,23 +129,33 @@ type AuthResponse struct {
 	ExpiresIn    int         `json:"expires_in"`
 }
 
-func NewAuthService(userRepo repository.UserRepository, jwtSecret string, rabbitMQ *util.RabbitMQClient) AuthService {
+func NewAuthService(userRepo repository.UserRepository, refreshTokens repository.RefreshTokenRepository, sessions repository.SessionRepository, jwtKeys *util.JWTKeySet, rabbitMQ *util.RabbitMQClient, limiter util.RateLimiter) AuthService {
 	return &authService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
-		rabbitMQ:  rabbitMQ,
-		config:    nil, // Will be set if needed
+		userRepo:      userRepo,
+		refreshTokens: refreshTokens,
+		sessions:      sessions,
+		jwtKeys:       jwtKeys,
+		rabbitMQ:      rabbitMQ,
+		limiter:       limiter,
+		config:        nil, // Will be set if needed
 	}
 }
 
 // NewAuthServiceWithConfig creates auth service with config for RabbitMQ reconnection
-func NewAuthServiceWithConfig(userRepo repository.UserRepository, jwtSecret string, rabbitMQ *util.RabbitMQClient, cfg *config.Config) AuthService {
-	return &authService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
-		rabbitMQ:  rabbitMQ,
-		config:    cfg,
-	}
+func NewAuthServiceWithConfig(userRepo repository.UserRepository, refreshTokens repository.RefreshTokenRepository, sessions repository.SessionRepository, jwtKeys *util.JWTKeySet, rabbitMQ *util.RabbitMQClient, limiter util.RateLimiter, cfg *config.Config) AuthService {
+	s := &authService{
+		userRepo:      userRepo,
+		refreshTokens: refreshTokens,
+		sessions:      sessions,
+		jwtKeys:       jwtKeys,
+		rabbitMQ:      rabbitMQ,
+		limiter:       limiter,
+		config:        cfg,
+	}
+	if cfg.GoogleClientID != "" {
+		s.google = util.NewGoogleTokenVerifier(cfg.GoogleClientID, cfg.GoogleClientSecret, cfg.GoogleRedirectURL, cfg.GoogleJWKSURL, cfg.GoogleTokenURL)
+	}
+	return s
 }
 
 // ensureRabbitMQ ensures RabbitMQ connection is available, reconnects if needed
@@ -205,7 +273,11 @@ func (s *authService) Register(req RegisterRequest) (*RegisterResponse, error) {
 	}, nil
 }
 
-func (s *authService) Login(req LoginRequest) (*AuthResponse, error) {
+func (s *authService) Login(req LoginRequest, client ClientInfo) (*AuthResponse, error) {
+	if err := s.throttle("login", req.Email, loginAccountLimit); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil {
 		return nil, errors.New("invalid email or password")
@@ -221,10 +293,21 @@ func (s *authService) Login(req LoginRequest) (*AuthResponse, error) {
 		return nil, errors.New("invalid email or password")
 	}
 
+	// Refuse logins while the account is locked after failed attempts
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return nil, accountLockedError(time.Until(*user.LockedUntil))
+	}
+
 	// Check password
 	if !util.CheckPasswordHash(req.Password, user.PasswordHash) {
+		if err := s.recordFailedLogin(user, client); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("invalid email or password")
 	}
+	if err := s.userRepo.ResetFailedLogins(user.ID); err != nil {
+		log.Printf("[Auth] Failed to reset failed logins of user %s: %v", user.ID, err)
+	}
 
 	// Check if user is active
 	if !user.IsActive {
@@ -233,6 +316,10 @@ func (s *authService) Login(req LoginRequest) (*AuthResponse, error) {
 
 	// Check if email is verified
 	if !user.IsVerified {
+		if err := s.throttle("send_otp", req.Email, otpSendLimit); err != nil {
+			return nil, err
+		}
+
 		// Generate new OTP
 		otpCode := generateOTP()
 		otpExpiresAt := time.Now().Add(10 * time.Minute)
@@ -264,27 +351,15 @@ func (s *authService) Login(req LoginRequest) (*AuthResponse, error) {
 	// Update last login
 	s.userRepo.UpdateLastLogin(user.ID)
 
-	// Generate tokens
-	accessToken, err := util.GenerateAccessToken(user.ID, user.Email, user.UserType, s.jwtSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
-	}
+	return s.startSession(user, client)
+}
 
-	refreshToken, err := util.GenerateRefreshToken(user.ID, user.Email, user.UserType, s.jwtSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+func (s *authService) VerifyOTP(email, otpCode string, client ClientInfo) (*AuthResponse, error) {
+	if err := s.throttle("verify_otp", email, otpAccountLimit); err != nil {
+		return nil, err
 	}
 
-	return &AuthResponse{
-		User:         user,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    900, // 15 minutes in seconds
-	}, nil
-}
-
-func (s *authService) VerifyOTP(email, otpCode string) (*AuthResponse, error) {
-	user, err := s.userRepo.VerifyOTP(email, otpCode)
+	user, err := s.userRepo.VerifyOTP(email, otpCode, otpMaxAttempts)
 	if err != nil {
 		return nil, err
 	}
@@ -292,26 +367,14 @@ func (s *authService) VerifyOTP(email, otpCode string) (*AuthResponse, error) {
 	// Update last login
 	s.userRepo.UpdateLastLogin(user.ID)
 
-	// Generate tokens
-	accessToken, err := util.GenerateAccessToken(user.ID, user.Email, user.UserType, s.jwtSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
-	}
-
-	refreshToken, err := util.GenerateRefreshToken(user.ID, user.Email, user.UserType, s.jwtSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
-	}
-
-	return &AuthResponse{
-		User:         user,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    900,
-	}, nil
+	return s.startSession(user, client)
 }
 
 func (s *authService) ResendOTP(email string) error {
+	if err := s.throttle("send_otp", email, otpSendLimit); err != nil {
+		return err
+	}
+
 	_, err := s.userRepo.FindByEmail(email)
 	if err != nil {
 		return errors.New("user not found")
@@ -350,103 +413,199 @@ func (s *authService) ResendOTP(email string) error {
 	return nil
 }
 
-func (s *authService) GoogleOAuth(req GoogleOAuthRequest) (*AuthResponse, error) {
+func (s *authService) GoogleOAuth(req GoogleOAuthRequest, client ClientInfo) (*AuthResponse, error) {
+	if s.google == nil {
+		return nil, ErrGoogleSignInDisabled
+	}
+
+	// Only trust identity claims from a token signed by Google for this client
+	var claims *util.GoogleIDClaims
+	var err error
+	if req.IDToken != "" {
+		claims, err = s.google.Verify(req.IDToken)
+	} else {
+		claims, err = s.google.Exchange(req.Code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, fmt.Errorf("%w: email is not verified", util.ErrInvalidGoogleToken)
+	}
+	googleID := claims.Subject
+
 	// Check if user exists by Google ID
-	user, err := s.userRepo.FindByGoogleID(req.GoogleID)
+	user, err := s.userRepo.FindByGoogleID(googleID)
 	if err == nil {
 		// User exists, update and return tokens
 		user.LastLogin = &[]time.Time{time.Now()}[0]
 		s.userRepo.UpdateLastLogin(user.ID)
 
-		accessToken, _ := util.GenerateAccessToken(user.ID, user.Email, user.UserType, s.jwtSecret)
-		refreshToken, _ := util.GenerateRefreshToken(user.ID, user.Email, user.UserType, s.jwtSecret)
-
-		return &AuthResponse{
-			User:         user,
-			AccessToken:  accessToken,
-			RefreshToken: refreshToken,
-			ExpiresIn:    900,
-		}, nil
+		return s.startSession(user, client)
 	}
 
 	// Check if email already exists
-	existingUser, _ := s.userRepo.FindByEmail(req.Email)
+	existingUser, _ := s.userRepo.FindByEmail(claims.Email)
 	if existingUser != nil {
 		// Check if user registered with credential instead of Google
 		if existingUser.LoginType == "credential" {
 			return nil, errors.New("email sudah terdaftar dengan email dan password. Silakan login dengan email dan password")
 		}
-		if existingUser.GoogleID != nil && *existingUser.GoogleID != req.GoogleID {
+		if existingUser.GoogleID != nil && *existingUser.GoogleID != googleID {
 			return nil, errors.New("email already registered with different Google account")
 		}
+
+		// Google accounts created before the Google ID was stored: link the
+		// verified subject to the existing user instead of creating another
+		existingUser.GoogleID = &googleID
+		if err := s.userRepo.Update(existingUser); err != nil {
+			return nil, errors.New("failed to link Google account")
+		}
+		s.userRepo.UpdateLastLogin(existingUser.ID)
+
+		return s.startSession(existingUser, client)
 	}
 
 	// Create new user
+	fullName := claims.Name
+	if fullName == "" {
+		fullName = claims.Email
+	}
+	var profilePhoto *string
+	if claims.Picture != "" {
+		profilePhoto = &claims.Picture
+	}
 	user = &model.User{
-		Email:        req.Email,
-		FullName:     req.FullName,
-		ProfilePhoto: &req.ProfilePhoto,
+		Email:        claims.Email,
+		FullName:     fullName,
+		ProfilePhoto: profilePhoto,
 		UserType:     "member",
 		IsActive:     true,
 		IsVerified:   true, // Google users are auto-verified
 		LoginType:    "google",
-		GoogleID:     &req.GoogleID,
+		GoogleID:     &googleID,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// Generate tokens
-	accessToken, err := util.GenerateAccessToken(user.ID, user.Email, user.UserType, s.jwtSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
-	}
+	return s.startSession(user, client)
+}
 
-	refreshToken, err := util.GenerateRefreshToken(user.ID, user.Email, user.UserType, s.jwtSecret)
+// RefreshToken exchanges a refresh token for new tokens. The presented token
+// is rotated out; presenting it again revokes its session.
+func (s *authService) RefreshToken(refreshToken string, client ClientInfo) (*AuthResponse, error) {
+	claims, err := util.ValidateToken(refreshToken, s.jwtKeys, util.TokenTypeRefresh)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		return nil, ErrInvalidRefreshToken
 	}
 
-	return &AuthResponse{
-		User:         user,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    900,
-	}, nil
-}
+	stored, err := s.refreshTokens.FindByHash(util.HashToken(refreshToken))
+	if err != nil || stored.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
 
-func (s *authService) RefreshToken(refreshToken string) (*AuthResponse, error) {
-	claims, err := util.ValidateToken(refreshToken, s.jwtSecret)
+	now := time.Now()
+	rotated, err := s.refreshTokens.Rotate(stored.ID, now)
 	if err != nil {
-		return nil, errors.New("invalid refresh token")
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		// Someone else already used this token, so it has leaked
+		log.Printf("[Auth] Refresh token reuse detected: user=%s, family=%s", stored.UserID, stored.FamilyID)
+		if _, err := s.sessions.Revoke(stored.UserID, stored.FamilyID, now); err != nil {
+			log.Printf("[Auth] Failed to revoke session %s: %v", stored.FamilyID, err)
+		}
+		return nil, ErrRefreshTokenReused
 	}
 
 	user, err := s.userRepo.FindByID(claims.UserID)
 	if err != nil {
 		return nil, errors.New("user not found")
 	}
+	if !user.IsActive {
+		return nil, errors.New("account is deactivated")
+	}
 
-	// Generate new tokens
-	accessToken, err := util.GenerateAccessToken(user.ID, user.Email, user.UserType, s.jwtSecret)
+	session, err := s.sessions.FindByID(stored.FamilyID)
+	if err != nil || !session.IsActive(now) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err := s.sessions.Touch(session.ID, client.UserAgent, client.IPAddress, now, now.Add(util.RefreshTokenTTL)); err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	return s.issueTokens(user, session.ID)
+}
+
+// Logout ends the session the refresh token belongs to
+func (s *authService) Logout(refreshToken string) error {
+	if _, err := util.ValidateToken(refreshToken, s.jwtKeys, util.TokenTypeRefresh); err != nil {
+		return ErrInvalidRefreshToken
+	}
+
+	stored, err := s.refreshTokens.FindByHash(util.HashToken(refreshToken))
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+		return ErrInvalidRefreshToken
+	}
+
+	if _, err := s.sessions.Revoke(stored.UserID, stored.FamilyID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// LogoutAll ends every session of the user
+func (s *authService) LogoutAll(userID string) error {
+	if err := s.sessions.RevokeAllForUser(userID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
 	}
+	return nil
+}
 
-	newRefreshToken, err := util.GenerateRefreshToken(user.ID, user.Email, user.UserType, s.jwtSecret)
+// ListSessions returns the user's active sessions, marking the one the
+// request was made with
+func (s *authService) ListSessions(userID, currentSessionID string) ([]SessionResponse, error) {
+	sessions, err := s.sessions.FindActiveByUserID(userID, time.Now())
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
 	}
 
-	return &AuthResponse{
-		User:         user,
-		AccessToken:  accessToken,
-		RefreshToken: newRefreshToken,
-		ExpiresIn:    900,
-	}, nil
+	result := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		result[i] = SessionResponse{Session: session, Current: session.ID == currentSessionID}
+	}
+	return result, nil
+}
+
+// RevokeSession signs one of the user's devices out
+func (s *authService) RevokeSession(userID, sessionID string) error {
+	revoked, err := s.sessions.Revoke(userID, sessionID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// IsSessionActive reports whether access tokens of the session are still
+// accepted
+func (s *authService) IsSessionActive(sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+	session, err := s.sessions.FindByID(sessionID)
+	return err == nil && session.IsActive(time.Now())
 }
 
 func (s *authService) RequestResetPassword(email string) error {
+	if err := s.throttle("send_otp", email, otpSendLimit); err != nil {
+		return err
+	}
+
 	// Check if email exists in database first - must exist before sending email
 	user, err := s.userRepo.FindByEmail(email)
 	if err != nil || user == nil {
@@ -494,6 +653,10 @@ func (s *authService) RequestResetPassword(email string) error {
 }
 
 func (s *authService) VerifyResetPassword(email, otpCode, newPassword string) error {
+	if err := s.throttle("verify_otp", email, otpAccountLimit); err != nil {
+		return err
+	}
+
 	// First, verify that email exists in database and check login type before OTP verification
 	existingUser, err := s.userRepo.FindByEmail(email)
 	if err != nil || existingUser == nil {
@@ -507,9 +670,9 @@ func (s *authService) VerifyResetPassword(email, otpCode, newPassword string) er
 	}
 
 	// Verify OTP code - this will also validate email, OTP, and expiry
-	user, err := s.userRepo.VerifyOTP(email, otpCode)
+	user, err := s.userRepo.VerifyOTP(email, otpCode, otpMaxAttempts)
 	if err != nil {
-		return errors.New("invalid or expired OTP")
+		return err
 	}
 
 	// Double check login type after OTP verification (should be same, but extra security)
@@ -528,21 +691,21 @@ func (s *authService) VerifyResetPassword(email, otpCode, newPassword string) er
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
+	// Sign out every device that logged in with the old password
+	if err := s.LogoutAll(user.ID); err != nil {
+		log.Printf("[Auth] Failed to sign out user %s after password reset: %v", user.ID, err)
+	}
+
 	return nil
 }
 
-func (s *authService) ResetPassword(token, newPassword string) (*AuthResponse, error) {
+func (s *authService) ResetPassword(token, newPassword string, client ClientInfo) (*AuthResponse, error) {
 	// Validate JWT token first
-	claims, err := util.ValidateToken(token, s.jwtSecret)
+	claims, err := util.ValidateToken(token, s.jwtKeys, util.TokenTypeReset)
 	if err != nil {
 		return nil, errors.New("invalid or expired reset token")
 	}
 
-	// Verify token is for reset password (userType should be "reset")
-	if claims.UserType != "reset" {
-		return nil, errors.New("invalid reset token")
-	}
-
 	// Find user by ID from token
 	user, err := s.userRepo.FindByID(claims.UserID)
 	if err != nil {
@@ -570,29 +733,18 @@ func (s *authService) ResetPassword(token, newPassword string) (*AuthResponse, e
 		return nil, fmt.Errorf("failed to update password: %w", err)
 	}
 
-	// Generate tokens
-	accessToken, err := util.GenerateAccessToken(user.ID, user.Email, user.UserType, s.jwtSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
-	}
-
-	refreshToken, err := util.GenerateRefreshToken(user.ID, user.Email, user.UserType, s.jwtSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	// Sign out every device that logged in with the old password
+	if err := s.LogoutAll(user.ID); err != nil {
+		log.Printf("[Auth] Failed to sign out user %s after password reset: %v", user.ID, err)
 	}
 
-	return &AuthResponse{
-		User:         user,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    900,
-	}, nil
+	return s.startSession(user, client)
 }
 
-func (s *authService) VerifyEmail(token string) (*AuthResponse, error) {
+func (s *authService) VerifyEmail(token string, client ClientInfo) (*AuthResponse, error) {
 	// For now, treat token as OTP code
 	// In production, you might want to use JWT token
-	claims, err := util.ValidateToken(token, s.jwtSecret)
+	claims, err := util.ValidateToken(token, s.jwtKeys, util.TokenTypeVerifyEmail)
 	if err != nil {
 		// If token validation fails, try as OTP
 		// This is a simplified approach - in production, use proper email verification tokens
@@ -609,29 +761,113 @@ func (s *authService) VerifyEmail(token string) (*AuthResponse, error) {
 		return nil, fmt.Errorf("failed to verify user: %w", err)
 	}
 
-	// Generate tokens
-	accessToken, err := util.GenerateAccessToken(user.ID, user.Email, user.UserType, s.jwtSecret)
+	return s.startSession(user, client)
+}
+
+func (s *authService) GetMe(userID string) (*model.User, error) {
+	return s.userRepo.FindByID(userID)
+}
+
+// throttle takes a token from the account's bucket for an action
+func (s *authService) throttle(action, email string, limit util.RateLimit) error {
+	if s.limiter == nil {
+		return nil
+	}
+	key := fmt.Sprintf("account:%s:%s", action, strings.ToLower(strings.TrimSpace(email)))
+	if allowed, retryAfter := s.limiter.Allow(key, limit); !allowed {
+		return &RateLimitError{Message: "too many attempts, please try again later", RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+func accountLockedError(retryAfter time.Duration) error {
+	return &RateLimitError{
+		Message:    "account is temporarily locked after too many failed login attempts",
+		RetryAfter: retryAfter,
+	}
+}
+
+// recordFailedLogin counts a wrong password and, when that locks the account,
+// tells the owner by email and returns the lock error
+func (s *authService) recordFailedLogin(user *model.User, client ClientInfo) error {
+	lockedUntil, err := s.userRepo.RecordFailedLogin(user.ID, loginMaxFailures, loginLockout)
+	if err != nil {
+		log.Printf("[Auth] Failed to record failed login of user %s: %v", user.ID, err)
+		return nil
+	}
+	if lockedUntil == nil {
+		return nil
+	}
+
+	log.Printf("[Auth] Account locked after %d failed logins: user=%s, ip=%s", loginMaxFailures, user.ID, client.IPAddress)
+	go func() {
+		s.ensureRabbitMQ() // Try to reconnect if needed
+		if s.rabbitMQ == nil {
+			log.Printf("Warning: RabbitMQ not available, account locked email not sent for %s", user.Email)
+			return
+		}
+		payload, _ := json.Marshal(AccountLockedEmail{
+			Minutes:   int(loginLockout.Minutes()),
+			IPAddress: client.IPAddress,
+		})
+		if err := s.rabbitMQ.PublishEmail(util.EmailMessage{
+			To:      user.Email,
+			Subject: "Akun Dikunci Sementara",
+			Type:    "account_locked",
+			Payload: payload,
+		}); err != nil {
+			log.Printf("Failed to publish account locked email: %v\n", err)
+		}
+	}()
+
+	return accountLockedError(time.Until(*lockedUntil))
+}
+
+// startSession signs the user in on a new device session
+func (s *authService) startSession(user *model.User, client ClientInfo) (*AuthResponse, error) {
+	now := time.Now()
+	session := &model.Session{
+		UserID:     user.ID,
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(util.RefreshTokenTTL),
+	}
+	if err := s.sessions.Create(session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return s.issueTokens(user, session.ID)
+}
+
+// issueTokens issues a new access and refresh token for a session
+func (s *authService) issueTokens(user *model.User, sessionID string) (*AuthResponse, error) {
+	accessToken, err := util.GenerateAccessToken(user.ID, user.Email, user.UserType, sessionID, s.jwtKeys)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	refreshToken, err := util.GenerateRefreshToken(user.ID, user.Email, user.UserType, s.jwtSecret)
+	refreshToken, err := util.GenerateRefreshToken(user.ID, user.Email, user.UserType, sessionID, s.jwtKeys)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
+	if err := s.refreshTokens.Create(&model.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  sessionID,
+		TokenHash: util.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(util.RefreshTokenTTL),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
 	return &AuthResponse{
 		User:         user,
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-		ExpiresIn:    900,
+		ExpiresIn:    int(util.AccessTokenTTL.Seconds()),
 	}, nil
 }
 
-func (s *authService) GetMe(userID string) (*model.User, error) {
-	return s.userRepo.FindByID(userID)
-}
-
 // generateOTP generates a 6-digit OTP
 func generateOTP() string {
 	rand.Seed(time.Now().UnixNano())
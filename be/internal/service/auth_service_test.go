@@ -0,0 +1,241 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+	"yourapp/internal/util"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// googleUserRepo adds the writes GoogleOAuth makes to fakeUserRepo
+type googleUserRepo struct {
+	fakeUserRepo
+	created []*model.User
+}
+
+func (r *googleUserRepo) FindByGoogleID(googleID string) (*model.User, error) {
+	return r.find(func(u *model.User) bool { return u.GoogleID != nil && *u.GoogleID == googleID })
+}
+
+func (r *googleUserRepo) Create(user *model.User) error {
+	if _, err := r.FindByEmail(user.Email); err == nil {
+		return errors.New(`duplicate key value violates unique constraint "idx_users_email"`)
+	}
+	r.created = append(r.created, user)
+	r.users = append(r.users, user)
+	return nil
+}
+
+func (r *googleUserRepo) Update(user *model.User) error {
+	for i, existing := range r.users {
+		if existing.ID == user.ID {
+			copied := *user
+			r.users[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (r *googleUserRepo) UpdateLastLogin(userID string) error { return nil }
+
+type fakeSessionRepo struct{ repository.SessionRepository }
+
+func (fakeSessionRepo) Create(session *model.Session) error {
+	session.ID = "session-1"
+	return nil
+}
+
+type fakeRefreshTokenRepo struct {
+	repository.RefreshTokenRepository
+}
+
+func (fakeRefreshTokenRepo) Create(token *model.RefreshToken) error { return nil }
+
+// googleIDToken serves a JWKS for a fresh key and returns a token for
+// subject and email signed with it, plus the JWKS URL
+func googleIDToken(t *testing.T, clientID, subject, email string) (string, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "key-1",
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, &util.GoogleIDClaims{
+		Email:         email,
+		EmailVerified: true,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "accounts.google.com",
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{clientID},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	token.Header["kid"] = "key-1"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return signed, srv.URL
+}
+
+func TestGoogleOAuthLinksLegacyGoogleUser(t *testing.T) {
+	const clientID = "client-123.apps.googleusercontent.com"
+	idToken, jwksURL := googleIDToken(t, clientID, "google-sub-1", "legacy@example.com")
+
+	users := &googleUserRepo{fakeUserRepo: fakeUserRepo{users: []*model.User{
+		{ID: "legacy", Email: "legacy@example.com", FullName: "Legacy", LoginType: "google", IsVerified: true},
+	}}}
+	cfg := &config.Config{GoogleClientID: clientID, GoogleJWKSURL: jwksURL}
+	auth := NewAuthServiceWithConfig(users, fakeRefreshTokenRepo{}, fakeSessionRepo{}, util.NewHMACKeySet("secret"), nil, nil, cfg)
+
+	for i := 0; i < 2; i++ {
+		response, err := auth.GoogleOAuth(GoogleOAuthRequest{IDToken: idToken}, ClientInfo{})
+		if err != nil {
+			t.Fatalf("GoogleOAuth #%d: %v", i+1, err)
+		}
+		if response.User.ID != "legacy" {
+			t.Errorf("GoogleOAuth #%d signed in as %s, want the existing user", i+1, response.User.ID)
+		}
+	}
+	if len(users.created) != 0 {
+		t.Errorf("created %d users, want the existing one reused", len(users.created))
+	}
+	linked, _ := users.FindByID("legacy")
+	if linked.GoogleID == nil || *linked.GoogleID != "google-sub-1" {
+		t.Errorf("google_id = %v, want the verified subject stored", linked.GoogleID)
+	}
+}
+
+// otpUserRepo counts the OTPs Login issues to unverified accounts
+type otpUserRepo struct {
+	fakeUserRepo
+	otpsIssued int
+}
+
+func (r *otpUserRepo) UpdateOTP(email string, otpCode string, expiresAt time.Time) error {
+	r.otpsIssued++
+	return nil
+}
+
+func (r *otpUserRepo) ResetFailedLogins(userID string) error { return nil }
+
+func TestLoginThrottlesOTPForUnverifiedAccount(t *testing.T) {
+	hash, err := util.HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	users := &otpUserRepo{fakeUserRepo: fakeUserRepo{users: []*model.User{
+		{ID: "pending", Email: "pending@example.com", PasswordHash: hash, LoginType: "credential", IsActive: true},
+	}}}
+	auth := NewAuthService(users, fakeRefreshTokenRepo{}, fakeSessionRepo{}, util.NewHMACKeySet("secret"), nil, util.NewMemoryRateLimiter())
+	req := LoginRequest{Email: "pending@example.com", Password: "correct horse"}
+
+	for i := 0; i < otpSendLimit.Burst; i++ {
+		if _, err := auth.Login(req, ClientInfo{}); err == nil || err.Error() != "email not verified. Please verify your email first" {
+			t.Fatalf("Login #%d: got %v, want the unverified error", i+1, err)
+		}
+	}
+	var limited *RateLimitError
+	if _, err := auth.Login(req, ClientInfo{}); !errors.As(err, &limited) {
+		t.Fatalf("Login past the OTP limit: got %v, want a RateLimitError", err)
+	}
+	if users.otpsIssued != otpSendLimit.Burst {
+		t.Errorf("issued %d OTPs, want %d", users.otpsIssued, otpSendLimit.Burst)
+	}
+}
+
+// lockoutUserRepo counts failed logins and locks accounts like the repository
+type lockoutUserRepo struct {
+	fakeUserRepo
+}
+
+func (r *lockoutUserRepo) RecordFailedLogin(userID string, maxFailures int, lockFor time.Duration) (*time.Time, error) {
+	for _, user := range r.users {
+		if user.ID != userID {
+			continue
+		}
+		user.FailedLogins++
+		if user.FailedLogins < maxFailures {
+			return nil, nil
+		}
+		until := time.Now().Add(lockFor)
+		user.FailedLogins, user.LockedUntil = 0, &until
+		return &until, nil
+	}
+	return nil, errors.New("user not found")
+}
+
+func (r *lockoutUserRepo) ResetFailedLogins(userID string) error {
+	for _, user := range r.users {
+		if user.ID == userID {
+			user.FailedLogins = 0
+		}
+	}
+	return nil
+}
+
+func (r *lockoutUserRepo) UpdateLastLogin(userID string) error { return nil }
+
+func TestLoginLocksAccountAfterFailures(t *testing.T) {
+	hash, err := util.HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	account := &model.User{ID: "user-1", Email: "user@example.com", PasswordHash: hash, LoginType: "credential", IsActive: true, IsVerified: true}
+	users := &lockoutUserRepo{fakeUserRepo: fakeUserRepo{users: []*model.User{account}}}
+	store := &sessionStore{sessions: make(map[string]*model.Session), tokens: make(map[string]*model.RefreshToken)}
+	auth := NewAuthService(users, memRefreshTokenRepo{sessionStore: store}, memSessionRepo{sessionStore: store}, util.NewHMACKeySet("secret"), nil, nil)
+	wrong := LoginRequest{Email: "user@example.com", Password: "wrong"}
+	right := LoginRequest{Email: "user@example.com", Password: "correct horse"}
+
+	for i := 1; i < loginMaxFailures; i++ {
+		if _, err := auth.Login(wrong, ClientInfo{}); err == nil || err.Error() != "invalid email or password" {
+			t.Fatalf("wrong password #%d: got %v", i, err)
+		}
+	}
+	var locked *RateLimitError
+	if _, err := auth.Login(wrong, ClientInfo{}); !errors.As(err, &locked) {
+		t.Fatalf("wrong password #%d: got %v, want the account locked", loginMaxFailures, err)
+	}
+	if locked.RetryAfter <= loginLockout-time.Minute || locked.RetryAfter > loginLockout {
+		t.Errorf("retry after %v, want about %v", locked.RetryAfter, loginLockout)
+	}
+
+	// Even the right password is refused while locked
+	if _, err := auth.Login(right, ClientInfo{}); !errors.As(err, &locked) {
+		t.Errorf("right password while locked: got %v, want the account locked", err)
+	}
+
+	expired := time.Now().Add(-time.Second)
+	account.LockedUntil = &expired
+	account.FailedLogins = 2
+	if _, err := auth.Login(right, ClientInfo{}); err != nil {
+		t.Fatalf("login after the lockout expired: %v", err)
+	}
+	if account.FailedLogins != 0 {
+		t.Errorf("failed logins = %d after a successful login, want 0", account.FailedLogins)
+	}
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+	"yourapp/internal/util"
+
+	"gorm.io/gorm"
+)
+
+// sessionStore holds sessions and refresh tokens in memory. Revoking a
+// session revokes its refresh tokens, as the repository does.
+type sessionStore struct {
+	sessions map[string]*model.Session
+	tokens   map[string]*model.RefreshToken // by hash
+	nextID   int
+}
+
+func (s *sessionStore) id(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%d", prefix, s.nextID)
+}
+
+func (s *sessionStore) revokeFamily(match func(*model.RefreshToken) bool, at time.Time) {
+	for _, token := range s.tokens {
+		if match(token) && token.RevokedAt == nil {
+			token.RevokedAt = &at
+		}
+	}
+}
+
+type memSessionRepo struct {
+	repository.SessionRepository
+	*sessionStore
+}
+
+func (r memSessionRepo) Create(session *model.Session) error {
+	session.ID = r.id("session")
+	copied := *session
+	r.sessions[session.ID] = &copied
+	return nil
+}
+
+func (r memSessionRepo) FindByID(id string) (*model.Session, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (r memSessionRepo) Touch(id, userAgent, ipAddress string, at, expiresAt time.Time) error {
+	r.sessions[id].LastUsedAt, r.sessions[id].ExpiresAt = at, expiresAt
+	return nil
+}
+
+func (r memSessionRepo) Revoke(userID, id string, at time.Time) (bool, error) {
+	session, ok := r.sessions[id]
+	revoked := ok && session.UserID == userID && session.RevokedAt == nil
+	if revoked {
+		session.RevokedAt = &at
+	}
+	r.revokeFamily(func(t *model.RefreshToken) bool { return t.FamilyID == id }, at)
+	return revoked, nil
+}
+
+func (r memSessionRepo) RevokeAllForUser(userID string, at time.Time) error {
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+		}
+	}
+	r.revokeFamily(func(t *model.RefreshToken) bool { return t.UserID == userID }, at)
+	return nil
+}
+
+type memRefreshTokenRepo struct {
+	repository.RefreshTokenRepository
+	*sessionStore
+}
+
+func (r memRefreshTokenRepo) Create(token *model.RefreshToken) error {
+	token.ID = r.id("token")
+	copied := *token
+	r.tokens[token.TokenHash] = &copied
+	return nil
+}
+
+func (r memRefreshTokenRepo) FindByHash(tokenHash string) (*model.RefreshToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r memRefreshTokenRepo) Rotate(id string, at time.Time) (bool, error) {
+	for _, token := range r.tokens {
+		if token.ID == id {
+			if token.RotatedAt != nil || token.RevokedAt != nil {
+				return false, nil
+			}
+			token.RotatedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newSessionTestAuth(t *testing.T) (*authService, *sessionStore) {
+	t.Helper()
+	store := &sessionStore{sessions: make(map[string]*model.Session), tokens: make(map[string]*model.RefreshToken)}
+	users := &fakeUserRepo{users: []*model.User{{ID: "user-1", Email: "user@example.com", IsActive: true}}}
+	auth := NewAuthService(users, memRefreshTokenRepo{sessionStore: store}, memSessionRepo{sessionStore: store}, util.NewHMACKeySet("secret"), nil, nil)
+	return auth.(*authService), store
+}
+
+// signIn starts a new device session for user-1
+func signIn(t *testing.T, auth *authService) *AuthResponse {
+	t.Helper()
+	user, _ := auth.userRepo.FindByID("user-1")
+	response, err := auth.startSession(user, ClientInfo{UserAgent: "test"})
+	if err != nil {
+		t.Fatalf("startSession: %v", err)
+	}
+	return response
+}
+
+func sessionOf(t *testing.T, auth *authService, token string) string {
+	t.Helper()
+	claims, err := util.ValidateToken(token, auth.jwtKeys, util.TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	return claims.SessionID
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	auth, store := newSessionTestAuth(t)
+	first := signIn(t, auth)
+
+	second, err := auth.RefreshToken(first.RefreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh returned the same refresh token")
+	}
+	if sessionOf(t, auth, second.AccessToken) != sessionOf(t, auth, first.AccessToken) {
+		t.Error("refresh moved the device to a new session")
+	}
+	if store.tokens[util.HashToken(first.RefreshToken)].RotatedAt == nil {
+		t.Error("presented refresh token was not rotated out")
+	}
+
+	if _, err := auth.RefreshToken(second.RefreshToken, ClientInfo{}); err != nil {
+		t.Errorf("refreshing with the new token: %v", err)
+	}
+	if _, err := auth.RefreshToken(first.AccessToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refreshing with an access token: got %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	auth, _ := newSessionTestAuth(t)
+	stolen := signIn(t, auth)
+	other := signIn(t, auth)
+
+	current, err := auth.RefreshToken(stolen.RefreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if _, err := auth.RefreshToken(stolen.RefreshToken, ClientInfo{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("presenting a rotated token: got %v, want ErrRefreshTokenReused", err)
+	}
+
+	if auth.IsSessionActive(sessionOf(t, auth, current.AccessToken)) {
+		t.Error("session is still active after reuse was detected")
+	}
+	if _, err := auth.RefreshToken(current.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("latest token of the family: got %v, want ErrInvalidRefreshToken", err)
+	}
+	if !auth.IsSessionActive(sessionOf(t, auth, other.AccessToken)) {
+		t.Error("reuse on one device signed the other device out")
+	}
+}
+
+func TestLogout(t *testing.T) {
+	auth, _ := newSessionTestAuth(t)
+	device := signIn(t, auth)
+	other := signIn(t, auth)
+
+	if err := auth.Logout(device.RefreshToken); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if auth.IsSessionActive(sessionOf(t, auth, device.AccessToken)) {
+		t.Error("session is still active after logout")
+	}
+	if _, err := auth.RefreshToken(device.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refresh after logout: got %v, want ErrInvalidRefreshToken", err)
+	}
+	if !auth.IsSessionActive(sessionOf(t, auth, other.AccessToken)) {
+		t.Error("logout signed the other device out")
+	}
+	if err := auth.Logout("not-a-token"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Logout with garbage: got %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestLogoutAll(t *testing.T) {
+	auth, _ := newSessionTestAuth(t)
+	devices := []*AuthResponse{signIn(t, auth), signIn(t, auth)}
+
+	if err := auth.LogoutAll("user-1"); err != nil {
+		t.Fatalf("LogoutAll: %v", err)
+	}
+	for i, device := range devices {
+		if auth.IsSessionActive(sessionOf(t, auth, device.AccessToken)) {
+			t.Errorf("device %d is still signed in", i+1)
+		}
+		if _, err := auth.RefreshToken(device.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("device %d refresh: got %v, want ErrInvalidRefreshToken", i+1, err)
+		}
+	}
+}
@@ -0,0 +1,561 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+var (
+	ErrBreakoutNotFound    = errors.New("breakout room not found")
+	ErrBreakoutsOpen       = errors.New("this meeting already has open breakout rooms")
+	ErrNoBreakouts         = errors.New("this meeting has no open breakout rooms")
+	ErrBreakoutNotAssigned = errors.New("you are not assigned to this breakout room")
+)
+
+// Breakout limits
+const (
+	maxBreakoutRooms    = 50
+	maxBreakoutDuration = 24 * time.Hour
+)
+
+// BreakoutService splits a live meeting into breakout rooms. Breakout rooms
+// are private child rooms of the meeting; hosts and co-hosts assign
+// participants to them and can visit any of them. Closing the breakouts,
+// by hand or when their timer runs out, sends everyone back to the meeting.
+type BreakoutService interface {
+	Create(roomID, userID string, req CreateBreakoutsRequest) (*BreakoutOverview, error)
+	Get(roomID, userID string) (*BreakoutOverview, error)
+	Assign(roomID, userID string, req AssignBreakoutsRequest) (*BreakoutOverview, error)
+	Join(roomID, breakoutID, userID string) (*BreakoutJoinResponse, error)
+	Close(roomID, userID string) error
+	Start()
+}
+
+type breakoutService struct {
+	breakoutRepo   repository.BreakoutRepository
+	roomRepo       repository.RoomRepository
+	userRepo       repository.UserRepository
+	liveKitService LiveKitService
+	cfg            *config.Config
+	broadcaster    Broadcaster
+
+	mu     sync.Mutex
+	timers map[string]*time.Timer // Close timers by meeting
+}
+
+func NewBreakoutService(breakoutRepo repository.BreakoutRepository, roomRepo repository.RoomRepository, userRepo repository.UserRepository, liveKitService LiveKitService, cfg *config.Config, broadcaster Broadcaster) BreakoutService {
+	return &breakoutService{
+		breakoutRepo:   breakoutRepo,
+		roomRepo:       roomRepo,
+		userRepo:       userRepo,
+		liveKitService: liveKitService,
+		cfg:            cfg,
+		broadcaster:    broadcaster,
+		timers:         make(map[string]*time.Timer),
+	}
+}
+
+type CreateBreakoutsRequest struct {
+	Count           int      `json:"count"`            // Number of rooms, used when no names are given
+	Names           []string `json:"names"`            // One room per name
+	DurationMinutes int      `json:"duration_minutes"` // Close automatically after this long; 0 keeps them open until closed
+}
+
+type BreakoutAssignmentInput struct {
+	UserID         string `json:"user_id"`
+	BreakoutRoomID string `json:"breakout_room_id"` // Empty sends the user back to the meeting
+}
+
+// AssignBreakoutsRequest assigns the listed users (manual, the default), or
+// spreads all connected participants evenly over the rooms (random).
+// Random assignment replaces any earlier assignments.
+type AssignBreakoutsRequest struct {
+	Mode        string                    `json:"mode"` // manual or random
+	Assignments []BreakoutAssignmentInput `json:"assignments"`
+}
+
+type BreakoutMember struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+type BreakoutRoomResponse struct {
+	ID               string           `json:"id"`
+	Name             string           `json:"name"`
+	Status           string           `json:"status"`
+	ParticipantCount int64            `json:"participant_count"` // Users currently in the breakout room
+	Assigned         []BreakoutMember `json:"assigned"`
+}
+
+type BreakoutOverview struct {
+	ParentRoomID string                 `json:"parent_room_id"`
+	Rooms        []BreakoutRoomResponse `json:"rooms"`
+	EndsAt       *time.Time             `json:"ends_at,omitempty"`
+}
+
+type BreakoutJoinResponse struct {
+	Token        string     `json:"token"`
+	URL          string     `json:"url"`
+	Role         string     `json:"role"`
+	RoomID       string     `json:"room_id"`
+	Name         string     `json:"name"`
+	ParentRoomID string     `json:"parent_room_id"`
+	EndsAt       *time.Time `json:"ends_at,omitempty"`
+}
+
+// Create opens breakout rooms for a live meeting. Only one set of breakout
+// rooms can be open per meeting.
+func (s *breakoutService) Create(roomID, userID string, req CreateBreakoutsRequest) (*BreakoutOverview, error) {
+	parent, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost)
+	if err != nil {
+		return nil, err
+	}
+	if parent.IsBreakout() {
+		return nil, errors.New("breakout rooms cannot have breakout rooms of their own")
+	}
+	if parent.Status != model.RoomLive {
+		return nil, ErrRoomNotLive
+	}
+
+	names, err := breakoutNames(req)
+	if err != nil {
+		return nil, err
+	}
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	if duration < 0 || duration > maxBreakoutDuration {
+		return nil, fmt.Errorf("duration_minutes must be between 0 and %d", int(maxBreakoutDuration/time.Minute))
+	}
+
+	open, err := s.breakoutRepo.FindOpenBreakouts(roomID)
+	if err != nil {
+		return nil, errors.New("failed to fetch breakout rooms")
+	}
+	if len(open) > 0 {
+		return nil, ErrBreakoutsOpen
+	}
+
+	// Breakout rooms belong to the meeting's creator so the host keeps
+	// control; being private, only assigned users get in
+	created := make([]model.Room, 0, len(names))
+	for _, name := range names {
+		room := model.Room{
+			Name:         name,
+			CreatedByID:  parent.CreatedByID,
+			Status:       model.RoomLive,
+			Visibility:   model.VisibilityPrivate,
+			ParentRoomID: &parent.ID,
+		}
+		if err := s.roomRepo.Create(&room); err != nil {
+			s.discard(created)
+			return nil, errors.New("failed to create breakout rooms")
+		}
+		created = append(created, room)
+	}
+
+	if duration > 0 {
+		// Stored to the second so the timer can recognise its own deadline
+		endsAt := time.Now().Add(duration).Truncate(time.Second)
+		if err := s.breakoutRepo.SetDeadline(roomID, &endsAt); err != nil {
+			s.discard(created)
+			return nil, errors.New("failed to set the breakout timer")
+		}
+		parent.BreakoutsEndAt = &endsAt
+		s.schedule(roomID, endsAt)
+	}
+
+	overview, err := s.overview(parent)
+	if err != nil {
+		return nil, err
+	}
+	broadcast(s.broadcaster, roomID, userID, "breakout_rooms_opened", overview)
+	return overview, nil
+}
+
+func (s *breakoutService) Get(roomID, userID string) (*BreakoutOverview, error) {
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return nil, err
+	}
+	parent, err := s.roomRepo.FindByID(roomID)
+	if err != nil {
+		return nil, ErrRoomNotFound
+	}
+	return s.overview(parent)
+}
+
+// Assign places users in breakout rooms and tells each of them where to go.
+// Hosts and co-hosts are left out of random assignment; they stay in the
+// meeting and can join any breakout room.
+func (s *breakoutService) Assign(roomID, userID string, req AssignBreakoutsRequest) (*BreakoutOverview, error) {
+	parent, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost)
+	if err != nil {
+		return nil, err
+	}
+
+	rooms, err := s.breakoutRepo.FindOpenBreakouts(roomID)
+	if err != nil {
+		return nil, errors.New("failed to fetch breakout rooms")
+	}
+	if len(rooms) == 0 {
+		return nil, ErrNoBreakouts
+	}
+	byID := make(map[string]*model.Room, len(rooms))
+	for i := range rooms {
+		byID[rooms[i].ID] = &rooms[i]
+	}
+
+	now := time.Now()
+	var assignments []model.BreakoutAssignment
+	var unassigned []string
+	switch req.Mode {
+	case "", "manual":
+		if len(req.Assignments) == 0 {
+			return nil, errors.New("assignments are required")
+		}
+		seen := make(map[string]bool, len(req.Assignments))
+		for _, a := range req.Assignments {
+			if a.UserID == "" {
+				return nil, errors.New("user_id is required for every assignment")
+			}
+			if seen[a.UserID] {
+				return nil, fmt.Errorf("user %s is assigned more than once", a.UserID)
+			}
+			seen[a.UserID] = true
+
+			if roomRole(s.roomRepo, parent, a.UserID) == "" {
+				return nil, ErrParticipantNotFound
+			}
+			if a.BreakoutRoomID == "" {
+				unassigned = append(unassigned, a.UserID)
+				continue
+			}
+			if byID[a.BreakoutRoomID] == nil {
+				return nil, ErrBreakoutNotFound
+			}
+			assignments = append(assignments, model.BreakoutAssignment{
+				ParentRoomID: roomID,
+				UserID:       a.UserID,
+				RoomID:       a.BreakoutRoomID,
+				AssignedByID: userID,
+				AssignedAt:   now,
+			})
+		}
+	case "random":
+		participants, err := s.roomRepo.FindParticipants(roomID)
+		if err != nil {
+			return nil, errors.New("failed to fetch participants")
+		}
+		var userIDs []string
+		for _, p := range participants {
+			if !p.IsActive || p.UserID == parent.CreatedByID || p.Role == model.RoleHost || p.Role == model.RoleCoHost {
+				continue
+			}
+			userIDs = append(userIDs, p.UserID)
+		}
+		if len(userIDs) == 0 {
+			return nil, errors.New("there are no participants to assign")
+		}
+
+		rand.Shuffle(len(userIDs), func(i, j int) { userIDs[i], userIDs[j] = userIDs[j], userIDs[i] })
+		for i, id := range userIDs {
+			assignments = append(assignments, model.BreakoutAssignment{
+				ParentRoomID: roomID,
+				UserID:       id,
+				RoomID:       rooms[i%len(rooms)].ID,
+				AssignedByID: userID,
+				AssignedAt:   now,
+			})
+		}
+		if err := s.breakoutRepo.ClearAssignments(roomID); err != nil {
+			return nil, errors.New("failed to assign participants")
+		}
+	default:
+		return nil, errors.New("mode must be manual or random")
+	}
+
+	if err := s.breakoutRepo.Assign(assignments); err != nil {
+		return nil, errors.New("failed to assign participants")
+	}
+	if err := s.breakoutRepo.Unassign(roomID, unassigned); err != nil {
+		return nil, errors.New("failed to assign participants")
+	}
+
+	for _, a := range assignments {
+		room := byID[a.RoomID]
+		sendToUser(s.broadcaster, roomID, a.UserID, userID, "breakout_assigned", map[string]interface{}{
+			"parent_room_id":   roomID,
+			"breakout_room_id": room.ID,
+			"name":             room.Name,
+			"ends_at":          parent.BreakoutsEndAt,
+		})
+	}
+	for _, id := range unassigned {
+		sendToUser(s.broadcaster, roomID, id, userID, "breakout_return", map[string]interface{}{
+			"parent_room_id": roomID,
+		})
+	}
+
+	overview, err := s.overview(parent)
+	if err != nil {
+		return nil, err
+	}
+	broadcast(s.broadcaster, roomID, userID, "breakout_rooms_updated", overview)
+	return overview, nil
+}
+
+// Join issues a LiveKit token for a breakout room. Users can only join the
+// room they are assigned to; hosts and co-hosts can join any of them.
+func (s *breakoutService) Join(roomID, breakoutID, userID string) (*BreakoutJoinResponse, error) {
+	parent, err := s.roomRepo.FindByID(roomID)
+	if err != nil {
+		return nil, ErrRoomNotFound
+	}
+	role := roomRole(s.roomRepo, parent, userID)
+	if role == "" {
+		return nil, ErrRoomAccessDenied
+	}
+
+	breakout, err := s.roomRepo.FindByID(breakoutID)
+	if err != nil || breakout.ParentRoomID == nil || *breakout.ParentRoomID != roomID {
+		return nil, ErrBreakoutNotFound
+	}
+	if !breakout.IsOpen() {
+		return nil, ErrRoomClosed
+	}
+	if role != model.RoleHost && role != model.RoleCoHost {
+		assignment, err := s.breakoutRepo.FindAssignment(roomID, userID)
+		if err != nil || assignment.RoomID != breakoutID {
+			return nil, ErrBreakoutNotAssigned
+		}
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	// Users keep their meeting role in the breakout room
+	if err := s.roomRepo.AddParticipant(breakoutID, userID, role); err != nil {
+		return nil, errors.New("failed to join breakout room")
+	}
+	role = roomRole(s.roomRepo, breakout, userID)
+
+	token, err := joinToken(s.cfg, breakoutID, role, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BreakoutJoinResponse{
+		Token:        token,
+		URL:          s.cfg.LiveKitURL,
+		Role:         role,
+		RoomID:       breakout.ID,
+		Name:         breakout.Name,
+		ParentRoomID: roomID,
+		EndsAt:       parent.BreakoutsEndAt,
+	}, nil
+}
+
+// Close ends all open breakout rooms of the meeting and sends everyone back
+func (s *breakoutService) Close(roomID, userID string) error {
+	parent, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost)
+	if err != nil {
+		return err
+	}
+
+	rooms, err := s.breakoutRepo.FindOpenBreakouts(roomID)
+	if err != nil {
+		return errors.New("failed to fetch breakout rooms")
+	}
+	if len(rooms) == 0 {
+		return ErrNoBreakouts
+	}
+
+	return s.closeBreakouts(parent, rooms, userID)
+}
+
+// Start re-arms the timers of breakout rooms that were open when the server
+// stopped. Deadlines that passed in the meantime close right away.
+func (s *breakoutService) Start() {
+	rooms, err := s.breakoutRepo.FindWithDeadline()
+	if err != nil {
+		log.Printf("[Breakout] Failed to restore breakout timers: %v", err)
+		return
+	}
+	for i := range rooms {
+		s.schedule(rooms[i].ID, *rooms[i].BreakoutsEndAt)
+	}
+}
+
+// closeBreakouts tells everyone in the breakout rooms to return, disconnects
+// them and ends the rooms. A LiveKit failure doesn't stop the others from
+// closing.
+func (s *breakoutService) closeBreakouts(parent *model.Room, rooms []model.Room, userID string) error {
+	s.cancelTimer(parent.ID)
+
+	now := time.Now()
+	ids := make([]string, 0, len(rooms))
+	for i := range rooms {
+		room := &rooms[i]
+		ids = append(ids, room.ID)
+
+		broadcast(s.broadcaster, room.ID, userID, "breakout_return", map[string]interface{}{
+			"parent_room_id":   parent.ID,
+			"breakout_room_id": room.ID,
+		})
+		if err := closeMeeting(s.liveKitService, s.roomRepo, room.ID); err != nil {
+			log.Printf("[Breakout] Failed to disconnect breakout room %s: %v", room.ID, err)
+		}
+		if err := transitionRoom(s.roomRepo, s.broadcaster, room, model.RoomEnded, userID, now); err != nil {
+			log.Printf("[Breakout] Failed to end breakout room %s: %v", room.ID, err)
+		}
+	}
+
+	if err := s.breakoutRepo.ClearAssignments(parent.ID); err != nil {
+		return errors.New("failed to close breakout rooms")
+	}
+	if err := s.breakoutRepo.SetDeadline(parent.ID, nil); err != nil {
+		return errors.New("failed to close breakout rooms")
+	}
+	parent.BreakoutsEndAt = nil
+
+	broadcast(s.broadcaster, parent.ID, userID, "breakout_rooms_closed", map[string]interface{}{
+		"parent_room_id":    parent.ID,
+		"breakout_room_ids": ids,
+	})
+	return nil
+}
+
+// schedule closes the meeting's breakout rooms at endsAt, replacing any
+// earlier timer
+func (s *breakoutService) schedule(parentRoomID string, endsAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if timer, ok := s.timers[parentRoomID]; ok {
+		timer.Stop()
+	}
+	s.timers[parentRoomID] = time.AfterFunc(time.Until(endsAt), func() {
+		s.expire(parentRoomID, endsAt)
+	})
+}
+
+func (s *breakoutService) cancelTimer(parentRoomID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if timer, ok := s.timers[parentRoomID]; ok {
+		timer.Stop()
+		delete(s.timers, parentRoomID)
+	}
+}
+
+// expire closes the breakout rooms when their timer runs out, unless they
+// were closed or reopened with another deadline in the meantime
+func (s *breakoutService) expire(parentRoomID string, endsAt time.Time) {
+	parent, err := s.roomRepo.FindByID(parentRoomID)
+	if err != nil {
+		s.cancelTimer(parentRoomID)
+		return
+	}
+	if parent.BreakoutsEndAt == nil || !parent.BreakoutsEndAt.Equal(endsAt) {
+		return
+	}
+
+	rooms, err := s.breakoutRepo.FindOpenBreakouts(parentRoomID)
+	if err != nil {
+		log.Printf("[Breakout] Failed to fetch breakout rooms of %s: %v", parentRoomID, err)
+		return
+	}
+	if err := s.closeBreakouts(parent, rooms, ""); err != nil {
+		log.Printf("[Breakout] Failed to close breakout rooms of %s: %v", parentRoomID, err)
+	}
+}
+
+// discard ends breakout rooms left over from a failed Create
+func (s *breakoutService) discard(rooms []model.Room) {
+	for i := range rooms {
+		if err := transitionRoom(s.roomRepo, nil, &rooms[i], model.RoomEnded, "", time.Now()); err != nil {
+			log.Printf("[Breakout] Failed to end breakout room %s: %v", rooms[i].ID, err)
+		}
+	}
+}
+
+func (s *breakoutService) overview(parent *model.Room) (*BreakoutOverview, error) {
+	rooms, err := s.breakoutRepo.FindOpenBreakouts(parent.ID)
+	if err != nil {
+		return nil, errors.New("failed to fetch breakout rooms")
+	}
+	assignments, err := s.breakoutRepo.FindAssignments(parent.ID)
+	if err != nil {
+		return nil, errors.New("failed to fetch breakout assignments")
+	}
+
+	ids := make([]string, len(rooms))
+	for i := range rooms {
+		ids[i] = rooms[i].ID
+	}
+	counts, err := s.roomRepo.GetParticipantCounts(ids)
+	if err != nil {
+		return nil, errors.New("failed to fetch participant counts")
+	}
+
+	members := make(map[string][]BreakoutMember)
+	for _, a := range assignments {
+		members[a.RoomID] = append(members[a.RoomID], BreakoutMember{
+			UserID: a.UserID,
+			Name:   displayName(a.FullName, a.Username),
+		})
+	}
+
+	overview := &BreakoutOverview{
+		ParentRoomID: parent.ID,
+		Rooms:        make([]BreakoutRoomResponse, len(rooms)),
+		EndsAt:       parent.BreakoutsEndAt,
+	}
+	for i, room := range rooms {
+		assigned := members[room.ID]
+		if assigned == nil {
+			assigned = []BreakoutMember{}
+		}
+		overview.Rooms[i] = BreakoutRoomResponse{
+			ID:               room.ID,
+			Name:             room.Name,
+			Status:           room.Status,
+			ParticipantCount: counts[room.ID],
+			Assigned:         assigned,
+		}
+	}
+	return overview, nil
+}
+
+// breakoutNames returns the names of the rooms to create: the given names,
+// or numbered rooms when only a count is given
+func breakoutNames(req CreateBreakoutsRequest) ([]string, error) {
+	if len(req.Names) > maxBreakoutRooms || (len(req.Names) == 0 && (req.Count < 1 || req.Count > maxBreakoutRooms)) {
+		return nil, fmt.Errorf("between 1 and %d breakout rooms can be created", maxBreakoutRooms)
+	}
+
+	var names []string
+	if len(req.Names) > 0 {
+		for _, name := range req.Names {
+			name = strings.TrimSpace(name)
+			if name == "" || len(name) > 255 {
+				return nil, errors.New("breakout room names must be between 1 and 255 characters")
+			}
+			names = append(names, name)
+		}
+	} else {
+		for i := 1; i <= req.Count; i++ {
+			names = append(names, fmt.Sprintf("Breakout room %d", i))
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// breakoutRoomRepo adds the participant and status queries breakouts use
+type breakoutRoomRepo struct {
+	*fakeRoomRepo
+}
+
+func (r *breakoutRoomRepo) FindParticipants(roomID string) ([]repository.ParticipantDetail, error) {
+	var found []repository.ParticipantDetail
+	for _, p := range r.participants {
+		if p.RoomID == roomID {
+			found = append(found, repository.ParticipantDetail{RoomParticipant: *p})
+		}
+	}
+	return found, nil
+}
+
+func (r *breakoutRoomRepo) AddParticipant(roomID, userID, role string) error {
+	if _, ok := r.participants[roomID+"/"+userID]; !ok {
+		r.participants[roomID+"/"+userID] = &model.RoomParticipant{RoomID: roomID, UserID: userID, Role: role, IsActive: true}
+	}
+	return nil
+}
+
+func (r *breakoutRoomRepo) UpdateStatus(roomID, from, to string, at time.Time) (bool, error) {
+	room, ok := r.rooms[roomID]
+	if !ok || room.Status != from {
+		return false, nil
+	}
+	room.Status = to
+	room.StatusChangedAt = at
+	return true, nil
+}
+
+func (r *breakoutRoomRepo) DeactivateParticipants(roomID string, at time.Time) error {
+	return nil
+}
+
+func (r *breakoutRoomRepo) GetParticipantCounts(roomIDs []string) (map[string]int64, error) {
+	return map[string]int64{}, nil
+}
+
+// fakeBreakoutRepo keeps assignments in memory and reads breakout rooms and
+// deadlines from the room fake. Clearing a deadline is reported on cleared.
+type fakeBreakoutRepo struct {
+	repository.BreakoutRepository
+	rooms       *breakoutRoomRepo
+	assignments map[string]model.BreakoutAssignment // parentRoomID + "/" + userID
+	cleared     chan string
+}
+
+func (r *fakeBreakoutRepo) FindOpenBreakouts(parentRoomID string) ([]model.Room, error) {
+	var open []model.Room
+	for _, room := range r.rooms.rooms {
+		if room.ParentRoomID != nil && *room.ParentRoomID == parentRoomID && room.IsOpen() {
+			open = append(open, *room)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].ID < open[j].ID })
+	return open, nil
+}
+
+func (r *fakeBreakoutRepo) SetDeadline(parentRoomID string, endsAt *time.Time) error {
+	r.rooms.rooms[parentRoomID].BreakoutsEndAt = endsAt
+	if endsAt == nil && r.cleared != nil {
+		r.cleared <- parentRoomID
+	}
+	return nil
+}
+
+func (r *fakeBreakoutRepo) FindWithDeadline() ([]model.Room, error) {
+	var found []model.Room
+	for _, room := range r.rooms.rooms {
+		if room.BreakoutsEndAt != nil {
+			found = append(found, *room)
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeBreakoutRepo) Assign(assignments []model.BreakoutAssignment) error {
+	for _, a := range assignments {
+		r.assignments[a.ParentRoomID+"/"+a.UserID] = a
+	}
+	return nil
+}
+
+func (r *fakeBreakoutRepo) Unassign(parentRoomID string, userIDs []string) error {
+	for _, id := range userIDs {
+		delete(r.assignments, parentRoomID+"/"+id)
+	}
+	return nil
+}
+
+func (r *fakeBreakoutRepo) ClearAssignments(parentRoomID string) error {
+	for key, a := range r.assignments {
+		if a.ParentRoomID == parentRoomID {
+			delete(r.assignments, key)
+		}
+	}
+	return nil
+}
+
+func (r *fakeBreakoutRepo) FindAssignment(parentRoomID, userID string) (*model.BreakoutAssignment, error) {
+	a, ok := r.assignments[parentRoomID+"/"+userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &a, nil
+}
+
+func (r *fakeBreakoutRepo) FindAssignments(parentRoomID string) ([]repository.AssignmentDetail, error) {
+	var found []repository.AssignmentDetail
+	for _, a := range r.assignments {
+		if a.ParentRoomID == parentRoomID {
+			found = append(found, repository.AssignmentDetail{BreakoutAssignment: a})
+		}
+	}
+	return found, nil
+}
+
+// newTestBreakouts sets up a live meeting created by "host" with a co-host,
+// four participants, one who already left, and two open breakout rooms
+func newTestBreakouts() (*breakoutService, *breakoutRoomRepo, *fakeBreakoutRepo, *fakeLiveKit) {
+	rooms := &breakoutRoomRepo{fakeRoomRepo: newFakeRoomRepo()}
+	rooms.rooms["meeting"] = &model.Room{ID: "meeting", CreatedByID: "host", Status: model.RoomLive}
+	parentID := "meeting"
+	for _, id := range []string{"breakout-1", "breakout-2"} {
+		rooms.rooms[id] = &model.Room{ID: id, Name: id, CreatedByID: "host", Status: model.RoomLive, ParentRoomID: &parentID}
+	}
+
+	participants := map[string]string{
+		"host":   model.RoleHost,
+		"cohost": model.RoleCoHost,
+		"p1":     model.RoleParticipant,
+		"p2":     model.RoleParticipant,
+		"p3":     model.RoleParticipant,
+		"p4":     model.RoleParticipant,
+		"left":   model.RoleParticipant,
+	}
+	users := &fakeUserRepo{}
+	for id, role := range participants {
+		rooms.participants["meeting/"+id] = &model.RoomParticipant{RoomID: "meeting", UserID: id, Role: role, IsActive: id != "left"}
+		users.users = append(users.users, &model.User{ID: id, Email: id + "@example.com", FullName: id})
+	}
+
+	breakouts := &fakeBreakoutRepo{rooms: rooms, assignments: make(map[string]model.BreakoutAssignment)}
+	lk := &fakeLiveKit{}
+	cfg := &config.Config{LiveKitAPIKey: testLiveKitKey, LiveKitAPISecret: testLiveKitSecret}
+	svc := NewBreakoutService(breakouts, rooms, users, lk, cfg, nil).(*breakoutService)
+	return svc, rooms, breakouts, lk
+}
+
+func TestBreakoutRandomAssignmentSkipsHostsAndCoHosts(t *testing.T) {
+	svc, _, breakouts, _ := newTestBreakouts()
+	// Random assignment replaces earlier assignments
+	breakouts.assignments["meeting/left"] = model.BreakoutAssignment{ParentRoomID: "meeting", UserID: "left", RoomID: "breakout-1"}
+
+	if _, err := svc.Assign("meeting", "cohost", AssignBreakoutsRequest{Mode: "random"}); err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+
+	perRoom := make(map[string]int)
+	for _, id := range []string{"p1", "p2", "p3", "p4"} {
+		a, ok := breakouts.assignments["meeting/"+id]
+		if !ok {
+			t.Errorf("%s was not assigned", id)
+			continue
+		}
+		perRoom[a.RoomID]++
+	}
+	if perRoom["breakout-1"] != 2 || perRoom["breakout-2"] != 2 {
+		t.Errorf("assignments per room = %v, want 2 in each", perRoom)
+	}
+	for _, id := range []string{"host", "cohost", "left"} {
+		if a, ok := breakouts.assignments["meeting/"+id]; ok {
+			t.Errorf("%s was assigned to %s, want them left in the meeting", id, a.RoomID)
+		}
+	}
+
+	if _, err := svc.Assign("meeting", "p1", AssignBreakoutsRequest{Mode: "random"}); !errors.Is(err, ErrRoomPermissionDenied) {
+		t.Errorf("assignment by a participant: got %v, want ErrRoomPermissionDenied", err)
+	}
+}
+
+func TestBreakoutJoinRequiresAssignment(t *testing.T) {
+	svc, _, breakouts, _ := newTestBreakouts()
+	breakouts.assignments["meeting/p1"] = model.BreakoutAssignment{ParentRoomID: "meeting", UserID: "p1", RoomID: "breakout-1"}
+
+	tests := []struct {
+		name       string
+		userID     string
+		breakoutID string
+		wantErr    error
+	}{
+		{"assigned room", "p1", "breakout-1", nil},
+		{"another room", "p1", "breakout-2", ErrBreakoutNotAssigned},
+		{"unassigned participant", "p2", "breakout-1", ErrBreakoutNotAssigned},
+		{"co-host visiting", "cohost", "breakout-2", nil},
+		{"not in the meeting", "stranger", "breakout-1", ErrRoomAccessDenied},
+		{"the meeting itself", "p1", "meeting", ErrBreakoutNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := svc.Join("meeting", tt.breakoutID, tt.userID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Join: got %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (resp.Token == "" || resp.RoomID != tt.breakoutID) {
+				t.Errorf("Join = %+v, want a token for %s", resp, tt.breakoutID)
+			}
+		})
+	}
+}
+
+func TestBreakoutCloseSendsEveryoneBack(t *testing.T) {
+	svc, rooms, breakouts, lk := newTestBreakouts()
+	endsAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	rooms.rooms["meeting"].BreakoutsEndAt = &endsAt
+	svc.schedule("meeting", endsAt)
+	breakouts.assignments["meeting/p1"] = model.BreakoutAssignment{ParentRoomID: "meeting", UserID: "p1", RoomID: "breakout-1"}
+
+	if err := svc.Close("meeting", "p1"); !errors.Is(err, ErrRoomPermissionDenied) {
+		t.Errorf("close by a participant: got %v, want ErrRoomPermissionDenied", err)
+	}
+	if err := svc.Close("meeting", "host"); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	assertBreakoutsClosed(t, rooms, breakouts, lk)
+	if len(svc.timers) != 0 {
+		t.Errorf("%d close timers still armed", len(svc.timers))
+	}
+	if err := svc.Close("meeting", "host"); !errors.Is(err, ErrNoBreakouts) {
+		t.Errorf("second close: got %v, want ErrNoBreakouts", err)
+	}
+}
+
+func TestBreakoutTimerClosesBreakouts(t *testing.T) {
+	svc, rooms, breakouts, lk := newTestBreakouts()
+	breakouts.cleared = make(chan string, 1)
+	// The deadline passed while the server was down
+	endsAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	rooms.rooms["meeting"].BreakoutsEndAt = &endsAt
+	breakouts.assignments["meeting/p1"] = model.BreakoutAssignment{ParentRoomID: "meeting", UserID: "p1", RoomID: "breakout-1"}
+
+	svc.Start()
+	select {
+	case <-breakouts.cleared:
+	case <-time.After(2 * time.Second):
+		t.Fatal("breakout rooms were not closed after their deadline")
+	}
+	assertBreakoutsClosed(t, rooms, breakouts, lk)
+}
+
+func TestBreakoutTimerIgnoresReplacedDeadline(t *testing.T) {
+	svc, rooms, _, lk := newTestBreakouts()
+	endsAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	rooms.rooms["meeting"].BreakoutsEndAt = &endsAt
+
+	// A timer left over from breakouts that were closed and reopened
+	svc.expire("meeting", endsAt.Add(-30*time.Minute))
+
+	for _, id := range []string{"breakout-1", "breakout-2"} {
+		if status := rooms.rooms[id].Status; status != model.RoomLive {
+			t.Errorf("%s is %s, want it still live", id, status)
+		}
+	}
+	if len(lk.deleted) != 0 {
+		t.Errorf("deleted LiveKit rooms %v, want none", lk.deleted)
+	}
+}
+
+func assertBreakoutsClosed(t *testing.T, rooms *breakoutRoomRepo, breakouts *fakeBreakoutRepo, lk *fakeLiveKit) {
+	t.Helper()
+	for _, id := range []string{"breakout-1", "breakout-2"} {
+		if status := rooms.rooms[id].Status; status != model.RoomEnded {
+			t.Errorf("%s is %s, want ended", id, status)
+		}
+	}
+	sort.Strings(lk.deleted)
+	if len(lk.deleted) != 2 || lk.deleted[0] != "breakout-1" || lk.deleted[1] != "breakout-2" {
+		t.Errorf("deleted LiveKit rooms %v, want both breakout rooms", lk.deleted)
+	}
+	if len(breakouts.assignments) != 0 {
+		t.Errorf("assignments left after closing: %v", breakouts.assignments)
+	}
+	if endsAt := rooms.rooms["meeting"].BreakoutsEndAt; endsAt != nil {
+		t.Errorf("breakout deadline = %v after closing, want none", endsAt)
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import "yourapp/internal/websocket"
+
+// Broadcaster pushes real-time events to clients connected to a room.
+// *websocket.Hub satisfies this interface.
+type Broadcaster interface {
+	BroadcastMessage(roomID string, message *websocket.Message)
+	SendToUser(roomID, userID string, message *websocket.Message)
+}
+
+// broadcast sends an event to the room if a broadcaster is configured
+func broadcast(b Broadcaster, roomID, userID, eventType string, payload interface{}) {
+	if b == nil {
+		return
+	}
+	b.BroadcastMessage(roomID, &websocket.Message{
+		RoomID:  roomID,
+		UserID:  userID,
+		Type:    eventType,
+		Payload: payload,
+	})
+}
+
+// sendToUser sends an event only to targetUserID's connections in the room.
+// userID is the user who caused the event, as with broadcast.
+func sendToUser(b Broadcaster, roomID, targetUserID, userID, eventType string, payload interface{}) {
+	if b == nil {
+		return
+	}
+	b.SendToUser(roomID, targetUserID, &websocket.Message{
+		RoomID:  roomID,
+		UserID:  userID,
+		Type:    eventType,
+		Payload: payload,
+	})
+}
@@ -7,34 +7,47 @@ import (
 	"yourapp/internal/repository"
 )
 
+// The user AI answers are saved under. It is created on startup, cannot log
+// in, and its address is in a reserved domain nobody can register.
+const (
+	AIAgentUserID = "00000000-0000-4000-8000-0000000000a1"
+	AIAgentName   = "AI Agent"
+	AIAgentEmail  = "ai-agent@system.invalid"
+)
+
 type ChatService interface {
 	CreateMessage(roomID, userID, message string) (*ChatMessageResponse, error)
-	GetMessages(roomID string, limit, offset int) ([]ChatMessageResponse, error)
+	CreateAIMessage(roomID, message string, provenance *model.AIProvenance) (*ChatMessageResponse, error)
+	GetMessages(roomID, userID string, limit, offset int) ([]ChatMessageResponse, error)
 	GetMessageCount(roomID string) (int64, error)
+	EnsureCanPost(roomID, userID string) error
 }
 
 type chatService struct {
-	chatRepo repository.ChatRepository
-	roomRepo repository.RoomRepository
-	userRepo repository.UserRepository
+	chatRepo   repository.ChatRepository
+	roomRepo   repository.RoomRepository
+	userRepo   repository.UserRepository
+	ragService RAGService
 }
 
-func NewChatService(chatRepo repository.ChatRepository, roomRepo repository.RoomRepository, userRepo repository.UserRepository) ChatService {
+func NewChatService(chatRepo repository.ChatRepository, roomRepo repository.RoomRepository, userRepo repository.UserRepository, ragService RAGService) ChatService {
 	return &chatService{
-		chatRepo: chatRepo,
-		roomRepo: roomRepo,
-		userRepo: userRepo,
+		chatRepo:   chatRepo,
+		roomRepo:   roomRepo,
+		userRepo:   userRepo,
+		ragService: ragService,
 	}
 }
 
 type ChatMessageResponse struct {
-	ID        string    `json:"id"`
-	RoomID    string    `json:"room_id"`
-	UserID    string    `json:"user_id"`
-	UserName  string    `json:"user_name"`
-	UserEmail string    `json:"user_email"`
-	Message   string    `json:"message"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         string              `json:"id"`
+	RoomID     string              `json:"room_id"`
+	UserID     string              `json:"user_id"`
+	UserName   string              `json:"user_name"`
+	UserEmail  string              `json:"user_email"`
+	Message    string              `json:"message"`
+	Provenance *model.AIProvenance `json:"provenance,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
 }
 
 type CreateChatMessageRequest struct {
@@ -42,10 +55,31 @@ type CreateChatMessageRequest struct {
 }
 
 func (s *chatService) CreateMessage(roomID, userID, message string) (*ChatMessageResponse, error) {
-	// Verify room exists
-	_, err := s.roomRepo.FindByID(roomID)
-	if err != nil {
-		return nil, errors.New("room not found")
+	return s.createMessage(roomID, userID, message, nil)
+}
+
+// CreateAIMessage saves an AI answer together with how it was produced
+func (s *chatService) CreateAIMessage(roomID, message string, provenance *model.AIProvenance) (*ChatMessageResponse, error) {
+	return s.createMessage(roomID, AIAgentUserID, message, provenance)
+}
+
+// EnsureCanPost checks that the user is in the room and was not removed by a
+// moderator, so they may post into it or ask its AI
+func (s *chatService) EnsureCanPost(roomID, userID string) error {
+	if wasRemoved(s.roomRepo, roomID, userID) {
+		return ErrRemovedFromRoom
+	}
+	return ensureRoomAccess(s.roomRepo, roomID, userID)
+}
+
+func (s *chatService) createMessage(roomID, userID, message string, provenance *model.AIProvenance) (*ChatMessageResponse, error) {
+	// Verify room exists; people can only post into rooms they are in
+	if userID != AIAgentUserID {
+		if err := s.EnsureCanPost(roomID, userID); err != nil {
+			return nil, err
+		}
+	} else if _, err := s.roomRepo.FindByID(roomID); err != nil {
+		return nil, ErrRoomNotFound
 	}
 
 	// Verify user exists
@@ -61,9 +95,10 @@ func (s *chatService) CreateMessage(roomID, userID, message string) (*ChatMessag
 
 	// Create message
 	chatMessage := &model.ChatMessage{
-		RoomID:  roomID,
-		UserID:  userID,
-		Message: message,
+		RoomID:     roomID,
+		UserID:     userID,
+		Message:    message,
+		Provenance: provenance,
 	}
 
 	if err := s.chatRepo.Create(chatMessage); err != nil {
@@ -76,14 +111,22 @@ func (s *chatService) CreateMessage(roomID, userID, message string) (*ChatMessag
 		return nil, errors.New("failed to fetch created message")
 	}
 
+	// Index user messages for retrieval; AI answers are not indexed so the
+	// model does not end up citing its own output
+	if userID != AIAgentUserID {
+		author := displayName(user.FullName, user.Username)
+		indexAsync(s.ragService, "message "+createdMessage.ID, func(rag RAGService) error {
+			return rag.IndexMessage(roomID, createdMessage.ID, author, message)
+		})
+	}
+
 	return s.chatMessageToResponse(createdMessage, user), nil
 }
 
-func (s *chatService) GetMessages(roomID string, limit, offset int) ([]ChatMessageResponse, error) {
-	// Verify room exists
-	_, err := s.roomRepo.FindByID(roomID)
-	if err != nil {
-		return nil, errors.New("room not found")
+func (s *chatService) GetMessages(roomID, userID string, limit, offset int) ([]ChatMessageResponse, error) {
+	// Only people who have been in the room can read its chat
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return nil, err
 	}
 
 	// Get messages
@@ -101,13 +144,14 @@ func (s *chatService) GetMessages(roomID string, limit, offset int) ([]ChatMessa
 		}
 		
 		responses[i] = ChatMessageResponse{
-			ID:        msg.ID,
-			RoomID:    msg.RoomID,
-			UserID:    msg.UserID,
-			UserName:  userName,
-			UserEmail: msg.User.Email,
-			Message:   msg.Message,
-			CreatedAt: msg.CreatedAt,
+			ID:         msg.ID,
+			RoomID:     msg.RoomID,
+			UserID:     msg.UserID,
+			UserName:   userName,
+			UserEmail:  msg.User.Email,
+			Message:    msg.Message,
+			Provenance: msg.Provenance,
+			CreatedAt:  msg.CreatedAt,
 		}
 	}
 
@@ -125,13 +169,14 @@ func (s *chatService) chatMessageToResponse(msg *model.ChatMessage, user *model.
 	}
 
 	return &ChatMessageResponse{
-		ID:        msg.ID,
-		RoomID:    msg.RoomID,
-		UserID:    msg.UserID,
-		UserName:  userName,
-		UserEmail: user.Email,
-		Message:   msg.Message,
-		CreatedAt: msg.CreatedAt,
+		ID:         msg.ID,
+		RoomID:     msg.RoomID,
+		UserID:     msg.UserID,
+		UserName:   userName,
+		UserEmail:  user.Email,
+		Message:    msg.Message,
+		Provenance: msg.Provenance,
+		CreatedAt:  msg.CreatedAt,
 	}
 }
 
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+// fakeChatRepo keeps created messages in memory
+type fakeChatRepo struct {
+	repository.ChatRepository
+	messages []*model.ChatMessage
+}
+
+func (r *fakeChatRepo) Create(message *model.ChatMessage) error {
+	message.ID = "msg-1"
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *fakeChatRepo) FindByID(id string) (*model.ChatMessage, error) {
+	for _, message := range r.messages {
+		if message.ID == id {
+			return message, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func TestCreateMessageRequiresRoomAccess(t *testing.T) {
+	rooms := newFakeRoomRepo()
+	rooms.rooms["private"] = &model.Room{ID: "private", CreatedByID: "host", Visibility: model.VisibilityPrivate}
+	rooms.access["private/member"] = true
+	rooms.access["private/removed"] = true
+	moderator := "host"
+	rooms.participants["private/removed"] = &model.RoomParticipant{RemovedByID: &moderator}
+	users := &fakeUserRepo{users: []*model.User{
+		{ID: "member", FullName: "Member"},
+		{ID: "removed", FullName: "Removed"},
+		{ID: "stranger", FullName: "Stranger"},
+		{ID: AIAgentUserID, FullName: AIAgentName},
+	}}
+	chats := &fakeChatRepo{}
+	chat := NewChatService(chats, rooms, users, nil)
+
+	tests := []struct {
+		user string
+		want error
+	}{
+		{"stranger", ErrRoomAccessDenied},
+		{"removed", ErrRemovedFromRoom},
+		{"member", nil},
+	}
+	for _, tc := range tests {
+		if _, err := chat.CreateMessage("private", tc.user, "hello"); !errors.Is(err, tc.want) {
+			t.Errorf("CreateMessage by %s = %v, want %v", tc.user, err, tc.want)
+		}
+	}
+	if _, err := chat.CreateMessage("missing", "member", "hello"); !errors.Is(err, ErrRoomNotFound) {
+		t.Errorf("CreateMessage into a missing room = %v, want ErrRoomNotFound", err)
+	}
+	if _, err := chat.CreateAIMessage("private", "answer", nil); err != nil {
+		t.Errorf("CreateAIMessage: %v", err)
+	}
+	if len(chats.messages) != 2 {
+		t.Errorf("saved %d messages, want the member's and the AI answer", len(chats.messages))
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"yourapp/internal/config"
+	"yourapp/internal/util"
+)
+
+// emailPublisher queues templated emails on RabbitMQ for the EmailWorker and
+// falls back to sending directly when the broker is unavailable
+type emailPublisher struct {
+	mu           sync.Mutex
+	rabbitMQ     *util.RabbitMQClient
+	config       *config.Config
+	emailService EmailService
+}
+
+func newEmailPublisher(rabbitMQ *util.RabbitMQClient, cfg *config.Config, emailService EmailService) *emailPublisher {
+	return &emailPublisher{
+		rabbitMQ:     rabbitMQ,
+		config:       cfg,
+		emailService: emailService,
+	}
+}
+
+// publishMeetingEmail sends a meeting_invite or meeting_reminder email
+func (p *emailPublisher) publishMeetingEmail(emailType, to string, meeting MeetingEmail) error {
+	payload, err := json.Marshal(meeting)
+	if err != nil {
+		return err
+	}
+
+	if client := p.client(); client != nil {
+		err := client.PublishEmail(util.EmailMessage{
+			To:      to,
+			Subject: meeting.Title,
+			Type:    emailType,
+			Payload: payload,
+		})
+		if err == nil {
+			return nil
+		}
+		log.Printf("[EmailPublisher] Failed to queue %s email for %s, sending directly: %v", emailType, to, err)
+	}
+
+	if emailType == "meeting_invite" {
+		return p.emailService.SendMeetingInviteEmail(to, meeting)
+	}
+	return p.emailService.SendMeetingReminderEmail(to, meeting)
+}
+
+// client returns a live RabbitMQ client, reconnecting if needed
+func (p *emailPublisher) client() *util.RabbitMQClient {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.rabbitMQ != nil && p.rabbitMQ.GetChannel() != nil && !p.rabbitMQ.GetChannel().IsClosed() {
+		return p.rabbitMQ
+	}
+	if p.config == nil {
+		return nil
+	}
+
+	newRabbitMQ, err := util.NewRabbitMQClient(p.config)
+	if err != nil {
+		log.Printf("[EmailPublisher] Failed to reconnect RabbitMQ: %v", err)
+		return nil
+	}
+	p.rabbitMQ = newRabbitMQ
+	return p.rabbitMQ
+}
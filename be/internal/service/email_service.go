@@ -14,6 +14,9 @@ type EmailService interface {
 	SendResetPasswordEmail(to, resetLink string) error
 	SendVerificationEmail(to, token string) error
 	SendWelcomeEmail(to, name string) error
+	SendMeetingInviteEmail(to string, meeting MeetingEmail) error
+	SendMeetingReminderEmail(to string, meeting MeetingEmail) error
+	SendAccountLockedEmail(to string, lock AccountLockedEmail) error
 }
 
 type emailService struct {
@@ -76,6 +76,21 @@ func (w *EmailWorker) processEmailMessage(msg amqp.Delivery) error {
 		return w.emailService.SendVerificationEmail(emailMsg.To, emailMsg.Body)
 	case "welcome":
 		return w.emailService.SendWelcomeEmail(emailMsg.To, emailMsg.Subject) // Using Subject as name
+	case "meeting_invite", "meeting_reminder":
+		var meeting MeetingEmail
+		if err := json.Unmarshal(emailMsg.Payload, &meeting); err != nil {
+			return err
+		}
+		if emailMsg.Type == "meeting_invite" {
+			return w.emailService.SendMeetingInviteEmail(emailMsg.To, meeting)
+		}
+		return w.emailService.SendMeetingReminderEmail(emailMsg.To, meeting)
+	case "account_locked":
+		var lock AccountLockedEmail
+		if err := json.Unmarshal(emailMsg.Payload, &lock); err != nil {
+			return err
+		}
+		return w.emailService.SendAccountLockedEmail(emailMsg.To, lock)
 	default:
 		// Generic email
 		return w.emailService.SendOTPEmail(emailMsg.To, emailMsg.Body)
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// EmbeddingProvider turns text into vectors for semantic search
+type EmbeddingProvider interface {
+	// Name identifies the provider and model; vectors from different names are never compared
+	Name() string
+	Embed(texts []string) ([][]float32, error)
+}
+
+// NewEmbeddingProvider returns the provider selected by name ("kolosal" or "local")
+func NewEmbeddingProvider(name, modelName string, dimensions int, kolosalService KolosalService) EmbeddingProvider {
+	if name == "kolosal" {
+		return &kolosalEmbedder{kolosalService: kolosalService, model: modelName}
+	}
+	return NewLocalEmbedder(dimensions)
+}
+
+type kolosalEmbedder struct {
+	kolosalService KolosalService
+	model          string
+}
+
+func (e *kolosalEmbedder) Name() string {
+	return "kolosal/" + e.model
+}
+
+func (e *kolosalEmbedder) Embed(texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	response, err := e.kolosalService.Embeddings(&KolosalEmbeddingRequest{
+		Model: e.model,
+		Input: texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(response.Data))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range response.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, errors.New("embedding index out of range")
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
+
+// LocalEmbedder is a deterministic bag-of-words embedder based on feature
+// hashing. It needs no network access, so it is the default for development
+// and tests; quality is keyword-level rather than truly semantic.
+type LocalEmbedder struct {
+	dimensions int
+}
+
+func NewLocalEmbedder(dimensions int) *LocalEmbedder {
+	if dimensions <= 0 {
+		dimensions = 256
+	}
+	return &LocalEmbedder{dimensions: dimensions}
+}
+
+func (e *LocalEmbedder) Name() string {
+	return fmt.Sprintf("local/hash-%d", e.dimensions)
+}
+
+func (e *LocalEmbedder) Embed(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e *LocalEmbedder) embed(text string) []float32 {
+	vector := make([]float32, e.dimensions)
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for _, token := range tokens {
+		h := fnv.New32a()
+		h.Write([]byte(token))
+		sum := h.Sum32()
+		// Use one bit of the hash as the sign to reduce collision bias
+		sign := float32(1)
+		if sum&1 == 1 {
+			sign = -1
+		}
+		vector[int(sum>>1)%e.dimensions] += sign
+	}
+
+	normalize(vector)
+	return vector
+}
+
+// normalize scales the vector to unit length in place
+func normalize(vector []float32) {
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) / norm)
+	}
+}
+
+// cosineSimilarity returns the cosine similarity of two vectors (0 if they differ in size)
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
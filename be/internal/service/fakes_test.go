@@ -0,0 +1,79 @@
+package service
+
+import (
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// fakeRoomRepo serves rooms and room access from memory. Methods the tests
+// don't set up panic through the embedded nil interface.
+type fakeRoomRepo struct {
+	repository.RoomRepository
+	rooms        map[string]*model.Room
+	access       map[string]bool                   // roomID + "/" + userID
+	participants map[string]*model.RoomParticipant // roomID + "/" + userID
+}
+
+func newFakeRoomRepo() *fakeRoomRepo {
+	return &fakeRoomRepo{
+		rooms:        make(map[string]*model.Room),
+		access:       make(map[string]bool),
+		participants: make(map[string]*model.RoomParticipant),
+	}
+}
+
+func (r *fakeRoomRepo) FindByID(id string) (*model.Room, error) {
+	room, ok := r.rooms[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *room
+	return &copied, nil
+}
+
+// HasAccess mirrors the repository: removed participants lose access
+func (r *fakeRoomRepo) HasAccess(roomID, userID string) (bool, error) {
+	if participant, ok := r.participants[roomID+"/"+userID]; ok && participant.RemovedByID != nil {
+		return false, nil
+	}
+	return r.access[roomID+"/"+userID], nil
+}
+
+func (r *fakeRoomRepo) FindParticipant(roomID, userID string) (*model.RoomParticipant, error) {
+	participant, ok := r.participants[roomID+"/"+userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *participant
+	return &copied, nil
+}
+
+// fakeUserRepo looks users up by ID, username and email
+type fakeUserRepo struct {
+	repository.UserRepository
+	users []*model.User
+}
+
+func (r *fakeUserRepo) find(match func(*model.User) bool) (*model.User, error) {
+	for _, user := range r.users {
+		if match(user) {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) FindByID(id string) (*model.User, error) {
+	return r.find(func(u *model.User) bool { return u.ID == id })
+}
+
+func (r *fakeUserRepo) FindByUsername(username string) (*model.User, error) {
+	return r.find(func(u *model.User) bool { return u.Username != nil && *u.Username == username })
+}
+
+func (r *fakeUserRepo) FindByEmail(email string) (*model.User, error) {
+	return r.find(func(u *model.User) bool { return u.Email == email })
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+	"yourapp/internal/util"
+)
+
+const (
+	defaultInviteLinkTTL = 7 * 24 * time.Hour
+	maxInviteLinkTTL     = 90 * 24 * time.Hour
+)
+
+var ErrInviteLinkInvalid = errors.New("invite link is invalid, expired or used up")
+
+// InviteLinkService issues signed, shareable invite links. A valid link lets
+// its holder join a private or passcode-protected room once per use. A use is
+// only counted once the holder is actually let in, so waiting in the lobby or
+// a failed join doesn't use up the link.
+type InviteLinkService interface {
+	Create(roomID, userID string, req CreateInviteLinkRequest) (*InviteLinkResponse, error)
+	List(roomID, userID string) ([]InviteLinkResponse, error)
+	Revoke(roomID, linkID, userID string) error
+	Validate(roomID, token string) (string, error)
+	Redeem(roomID, linkID string) error
+	Release(linkID string)
+}
+
+type inviteLinkService struct {
+	inviteLinkRepo repository.InviteLinkRepository
+	roomRepo       repository.RoomRepository
+	cfg            *config.Config
+}
+
+func NewInviteLinkService(inviteLinkRepo repository.InviteLinkRepository, roomRepo repository.RoomRepository, cfg *config.Config) InviteLinkService {
+	return &inviteLinkService{
+		inviteLinkRepo: inviteLinkRepo,
+		roomRepo:       roomRepo,
+		cfg:            cfg,
+	}
+}
+
+type CreateInviteLinkRequest struct {
+	ExpiresInMinutes *int `json:"expires_in_minutes"` // Default 7 days, at most 90 days
+	MaxUses          *int `json:"max_uses"`           // Nil for unlimited
+}
+
+type InviteLinkResponse struct {
+	ID        string     `json:"id"`
+	Token     string     `json:"token"`
+	URL       string     `json:"url"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	MaxUses   *int       `json:"max_uses,omitempty"`
+	Uses      int        `json:"uses"`
+	Revoked   bool       `json:"revoked"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (s *inviteLinkService) Create(roomID, userID string, req CreateInviteLinkRequest) (*InviteLinkResponse, error) {
+	if _, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost); err != nil {
+		return nil, err
+	}
+
+	ttl := defaultInviteLinkTTL
+	if req.ExpiresInMinutes != nil {
+		if *req.ExpiresInMinutes <= 0 {
+			return nil, errors.New("expires_in_minutes must be positive")
+		}
+		ttl = time.Duration(*req.ExpiresInMinutes) * time.Minute
+	}
+	if ttl > maxInviteLinkTTL {
+		return nil, errors.New("invite links can be valid for at most 90 days")
+	}
+	if req.MaxUses != nil && *req.MaxUses <= 0 {
+		return nil, errors.New("max_uses must be positive")
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	link := &model.RoomInviteLink{
+		RoomID:      roomID,
+		CreatedByID: userID,
+		ExpiresAt:   &expiresAt,
+		MaxUses:     req.MaxUses,
+	}
+	if err := s.inviteLinkRepo.Create(link); err != nil {
+		return nil, errors.New("failed to create invite link")
+	}
+
+	response := s.linkToResponse(link)
+	return &response, nil
+}
+
+func (s *inviteLinkService) List(roomID, userID string) ([]InviteLinkResponse, error) {
+	if _, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost); err != nil {
+		return nil, err
+	}
+
+	links, err := s.inviteLinkRepo.FindByRoomID(roomID)
+	if err != nil {
+		return nil, errors.New("failed to fetch invite links")
+	}
+
+	responses := make([]InviteLinkResponse, len(links))
+	for i := range links {
+		responses[i] = s.linkToResponse(&links[i])
+	}
+	return responses, nil
+}
+
+func (s *inviteLinkService) Revoke(roomID, linkID, userID string) error {
+	if _, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost); err != nil {
+		return err
+	}
+
+	link, err := s.inviteLinkRepo.FindByID(linkID)
+	if err != nil || link.RoomID != roomID {
+		return errors.New("invite link not found")
+	}
+	if err := s.inviteLinkRepo.Revoke(linkID, time.Now()); err != nil {
+		return errors.New("failed to revoke invite link")
+	}
+	return nil
+}
+
+// Validate verifies the token and checks that the link can still be used,
+// without counting a use. Returns the link ID to pass to Redeem.
+func (s *inviteLinkService) Validate(roomID, token string) (string, error) {
+	linkID, err := util.ParseInviteToken(token, s.cfg.JWTSecret)
+	if err != nil {
+		return "", ErrInviteLinkInvalid
+	}
+
+	link, err := s.inviteLinkRepo.FindByID(linkID)
+	if err != nil || link.RoomID != roomID || link.RevokedAt != nil {
+		return "", ErrInviteLinkInvalid
+	}
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(time.Now()) {
+		return "", ErrInviteLinkInvalid
+	}
+	if link.MaxUses != nil && link.Uses >= *link.MaxUses {
+		return "", ErrInviteLinkInvalid
+	}
+	return linkID, nil
+}
+
+// Redeem uses up one use of a validated link
+func (s *inviteLinkService) Redeem(roomID, linkID string) error {
+	ok, err := s.inviteLinkRepo.Redeem(linkID, roomID, time.Now())
+	if err != nil {
+		return errors.New("failed to verify invite link")
+	}
+	if !ok {
+		return ErrInviteLinkInvalid
+	}
+	return nil
+}
+
+// Release gives back a use of the link after the join failed
+func (s *inviteLinkService) Release(linkID string) {
+	if err := s.inviteLinkRepo.Release(linkID); err != nil {
+		log.Printf("[InviteLink] Failed to release a use of link %s: %v", linkID, err)
+	}
+}
+
+func (s *inviteLinkService) linkToResponse(link *model.RoomInviteLink) InviteLinkResponse {
+	token := util.SignInviteToken(link.ID, s.cfg.JWTSecret)
+	return InviteLinkResponse{
+		ID:        link.ID,
+		Token:     token,
+		URL:       roomJoinURL(s.cfg, link.RoomID) + "?invite=" + url.QueryEscape(token),
+		ExpiresAt: link.ExpiresAt,
+		MaxUses:   link.MaxUses,
+		Uses:      link.Uses,
+		Revoked:   link.RevokedAt != nil,
+		CreatedAt: link.CreatedAt,
+	}
+}
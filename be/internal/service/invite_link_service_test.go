@@ -0,0 +1,87 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+	"yourapp/internal/util"
+
+	"gorm.io/gorm"
+)
+
+// fakeInviteLinkRepo keeps invite links in memory
+type fakeInviteLinkRepo struct {
+	repository.InviteLinkRepository
+	links map[string]*model.RoomInviteLink
+}
+
+func (r *fakeInviteLinkRepo) FindByID(id string) (*model.RoomInviteLink, error) {
+	link, ok := r.links[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *link
+	return &copied, nil
+}
+
+func (r *fakeInviteLinkRepo) Redeem(id, roomID string, at time.Time) (bool, error) {
+	link, ok := r.links[id]
+	if !ok || link.RoomID != roomID || (link.MaxUses != nil && link.Uses >= *link.MaxUses) {
+		return false, nil
+	}
+	link.Uses++
+	return true, nil
+}
+
+func (r *fakeInviteLinkRepo) Release(id string) error {
+	if link, ok := r.links[id]; ok && link.Uses > 0 {
+		link.Uses--
+	}
+	return nil
+}
+
+func TestInviteLinkValidateDoesNotCountUse(t *testing.T) {
+	cfg := &config.Config{JWTSecret: "secret"}
+	maxUses := 1
+	past := time.Now().Add(-time.Hour)
+	links := &fakeInviteLinkRepo{links: map[string]*model.RoomInviteLink{
+		"once":    {ID: "once", RoomID: "room-1", MaxUses: &maxUses},
+		"expired": {ID: "expired", RoomID: "room-1", ExpiresAt: &past},
+	}}
+	svc := NewInviteLinkService(links, nil, cfg)
+
+	for i := 0; i < 2; i++ {
+		linkID, err := svc.Validate("room-1", util.SignInviteToken("once", cfg.JWTSecret))
+		if err != nil || linkID != "once" {
+			t.Fatalf("Validate #%d = %q, %v; want the link to stay valid", i+1, linkID, err)
+		}
+	}
+	if links.links["once"].Uses != 0 {
+		t.Fatalf("Validate counted %d uses", links.links["once"].Uses)
+	}
+
+	if err := svc.Redeem("room-1", "once"); err != nil {
+		t.Fatalf("Redeem: %v", err)
+	}
+	if _, err := svc.Validate("room-1", util.SignInviteToken("once", cfg.JWTSecret)); !errors.Is(err, ErrInviteLinkInvalid) {
+		t.Errorf("Validate of a used up link: got %v, want ErrInviteLinkInvalid", err)
+	}
+	svc.Release("once")
+	if _, err := svc.Validate("room-1", util.SignInviteToken("once", cfg.JWTSecret)); err != nil {
+		t.Errorf("Validate after Release: %v", err)
+	}
+
+	invalid := []struct{ name, roomID, token string }{
+		{"expired", "room-1", util.SignInviteToken("expired", cfg.JWTSecret)},
+		{"other room", "room-2", util.SignInviteToken("once", cfg.JWTSecret)},
+		{"forged", "room-1", util.SignInviteToken("once", "other-secret")},
+	}
+	for _, tc := range invalid {
+		if _, err := svc.Validate(tc.roomID, tc.token); !errors.Is(err, ErrInviteLinkInvalid) {
+			t.Errorf("%s: got %v, want ErrInviteLinkInvalid", tc.name, err)
+		}
+	}
+}
@@ -42,6 +42,7 @@ type KolosalService interface {
 	OCR(request *KolosalOCRRequest) (*KolosalOCRResponse, error)
 	GetModels() (*ModelsResponse, error)
 	AgentGenerate(request *KolosalAgentRequest) (*KolosalAgentResponse, error)
+	Embeddings(request *KolosalEmbeddingRequest) (*KolosalEmbeddingResponse, error)
 }
 
 // CustomSchema represents custom extraction schema
@@ -79,6 +80,9 @@ type KolosalOCRResponse struct {
 	Details       map[string]interface{} `json:"details,omitempty"`
 }
 
+// DefaultChatModel is used when a request does not specify a model
+const DefaultChatModel = "meta-llama/llama-4-maverick-17b-128e-instruct"
+
 type kolosalService struct {
 	apiURL string
 	apiKey string
@@ -106,12 +110,44 @@ type KolosalChatRequest struct {
 	Temperature float64   `json:"temperature,omitempty"`
 	Stream      bool      `json:"stream,omitempty"`
 	Cache       *bool     `json:"cache,omitempty"` // Enable/disable response caching
+	// Function calling (OpenAI-compatible)
+	Tools      []ToolDefinition `json:"tools,omitempty"`
+	ToolChoice interface{}      `json:"tool_choice,omitempty"` // "auto", "none" or a specific function
 }
 
 // Message represents a chat message
 type Message struct {
-	Role    string `json:"role"` // "system", "user", "assistant"
-	Content string `json:"content"`
+	Role       string     `json:"role"` // "system", "user", "assistant", "tool"
+	Content    string     `json:"content"`
+	Name       string     `json:"name,omitempty"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // Set by the model when it wants to call tools
+	ToolCallID string     `json:"tool_call_id,omitempty"` // Set on "tool" messages answering a tool call
+}
+
+// ToolDefinition describes a function the model may call
+type ToolDefinition struct {
+	Type     string             `json:"type"` // always "function"
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition is the JSON schema description of a callable function
+type FunctionDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// ToolCall represents a function call requested by the model
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall holds the function name and its JSON-encoded arguments
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 // KolosalChatResponse represents the response from Kolosal API
@@ -502,3 +538,83 @@ func (s *kolosalService) AgentGenerate(request *KolosalAgentRequest) (*KolosalAg
 
 	return &response, nil
 }
+
+// KolosalEmbeddingRequest represents an embeddings request (OpenAI-compatible)
+type KolosalEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// KolosalEmbeddingResponse represents an embeddings response
+type KolosalEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Model string `json:"model"`
+	Usage Usage  `json:"usage"`
+}
+
+// Embeddings creates vector embeddings for the given inputs using Kolosal API
+func (s *kolosalService) Embeddings(request *KolosalEmbeddingRequest) (*KolosalEmbeddingResponse, error) {
+	// Validate API key
+	if s.apiKey == "" {
+		return nil, fmt.Errorf("KOLOSAL_API_KEY is not set. Please configure KOLOSAL_API_KEY environment variable")
+	}
+
+	// Validate API URL
+	if s.apiURL == "" {
+		return nil, fmt.Errorf("KOLOSAL_API_URL is not set. Please configure KOLOSAL_API_URL environment variable")
+	}
+
+	// Build embeddings URL
+	embeddingsURL := s.apiURL
+	if !strings.HasSuffix(embeddingsURL, "/") {
+		embeddingsURL += "/"
+	}
+	embeddingsURL += "v1/embeddings"
+
+	// Prepare request body
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequest("POST", embeddingsURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
+
+	// Make request
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Check status code
+	if resp.StatusCode != http.StatusOK {
+		errorMsg := string(body)
+		log.Printf("[KolosalService] Embeddings API Error - Status: %d, Response: %s", resp.StatusCode, errorMsg)
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, errorMsg)
+	}
+
+	// Parse response
+	var response KolosalEmbeddingResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &response, nil
+}
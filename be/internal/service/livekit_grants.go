@@ -0,0 +1,87 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/model"
+
+	"github.com/livekit/protocol/auth"
+)
+
+// joinToken mints a LiveKit token for a user with the permissions of their role
+func joinToken(cfg *config.Config, roomID, role string, user *model.User) (string, error) {
+	at := auth.NewAccessToken(cfg.LiveKitAPIKey, cfg.LiveKitAPISecret)
+	at.AddGrant(videoGrantForRole(roomID, role)).
+		SetIdentity(participantIdentity(user)).
+		SetName(user.FullName).
+		SetValidFor(liveKitTokenTTL(cfg))
+
+	token, err := at.ToJWT()
+	if err != nil {
+		return "", errors.New("failed to generate token")
+	}
+	return token, nil
+}
+
+// liveKitTokenTTL is the validity of issued LiveKit tokens (LIVEKIT_TOKEN_TTL)
+func liveKitTokenTTL(cfg *config.Config) time.Duration {
+	if cfg.LiveKitTokenTTL > 0 {
+		return cfg.LiveKitTokenTTL
+	}
+	return 24 * time.Hour
+}
+
+// videoGrantForRole builds the LiveKit permissions for a room role.
+// Hosts and co-hosts get RoomAdmin so they can moderate from the client;
+// viewers can only watch.
+func videoGrantForRole(roomID, role string) *auth.VideoGrant {
+	grant := &auth.VideoGrant{
+		RoomJoin: true,
+		Room:     roomID,
+	}
+
+	switch role {
+	case model.RoleHost, model.RoleCoHost:
+		grant.RoomAdmin = true
+		grant.SetCanPublish(true)
+		grant.SetCanSubscribe(true)
+		grant.SetCanPublishData(true)
+		grant.SetCanUpdateOwnMetadata(true)
+	case model.RoleViewer:
+		grant.SetCanPublish(false)
+		grant.SetCanSubscribe(true)
+		grant.SetCanPublishData(false)
+	default:
+		grant.SetCanPublish(true)
+		grant.SetCanSubscribe(true)
+		grant.SetCanPublishData(true)
+	}
+
+	return grant
+}
+
+// recorderGrant is used for recording/transcription bots: they are hidden from
+// other participants, flagged as recorders and cannot publish media
+func recorderGrant(roomID string) *auth.VideoGrant {
+	grant := &auth.VideoGrant{
+		RoomJoin:  true,
+		Room:      roomID,
+		RoomAdmin: true,
+		Hidden:    true,
+		Recorder:  true,
+	}
+	grant.SetCanPublish(false)
+	grant.SetCanSubscribe(true)
+	grant.SetCanPublishData(true)
+	return grant
+}
+
+// validRole reports whether role is one of the room roles
+func validRole(role string) bool {
+	switch role {
+	case model.RoleHost, model.RoleCoHost, model.RoleParticipant, model.RoleViewer:
+		return true
+	}
+	return false
+}
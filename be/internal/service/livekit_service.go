@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/twitchtv/twirp"
+)
+
+var (
+	ErrLiveKitNotConfigured = errors.New("LiveKit server API is not configured")
+	ErrLiveKitNotFound      = errors.New("not found on the LiveKit server")
+	ErrLiveKitRequestFailed = errors.New("LiveKit server request failed")
+)
+
+// LiveKitService is a small server SDK for the LiveKit RoomService and
+// Egress APIs. LiveKit room names are the room IDs of this app.
+type LiveKitService interface {
+	GetParticipant(roomName, identity string) (*livekit.ParticipantInfo, error)
+	ListParticipants(roomName string) ([]*livekit.ParticipantInfo, error)
+	MuteTrack(roomName, identity, trackSID string, muted bool) error
+	UpdateParticipant(roomName, identity string, permission *livekit.ParticipantPermission) error
+	RemoveParticipant(roomName, identity string) error
+	DeleteRoom(roomName string) error
+	StartRoomRecording(roomName, filepath string) (*livekit.EgressInfo, error)
+	StopEgress(egressID string) (*livekit.EgressInfo, error)
+}
+
+type liveKitService struct {
+	apiKey    string
+	apiSecret string
+	rooms     livekit.RoomService
+	egress    livekit.Egress
+}
+
+// NewLiveKitService creates RoomService and Egress clients. apiURL is the
+// HTTP(S) base URL of the LiveKit server; ws/wss URLs are converted.
+func NewLiveKitService(apiURL, apiKey, apiSecret string) LiveKitService {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	return &liveKitService{
+		apiKey:    apiKey,
+		apiSecret: apiSecret,
+		rooms:     livekit.NewRoomServiceJSONClient(LiveKitHTTPURL(apiURL), httpClient),
+		egress:    livekit.NewEgressJSONClient(LiveKitHTTPURL(apiURL), httpClient),
+	}
+}
+
+// LiveKitHTTPURL turns a ws:// or wss:// LiveKit URL into its http(s) equivalent
+func LiveKitHTTPURL(url string) string {
+	switch {
+	case strings.HasPrefix(url, "wss://"):
+		url = "https://" + strings.TrimPrefix(url, "wss://")
+	case strings.HasPrefix(url, "ws://"):
+		url = "http://" + strings.TrimPrefix(url, "ws://")
+	}
+	return strings.TrimSuffix(url, "/")
+}
+
+func (s *liveKitService) GetParticipant(roomName, identity string) (*livekit.ParticipantInfo, error) {
+	ctx, cancel, err := s.authContext(&auth.VideoGrant{RoomAdmin: true, Room: roomName})
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	participant, err := s.rooms.GetParticipant(ctx, &livekit.RoomParticipantIdentity{
+		Room:     roomName,
+		Identity: identity,
+	})
+	if err != nil {
+		return nil, liveKitError("get participant", err)
+	}
+	return participant, nil
+}
+
+func (s *liveKitService) ListParticipants(roomName string) ([]*livekit.ParticipantInfo, error) {
+	ctx, cancel, err := s.authContext(&auth.VideoGrant{RoomAdmin: true, Room: roomName})
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	response, err := s.rooms.ListParticipants(ctx, &livekit.ListParticipantsRequest{Room: roomName})
+	if err != nil {
+		return nil, liveKitError("list participants", err)
+	}
+	return response.Participants, nil
+}
+
+func (s *liveKitService) MuteTrack(roomName, identity, trackSID string, muted bool) error {
+	ctx, cancel, err := s.authContext(&auth.VideoGrant{RoomAdmin: true, Room: roomName})
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	_, err = s.rooms.MutePublishedTrack(ctx, &livekit.MuteRoomTrackRequest{
+		Room:     roomName,
+		Identity: identity,
+		TrackSid: trackSID,
+		Muted:    muted,
+	})
+	if err != nil {
+		return liveKitError("mute track", err)
+	}
+	return nil
+}
+
+// UpdateParticipant changes the permissions of a connected participant
+// without them having to rejoin
+func (s *liveKitService) UpdateParticipant(roomName, identity string, permission *livekit.ParticipantPermission) error {
+	ctx, cancel, err := s.authContext(&auth.VideoGrant{RoomAdmin: true, Room: roomName})
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	_, err = s.rooms.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
+		Room:       roomName,
+		Identity:   identity,
+		Permission: permission,
+	})
+	if err != nil {
+		return liveKitError("update participant", err)
+	}
+	return nil
+}
+
+func (s *liveKitService) RemoveParticipant(roomName, identity string) error {
+	ctx, cancel, err := s.authContext(&auth.VideoGrant{RoomAdmin: true, Room: roomName})
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	_, err = s.rooms.RemoveParticipant(ctx, &livekit.RoomParticipantIdentity{
+		Room:     roomName,
+		Identity: identity,
+	})
+	if err != nil {
+		return liveKitError("remove participant", err)
+	}
+	return nil
+}
+
+func (s *liveKitService) DeleteRoom(roomName string) error {
+	ctx, cancel, err := s.authContext(&auth.VideoGrant{RoomCreate: true})
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	_, err = s.rooms.DeleteRoom(ctx, &livekit.DeleteRoomRequest{Room: roomName})
+	if err != nil {
+		return liveKitError("delete room", err)
+	}
+	return nil
+}
+
+// StartRoomRecording starts a room composite egress that records the room's
+// default layout to an MP4 file. filepath is relative to the storage
+// configured on the egress service.
+func (s *liveKitService) StartRoomRecording(roomName, filepath string) (*livekit.EgressInfo, error) {
+	ctx, cancel, err := s.authContext(&auth.VideoGrant{RoomRecord: true})
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	info, err := s.egress.StartRoomCompositeEgress(ctx, &livekit.RoomCompositeEgressRequest{
+		RoomName: roomName,
+		Layout:   "grid",
+		FileOutputs: []*livekit.EncodedFileOutput{{
+			FileType: livekit.EncodedFileType_MP4,
+			Filepath: filepath,
+		}},
+	})
+	if err != nil {
+		return nil, liveKitError("start recording", err)
+	}
+	return info, nil
+}
+
+func (s *liveKitService) StopEgress(egressID string) (*livekit.EgressInfo, error) {
+	ctx, cancel, err := s.authContext(&auth.VideoGrant{RoomRecord: true})
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	info, err := s.egress.StopEgress(ctx, &livekit.StopEgressRequest{EgressId: egressID})
+	if err != nil {
+		return nil, liveKitError("stop recording", err)
+	}
+	return info, nil
+}
+
+// authContext signs a short-lived server token with the given grant and
+// attaches it to the request context
+func (s *liveKitService) authContext(grant *auth.VideoGrant) (context.Context, context.CancelFunc, error) {
+	if s.apiKey == "" || s.apiSecret == "" {
+		return nil, nil, ErrLiveKitNotConfigured
+	}
+
+	at := auth.NewAccessToken(s.apiKey, s.apiSecret)
+	at.AddGrant(grant).SetValidFor(time.Minute)
+	token, err := at.ToJWT()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sign LiveKit token: %w", err)
+	}
+
+	header := make(http.Header)
+	header.Set("Authorization", "Bearer "+token)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, err = twirp.WithHTTPRequestHeaders(ctx, header)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return ctx, cancel, nil
+}
+
+// liveKitError maps twirp errors to the package error values
+func liveKitError(action string, err error) error {
+	var twerr twirp.Error
+	if errors.As(err, &twerr) && twerr.Code() == twirp.NotFound {
+		return fmt.Errorf("%s: %w", action, ErrLiveKitNotFound)
+	}
+	return fmt.Errorf("%s: %w: %v", action, ErrLiveKitRequestFailed, err)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"yourapp/internal/model"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	testLiveKitKey    = "test-key"
+	testLiveKitSecret = "test-secret-that-is-long-enough-for-hs256"
+)
+
+// fakeRoomServer answers twirp JSON RoomService calls and records the last
+// request it received. GetParticipant returns participant when set.
+type fakeRoomServer struct {
+	t           *testing.T
+	method      string
+	calls       []string
+	body        []byte
+	grant       *auth.VideoGrant
+	notFound    bool
+	participant *livekit.ParticipantInfo
+}
+
+func (f *fakeRoomServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const prefix = "/twirp/livekit.RoomService/"
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, prefix) {
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+	f.method = strings.TrimPrefix(r.URL.Path, prefix)
+	f.calls = append(f.calls, f.method)
+	f.body, _ = io.ReadAll(r.Body)
+
+	verifier, err := auth.ParseAPIToken(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if err != nil {
+		f.t.Errorf("%s: invalid bearer token: %v", f.method, err)
+	} else if verifier.APIKey() != testLiveKitKey {
+		f.t.Errorf("%s: token signed for key %q", f.method, verifier.APIKey())
+	} else if claims, err := verifier.Verify(testLiveKitSecret); err != nil {
+		f.t.Errorf("%s: token signature: %v", f.method, err)
+	} else {
+		f.grant = claims.Video
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if f.notFound {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"code":"not_found","msg":"participant not found"}`)
+		return
+	}
+	if f.method == "GetParticipant" && f.participant != nil {
+		body, _ := protojson.Marshal(f.participant)
+		w.Write(body)
+		return
+	}
+	io.WriteString(w, "{}")
+}
+
+// decode unmarshals the last request body into msg
+func (f *fakeRoomServer) decode(msg proto.Message) {
+	f.t.Helper()
+	if err := protojson.Unmarshal(f.body, msg); err != nil {
+		f.t.Fatalf("%s: decoding request %s: %v", f.method, f.body, err)
+	}
+}
+
+func newTestLiveKitService(t *testing.T) (LiveKitService, *fakeRoomServer) {
+	t.Helper()
+	fake := &fakeRoomServer{t: t}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	return NewLiveKitService(srv.URL, testLiveKitKey, testLiveKitSecret), fake
+}
+
+// requireRoomAdmin checks the request was authorized as admin of roomName
+func requireRoomAdmin(t *testing.T, fake *fakeRoomServer, roomName string) {
+	t.Helper()
+	if fake.grant == nil || !fake.grant.RoomAdmin || fake.grant.Room != roomName {
+		t.Errorf("%s: grant = %+v, want room admin of %s", fake.method, fake.grant, roomName)
+	}
+}
+
+func TestLiveKitMuteTrack(t *testing.T) {
+	lk, fake := newTestLiveKitService(t)
+
+	if err := lk.MuteTrack("room-1", "user-1", "TR_audio", true); err != nil {
+		t.Fatalf("MuteTrack: %v", err)
+	}
+	if fake.method != "MutePublishedTrack" {
+		t.Fatalf("called %s, want MutePublishedTrack", fake.method)
+	}
+	var req livekit.MuteRoomTrackRequest
+	fake.decode(&req)
+	if req.Room != "room-1" || req.Identity != "user-1" || req.TrackSid != "TR_audio" || !req.Muted {
+		t.Errorf("request = %+v", &req)
+	}
+	requireRoomAdmin(t, fake, "room-1")
+}
+
+func TestLiveKitRemoveParticipant(t *testing.T) {
+	lk, fake := newTestLiveKitService(t)
+
+	if err := lk.RemoveParticipant("room-1", "user-1"); err != nil {
+		t.Fatalf("RemoveParticipant: %v", err)
+	}
+	if fake.method != "RemoveParticipant" {
+		t.Fatalf("called %s, want RemoveParticipant", fake.method)
+	}
+	var req livekit.RoomParticipantIdentity
+	fake.decode(&req)
+	if req.Room != "room-1" || req.Identity != "user-1" {
+		t.Errorf("request = %+v", &req)
+	}
+	requireRoomAdmin(t, fake, "room-1")
+}
+
+func TestLiveKitUpdateParticipant(t *testing.T) {
+	lk, fake := newTestLiveKitService(t)
+
+	permission := videoGrantForRole("room-1", model.RoleViewer).ToPermission()
+	if err := lk.UpdateParticipant("room-1", "user-1", permission); err != nil {
+		t.Fatalf("UpdateParticipant: %v", err)
+	}
+	if fake.method != "UpdateParticipant" {
+		t.Fatalf("called %s, want UpdateParticipant", fake.method)
+	}
+	var req livekit.UpdateParticipantRequest
+	fake.decode(&req)
+	if req.Room != "room-1" || req.Identity != "user-1" {
+		t.Errorf("request = %+v", &req)
+	}
+	if req.Permission == nil || req.Permission.CanPublish || !req.Permission.CanSubscribe {
+		t.Errorf("permission = %+v, want subscribe-only", req.Permission)
+	}
+	requireRoomAdmin(t, fake, "room-1")
+}
+
+func TestLiveKitErrors(t *testing.T) {
+	lk, fake := newTestLiveKitService(t)
+	fake.notFound = true
+
+	if err := lk.RemoveParticipant("room-1", "gone"); !errors.Is(err, ErrLiveKitNotFound) {
+		t.Errorf("RemoveParticipant of a missing participant: got %v, want ErrLiveKitNotFound", err)
+	}
+
+	unconfigured := NewLiveKitService("http://127.0.0.1:0", "", "")
+	if err := unconfigured.MuteTrack("room-1", "user-1", "TR_audio", true); !errors.Is(err, ErrLiveKitNotConfigured) {
+		t.Errorf("MuteTrack without credentials: got %v, want ErrLiveKitNotConfigured", err)
+	}
+}
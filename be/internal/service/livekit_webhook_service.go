@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+)
+
+// LiveKitWebhookService reconciles room_participants with what actually
+// happens on the LiveKit server. Events arrive already verified.
+type LiveKitWebhookService interface {
+	HandleEvent(event *livekit.WebhookEvent) error
+}
+
+type liveKitWebhookService struct {
+	roomRepo         repository.RoomRepository
+	userRepo         repository.UserRepository
+	recordingService RecordingService
+	liveKitService   LiveKitService
+	broadcaster      Broadcaster
+}
+
+func NewLiveKitWebhookService(roomRepo repository.RoomRepository, userRepo repository.UserRepository, recordingService RecordingService, liveKitService LiveKitService, broadcaster Broadcaster) LiveKitWebhookService {
+	return &liveKitWebhookService{
+		roomRepo:         roomRepo,
+		userRepo:         userRepo,
+		recordingService: recordingService,
+		liveKitService:   liveKitService,
+		broadcaster:      broadcaster,
+	}
+}
+
+func (s *liveKitWebhookService) HandleEvent(event *livekit.WebhookEvent) error {
+	if event.EgressInfo != nil {
+		// egress_started, egress_updated and egress_ended track recordings
+		return s.recordingService.HandleEgress(event.EgressInfo)
+	}
+	if event.Room == nil {
+		// Ingress events without room info are not tracked here
+		return nil
+	}
+
+	// LiveKit room names are our room IDs; ignore rooms we don't know about
+	roomID := event.Room.Name
+	room, err := s.roomRepo.FindByID(roomID)
+	if err != nil {
+		log.Printf("[LiveKitWebhook] Ignoring %s for unknown room %s", event.Event, roomID)
+		return nil
+	}
+
+	at := time.Now()
+	if event.CreatedAt > 0 {
+		at = time.Unix(event.CreatedAt, 0)
+	}
+
+	switch event.Event {
+	case webhook.EventRoomStarted:
+		broadcast(s.broadcaster, roomID, "", "room_started", map[string]interface{}{
+			"room_id": roomID,
+			"sid":     event.Room.Sid,
+		})
+
+	case webhook.EventRoomFinished:
+		if err := s.roomRepo.DeactivateParticipants(roomID, at); err != nil {
+			return errors.New("failed to close participant sessions")
+		}
+		// LiveKit closes rooms once everyone has left; end the meeting too
+		// instead of waiting for the janitor's idle grace period. Rooms
+		// that were not live (early joins before a scheduled start) keep
+		// their status.
+		if room.Status == model.RoomLive {
+			if err := transitionRoom(s.roomRepo, s.broadcaster, room, endedStatus(room, at), "", at); err != nil {
+				if !errors.Is(err, ErrInvalidRoomTransition) {
+					return err
+				}
+				log.Printf("[LiveKitWebhook] Room %s not ended: %v", roomID, err)
+			}
+		}
+		broadcast(s.broadcaster, roomID, "", "room_finished", map[string]interface{}{
+			"room_id": roomID,
+			"sid":     event.Room.Sid,
+		})
+
+	case webhook.EventParticipantJoined, webhook.EventParticipantLeft:
+		return s.handleParticipant(room, event, at)
+
+	case webhook.EventTrackPublished, webhook.EventTrackUnpublished:
+		if event.Participant == nil || event.Track == nil {
+			return nil
+		}
+		payload := map[string]interface{}{
+			"identity":  event.Participant.Identity,
+			"track_sid": event.Track.Sid,
+			"type":      event.Track.Type.String(),
+			"source":    event.Track.Source.String(),
+			"muted":     event.Track.Muted,
+		}
+		userID := ""
+		if user := userByIdentity(s.userRepo, event.Participant.Identity); user != nil {
+			userID = user.ID
+			payload["user_id"] = user.ID
+		}
+		broadcast(s.broadcaster, roomID, userID, event.Event, payload)
+	}
+
+	return nil
+}
+
+// handleParticipant opens or closes the media session of a participant
+func (s *liveKitWebhookService) handleParticipant(room *model.Room, event *livekit.WebhookEvent, at time.Time) error {
+	if event.Participant == nil {
+		return nil
+	}
+
+	identity := event.Participant.Identity
+	user := userByIdentity(s.userRepo, identity)
+	if user == nil {
+		// Recorders, agents and other bots have no user account
+		log.Printf("[LiveKitWebhook] %s for non-user identity %s in room %s", event.Event, identity, room.ID)
+		return nil
+	}
+
+	if event.Event == webhook.EventParticipantJoined {
+		// A removed participant whose LiveKit token is still valid can
+		// connect without going through join; send them out again
+		if room.CreatedByID != user.ID && wasRemoved(s.roomRepo, room.ID, user.ID) {
+			log.Printf("[LiveKitWebhook] Removing %s from room %s again: removed by a moderator", identity, room.ID)
+			if err := s.liveKitService.RemoveParticipant(room.ID, identity); err != nil && !errors.Is(err, ErrLiveKitNotFound) {
+				log.Printf("[LiveKitWebhook] Failed to remove %s from room %s: %v", identity, room.ID, err)
+			}
+			return nil
+		}
+
+		role := model.RoleParticipant
+		if room.CreatedByID == user.ID {
+			role = model.RoleHost
+		}
+		if err := s.roomRepo.StartParticipantSession(room.ID, user.ID, role, at); err != nil {
+			// A full room won't have space on a retry either; only
+			// storage failures are worth a redelivery
+			if errors.Is(err, repository.ErrRoomFull) {
+				log.Printf("[LiveKitWebhook] Not tracking %s in room %s: %v", identity, room.ID, err)
+				return nil
+			}
+			return errors.New("failed to start participant session")
+		}
+	} else {
+		if err := s.roomRepo.EndParticipantSession(room.ID, user.ID, at); err != nil {
+			return errors.New("failed to end participant session")
+		}
+	}
+
+	broadcast(s.broadcaster, room.ID, user.ID, event.Event, map[string]interface{}{
+		"user_id":  user.ID,
+		"identity": identity,
+		"name":     displayName(user.FullName, user.Username),
+		"at":       at,
+	})
+	return nil
+}
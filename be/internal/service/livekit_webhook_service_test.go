@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+)
+
+// sessionRoomRepo records participant sessions and fails them with startErr
+type sessionRoomRepo struct {
+	*fakeRoomRepo
+	startErr error
+	started  []string
+}
+
+func (r *sessionRoomRepo) StartParticipantSession(roomID, userID, role string, at time.Time) error {
+	if r.startErr != nil {
+		return r.startErr
+	}
+	r.started = append(r.started, userID)
+	return nil
+}
+
+// fakeLiveKit records the participants removed from LiveKit rooms and the
+// rooms deleted
+type fakeLiveKit struct {
+	LiveKitService
+	removed []string
+	deleted []string
+}
+
+func (f *fakeLiveKit) RemoveParticipant(roomName, identity string) error {
+	f.removed = append(f.removed, identity)
+	return nil
+}
+
+func (f *fakeLiveKit) DeleteRoom(roomName string) error {
+	f.deleted = append(f.deleted, roomName)
+	return nil
+}
+
+func newTestWebhookService() (LiveKitWebhookService, *sessionRoomRepo, *fakeLiveKit) {
+	rooms := &sessionRoomRepo{fakeRoomRepo: newFakeRoomRepo()}
+	rooms.rooms["room-1"] = &model.Room{ID: "room-1", CreatedByID: "host"}
+	username := "bob"
+	users := &fakeUserRepo{users: []*model.User{{ID: "bob-id", Username: &username, Email: "bob@example.com"}}}
+	lk := &fakeLiveKit{}
+	return NewLiveKitWebhookService(rooms, users, nil, lk, nil), rooms, lk
+}
+
+func joinedEvent(identity string) *livekit.WebhookEvent {
+	return &livekit.WebhookEvent{
+		Event:       webhook.EventParticipantJoined,
+		Room:        &livekit.Room{Name: "room-1"},
+		Participant: &livekit.ParticipantInfo{Identity: identity},
+		CreatedAt:   time.Now().Unix(),
+	}
+}
+
+func TestWebhookParticipantJoinedStartsSession(t *testing.T) {
+	svc, rooms, _ := newTestWebhookService()
+
+	if err := svc.HandleEvent(joinedEvent("bob")); err != nil {
+		t.Fatalf("HandleEvent: %v", err)
+	}
+	if len(rooms.started) != 1 || rooms.started[0] != "bob-id" {
+		t.Errorf("started sessions = %v, want [bob-id]", rooms.started)
+	}
+}
+
+func TestWebhookParticipantJoinedFullRoomIsHandled(t *testing.T) {
+	svc, rooms, _ := newTestWebhookService()
+	rooms.startErr = repository.ErrRoomFull
+
+	if err := svc.HandleEvent(joinedEvent("bob")); err != nil {
+		t.Errorf("full room: got %v, want the event acknowledged", err)
+	}
+
+	rooms.startErr = errors.New("connection refused")
+	if err := svc.HandleEvent(joinedEvent("bob")); err == nil {
+		t.Error("storage failure: got nil, want an error so LiveKit retries")
+	}
+}
+
+func TestWebhookParticipantJoinedRemovesRemovedParticipant(t *testing.T) {
+	svc, rooms, lk := newTestWebhookService()
+	moderator := "host"
+	rooms.participants["room-1/bob-id"] = &model.RoomParticipant{RoomID: "room-1", UserID: "bob-id", RemovedByID: &moderator}
+
+	if err := svc.HandleEvent(joinedEvent("bob")); err != nil {
+		t.Fatalf("HandleEvent: %v", err)
+	}
+	if len(lk.removed) != 1 || lk.removed[0] != "bob" {
+		t.Errorf("removed from LiveKit = %v, want [bob]", lk.removed)
+	}
+	if len(rooms.started) != 0 {
+		t.Errorf("started a session for a removed participant: %v", rooms.started)
+	}
+}
+
+// finishingRoomRepo applies status changes and session closes to the in-memory rooms
+type finishingRoomRepo struct {
+	*fakeRoomRepo
+	deactivated []string
+}
+
+func (r *finishingRoomRepo) DeactivateParticipants(roomID string, at time.Time) error {
+	r.deactivated = append(r.deactivated, roomID)
+	return nil
+}
+
+func (r *finishingRoomRepo) UpdateStatus(roomID, from, to string, at time.Time) (bool, error) {
+	room, ok := r.rooms[roomID]
+	if !ok || room.Status != from {
+		return false, nil
+	}
+	room.Status = to
+	room.StatusChangedAt = at
+	return true, nil
+}
+
+func TestWebhookRoomFinishedEndsMeeting(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		room   *model.Room
+		status string
+		want   string
+	}{
+		{"live room", &model.Room{}, model.RoomLive, model.RoomEnded},
+		{"recurring room with a later occurrence", scheduledRoom(now.Add(-time.Hour), 30*time.Minute, "UTC", "FREQ=DAILY"), model.RoomLive, model.RoomScheduled},
+		{"scheduled room joined early", scheduledRoom(now.Add(5*time.Minute), 30*time.Minute, "UTC", ""), model.RoomScheduled, model.RoomScheduled},
+		{"ended room", &model.Room{}, model.RoomEnded, model.RoomEnded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := &finishingRoomRepo{fakeRoomRepo: newFakeRoomRepo()}
+			tt.room.ID = "room-1"
+			tt.room.Status = tt.status
+			rooms.rooms["room-1"] = tt.room
+			svc := NewLiveKitWebhookService(rooms, &fakeUserRepo{}, nil, &fakeLiveKit{}, nil)
+
+			event := &livekit.WebhookEvent{
+				Event:     webhook.EventRoomFinished,
+				Room:      &livekit.Room{Name: "room-1"},
+				CreatedAt: now.Unix(),
+			}
+			if err := svc.HandleEvent(event); err != nil {
+				t.Fatalf("HandleEvent: %v", err)
+			}
+			if len(rooms.deactivated) != 1 {
+				t.Errorf("participant sessions closed %d times, want once", len(rooms.deactivated))
+			}
+			if got := rooms.rooms["room-1"].Status; got != tt.want {
+				t.Errorf("status = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
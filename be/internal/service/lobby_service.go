@@ -0,0 +1,335 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+var ErrLobbyDenied = errors.New("the host denied your request to join this meeting")
+
+// LobbyService implements the waiting room. When a room has the lobby
+// enabled, joiners other than hosts and co-hosts wait until a host or co-host
+// admits them; admitted users receive their LiveKit token over the room socket.
+type LobbyService interface {
+	Enter(room *model.Room, user *model.User, inviteLinkID string) (bool, error)
+	Leave(roomID, userID string) (bool, error)
+	IsWaiting(roomID, userID string) bool
+	SetEnabled(roomID, userID string, enabled bool) error
+	GetWaiting(roomID, userID string) ([]LobbyEntryResponse, error)
+	Admit(roomID, hostID string, req LobbyDecisionRequest) ([]LobbyEntryResponse, error)
+	Deny(roomID, hostID string, req LobbyDecisionRequest) ([]LobbyEntryResponse, error)
+}
+
+type lobbyService struct {
+	lobbyRepo         repository.LobbyRepository
+	roomRepo          repository.RoomRepository
+	userRepo          repository.UserRepository
+	inviteLinkService InviteLinkService
+	cfg               *config.Config
+	broadcaster       Broadcaster
+}
+
+func NewLobbyService(lobbyRepo repository.LobbyRepository, roomRepo repository.RoomRepository, userRepo repository.UserRepository, inviteLinkService InviteLinkService, cfg *config.Config, broadcaster Broadcaster) LobbyService {
+	return &lobbyService{
+		lobbyRepo:         lobbyRepo,
+		roomRepo:          roomRepo,
+		userRepo:          userRepo,
+		inviteLinkService: inviteLinkService,
+		cfg:               cfg,
+		broadcaster:       broadcaster,
+	}
+}
+
+type UpdateLobbyRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// LobbyDecisionRequest selects waiting users by ID, or everyone with All
+type LobbyDecisionRequest struct {
+	UserIDs []string `json:"user_ids"`
+	All     bool     `json:"all"`
+}
+
+type LobbyEntryResponse struct {
+	UserID      string     `json:"user_id"`
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	Status      string     `json:"status"`
+	RequestedAt time.Time  `json:"requested_at"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+}
+
+// Enter reports whether the user may join the room now. Otherwise the user is
+// put in the lobby and hosts are notified. Admission lasts for the room,
+// except for participants a moderator removed, who have to wait again.
+func (s *lobbyService) Enter(room *model.Room, user *model.User, inviteLinkID string) (bool, error) {
+	now := time.Now()
+	entry, err := s.lobbyRepo.Find(room.ID, user.ID)
+	if err != nil {
+		entry = &model.LobbyEntry{
+			RoomID:      room.ID,
+			UserID:      user.ID,
+			Status:      model.LobbyWaiting,
+			RequestedAt: now,
+		}
+		if inviteLinkID != "" {
+			entry.InviteLinkID = &inviteLinkID
+		}
+		if err := s.lobbyRepo.Create(entry); err != nil {
+			return false, errors.New("failed to enter the lobby")
+		}
+	} else {
+		switch entry.Status {
+		case model.LobbyDenied:
+			return false, ErrLobbyDenied
+		case model.LobbyAdmitted:
+			if !wasRemoved(s.roomRepo, room.ID, user.ID) {
+				return true, nil
+			}
+			if err := s.lobbyRepo.Requeue(room.ID, user.ID, now); err != nil {
+				return false, errors.New("failed to enter the lobby")
+			}
+			entry.RequestedAt = now
+		}
+		// Waiting users who call join again re-notify the hosts
+	}
+
+	entry.Status = model.LobbyWaiting
+	entry.User = *user
+	s.notifyModerators(room, user.ID, "lobby_request", lobbyEntryToResponse(entry))
+	return false, nil
+}
+
+// Leave withdraws a waiting user's request and reports whether the user was waiting
+func (s *lobbyService) Leave(roomID, userID string) (bool, error) {
+	entry, err := s.lobbyRepo.Find(roomID, userID)
+	if err != nil || entry.Status != model.LobbyWaiting {
+		return false, nil
+	}
+	if err := s.lobbyRepo.Delete(roomID, userID); err != nil {
+		return true, errors.New("failed to leave the lobby")
+	}
+
+	if room, err := s.roomRepo.FindByID(roomID); err == nil {
+		s.notifyModerators(room, userID, "lobby_left", map[string]interface{}{
+			"user_id": userID,
+		})
+	}
+	return true, nil
+}
+
+// IsWaiting reports whether the user is waiting for admission to the room
+func (s *lobbyService) IsWaiting(roomID, userID string) bool {
+	entry, err := s.lobbyRepo.Find(roomID, userID)
+	return err == nil && entry.Status == model.LobbyWaiting
+}
+
+func (s *lobbyService) SetEnabled(roomID, userID string, enabled bool) error {
+	if _, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost); err != nil {
+		return err
+	}
+
+	// Only the flag is written so concurrent status changes are kept
+	if err := s.roomRepo.SetLobbyEnabled(roomID, enabled); err != nil {
+		return errors.New("failed to update room")
+	}
+
+	// Waiting users can simply join again once the lobby is turned off
+	broadcast(s.broadcaster, roomID, userID, "lobby_updated", map[string]interface{}{
+		"room_id":       roomID,
+		"lobby_enabled": enabled,
+	})
+	return nil
+}
+
+func (s *lobbyService) GetWaiting(roomID, userID string) ([]LobbyEntryResponse, error) {
+	if _, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.lobbyRepo.FindByStatus(roomID, model.LobbyWaiting)
+	if err != nil {
+		return nil, errors.New("failed to fetch lobby")
+	}
+
+	responses := make([]LobbyEntryResponse, len(entries))
+	for i := range entries {
+		responses[i] = lobbyEntryToResponse(&entries[i])
+	}
+	return responses, nil
+}
+
+// Admit lets waiting users in, oldest request first, and sends each of them
+// a LiveKit token over the socket. Users beyond the room's capacity are left
+// waiting.
+func (s *lobbyService) Admit(roomID, hostID string, req LobbyDecisionRequest) ([]LobbyEntryResponse, error) {
+	room, entries, err := s.selectWaiting(roomID, hostID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Capacity is enforced per participant by AddParticipant
+	var admitted []model.LobbyEntry
+	full := false
+	for _, entry := range entries {
+		if err := s.roomRepo.AddParticipant(roomID, entry.UserID, model.RoleParticipant); err != nil {
+			if errors.Is(err, repository.ErrRoomFull) {
+				full = true
+				break
+			}
+			log.Printf("[Lobby] Failed to add admitted user %s to room %s: %v", entry.UserID, roomID, err)
+			continue
+		}
+		// Admitting a removed participant lets them back in
+		if err := s.roomRepo.ClearParticipantRemoved(roomID, entry.UserID); err != nil {
+			log.Printf("[Lobby] Failed to clear removal of %s in room %s: %v", entry.UserID, roomID, err)
+		}
+		// The host's decision stands even if the link was used up meanwhile
+		if entry.InviteLinkID != nil {
+			if err := s.inviteLinkService.Redeem(roomID, *entry.InviteLinkID); err != nil {
+				log.Printf("[Lobby] Invite link of %s in room %s not redeemed: %v", entry.UserID, roomID, err)
+			}
+		}
+		admitted = append(admitted, entry)
+	}
+	if len(admitted) == 0 {
+		if full {
+			return nil, ErrRoomFull
+		}
+		return nil, errors.New("failed to admit participants")
+	}
+
+	now := time.Now()
+	if err := s.lobbyRepo.Decide(roomID, lobbyUserIDs(admitted), model.LobbyAdmitted, hostID, now); err != nil {
+		return nil, errors.New("failed to admit participants")
+	}
+
+	responses := make([]LobbyEntryResponse, 0, len(admitted))
+	for i := range admitted {
+		entry := &admitted[i]
+		entry.Status = model.LobbyAdmitted
+		entry.DecidedAt = &now
+
+		role := roomRole(s.roomRepo, room, entry.UserID)
+		token, err := joinToken(s.cfg, roomID, role, &entry.User)
+		if err != nil {
+			log.Printf("[Lobby] Failed to issue token for %s in room %s: %v", entry.UserID, roomID, err)
+			continue
+		}
+
+		sendToUser(s.broadcaster, roomID, entry.UserID, hostID, "lobby_admitted", map[string]interface{}{
+			"status":  "joined",
+			"token":   token,
+			"url":     s.cfg.LiveKitURL,
+			"role":    role,
+			"room_id": roomID,
+		})
+		responses = append(responses, lobbyEntryToResponse(entry))
+	}
+
+	s.notifyModerators(room, hostID, "lobby_admitted", responses)
+	return responses, nil
+}
+
+func (s *lobbyService) Deny(roomID, hostID string, req LobbyDecisionRequest) ([]LobbyEntryResponse, error) {
+	room, entries, err := s.selectWaiting(roomID, hostID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.lobbyRepo.Decide(roomID, lobbyUserIDs(entries), model.LobbyDenied, hostID, now); err != nil {
+		return nil, errors.New("failed to deny participants")
+	}
+
+	responses := make([]LobbyEntryResponse, len(entries))
+	for i := range entries {
+		entries[i].Status = model.LobbyDenied
+		entries[i].DecidedAt = &now
+		responses[i] = lobbyEntryToResponse(&entries[i])
+
+		sendToUser(s.broadcaster, roomID, entries[i].UserID, hostID, "lobby_denied", map[string]interface{}{
+			"status":  model.LobbyDenied,
+			"room_id": roomID,
+		})
+	}
+
+	s.notifyModerators(room, hostID, "lobby_denied", responses)
+	return responses, nil
+}
+
+// selectWaiting checks the moderator's role and returns the waiting entries
+// the request refers to
+func (s *lobbyService) selectWaiting(roomID, hostID string, req LobbyDecisionRequest) (*model.Room, []model.LobbyEntry, error) {
+	room, err := ensureRoomRole(s.roomRepo, roomID, hostID, model.RoleHost, model.RoleCoHost)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !req.All && len(req.UserIDs) == 0 {
+		return nil, nil, errors.New("user_ids or all is required")
+	}
+
+	waiting, err := s.lobbyRepo.FindByStatus(roomID, model.LobbyWaiting)
+	if err != nil {
+		return nil, nil, errors.New("failed to fetch lobby")
+	}
+	if req.All {
+		if len(waiting) == 0 {
+			return nil, nil, errors.New("nobody is waiting in the lobby")
+		}
+		return room, waiting, nil
+	}
+
+	wanted := make(map[string]bool, len(req.UserIDs))
+	for _, id := range req.UserIDs {
+		wanted[id] = true
+	}
+	var entries []model.LobbyEntry
+	for _, entry := range waiting {
+		if wanted[entry.UserID] {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, nil, ErrParticipantNotFound
+	}
+	return room, entries, nil
+}
+
+// notifyModerators sends a lobby event to the host and co-hosts only
+func (s *lobbyService) notifyModerators(room *model.Room, userID, eventType string, payload interface{}) {
+	sendToUser(s.broadcaster, room.ID, room.CreatedByID, userID, eventType, payload)
+
+	participants, err := s.roomRepo.FindParticipants(room.ID)
+	if err != nil {
+		return
+	}
+	for _, p := range participants {
+		if p.Role == model.RoleCoHost && p.UserID != room.CreatedByID {
+			sendToUser(s.broadcaster, room.ID, p.UserID, userID, eventType, payload)
+		}
+	}
+}
+
+func lobbyUserIDs(entries []model.LobbyEntry) []string {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.UserID
+	}
+	return ids
+}
+
+func lobbyEntryToResponse(entry *model.LobbyEntry) LobbyEntryResponse {
+	return LobbyEntryResponse{
+		UserID:      entry.UserID,
+		Name:        displayName(entry.User.FullName, entry.User.Username),
+		Email:       entry.User.Email,
+		Status:      entry.Status,
+		RequestedAt: entry.RequestedAt,
+		DecidedAt:   entry.DecidedAt,
+	}
+}
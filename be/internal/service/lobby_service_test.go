@@ -0,0 +1,42 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"yourapp/internal/model"
+)
+
+// lobbyRoomRepo applies lobby toggles to the fake rooms. Whole-row saves
+// through Update would undo concurrent status changes and fail the test.
+type lobbyRoomRepo struct {
+	*fakeRoomRepo
+	t *testing.T
+}
+
+func (r lobbyRoomRepo) Update(room *model.Room) error {
+	r.t.Errorf("room %s saved as a whole", room.ID)
+	return nil
+}
+
+func (r lobbyRoomRepo) SetLobbyEnabled(roomID string, enabled bool) error {
+	r.rooms[roomID].LobbyEnabled = enabled
+	return nil
+}
+
+func TestLobbySetEnabledWritesOnlyTheFlag(t *testing.T) {
+	rooms := newFakeRoomRepo()
+	rooms.rooms["room-1"] = &model.Room{ID: "room-1", CreatedByID: "host", Status: model.RoomLive}
+	rooms.participants["room-1/alice"] = &model.RoomParticipant{RoomID: "room-1", UserID: "alice", Role: model.RoleParticipant}
+	lobby := NewLobbyService(nil, lobbyRoomRepo{rooms, t}, nil, nil, nil, nil)
+
+	if err := lobby.SetEnabled("room-1", "alice", true); !errors.Is(err, ErrRoomPermissionDenied) {
+		t.Errorf("participant enabling the lobby: got %v, want ErrRoomPermissionDenied", err)
+	}
+
+	if err := lobby.SetEnabled("room-1", "host", true); err != nil {
+		t.Fatalf("SetEnabled: %v", err)
+	}
+	if !rooms.rooms["room-1"].LobbyEnabled {
+		t.Error("lobby was not enabled")
+	}
+}
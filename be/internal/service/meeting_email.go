@@ -0,0 +1,207 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// MeetingEmail berisi data yang dibutuhkan untuk email undangan dan pengingat rapat.
+type MeetingEmail struct {
+	RoomID         string    `json:"room_id"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	HostName       string    `json:"host_name"`
+	HostEmail      string    `json:"host_email"`
+	JoinURL        string    `json:"join_url"`
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"`
+	Timezone       string    `json:"timezone"`
+	RecurrenceRule string    `json:"recurrence_rule,omitempty"`
+	ICS            string    `json:"ics,omitempty"`
+}
+
+var (
+	indonesianDays   = []string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"}
+	indonesianMonths = []string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+)
+
+// formatMeetingTime menampilkan waktu rapat dalam zona waktu rapat.
+func formatMeetingTime(meeting MeetingEmail) string {
+	loc := time.UTC
+	if meeting.Timezone != "" {
+		if l, err := time.LoadLocation(meeting.Timezone); err == nil {
+			loc = l
+		}
+	}
+	start := meeting.Start.In(loc)
+	end := meeting.End.In(loc)
+	return fmt.Sprintf("%s, %d %s %d %s - %s (%s)",
+		indonesianDays[start.Weekday()], start.Day(), indonesianMonths[start.Month()-1], start.Year(),
+		start.Format("15:04"), end.Format("15:04"), loc.String())
+}
+
+func (s *emailService) SendMeetingInviteEmail(to string, meeting MeetingEmail) error {
+	subject := "Undangan Rapat: " + meeting.Title
+	intro := fmt.Sprintf("%s mengundang Anda ke rapat berikut.", meeting.HostName)
+	if meeting.RecurrenceRule != "" {
+		intro = fmt.Sprintf("%s mengundang Anda ke rapat berulang berikut.", meeting.HostName)
+	}
+	htmlBody, textBody := s.renderMeetingEmail(meeting, "📅", "Undangan Rapat", intro)
+	return s.sendEmailWithCalendar(to, subject, htmlBody, textBody, meeting.ICS)
+}
+
+func (s *emailService) SendMeetingReminderEmail(to string, meeting MeetingEmail) error {
+	subject := "Pengingat Rapat: " + meeting.Title
+	minutes := int(time.Until(meeting.Start).Round(time.Minute).Minutes())
+	intro := "Rapat Anda akan segera dimulai."
+	if minutes > 0 {
+		intro = fmt.Sprintf("Rapat Anda akan dimulai dalam %d menit.", minutes)
+	}
+	htmlBody, textBody := s.renderMeetingEmail(meeting, "⏰", "Pengingat Rapat", intro)
+	return s.sendEmailHTML(to, subject, htmlBody, textBody)
+}
+
+// renderMeetingEmail membuat versi HTML dan plain text dari email rapat.
+func (s *emailService) renderMeetingEmail(meeting MeetingEmail, icon, heading, intro string) (string, string) {
+	when := formatMeetingTime(meeting)
+	description := ""
+	if meeting.Description != "" {
+		description = fmt.Sprintf(`
+                            <p style="margin: 0 0 24px; color: #4a5568; font-size: 15px; line-height: 1.6;">%s</p>`,
+			strings.ReplaceAll(html.EscapeString(meeting.Description), "\n", "<br>"))
+	}
+
+	htmlBody := fmt.Sprintf(`
+<div style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f5f7fa;">
+    <table role="presentation" cellpadding="0" cellspacing="0" border="0" width="100%%" style="background-color: #f5f7fa;">
+        <tr>
+            <td align="center" style="padding: 40px 20px;">
+                <table role="presentation" cellpadding="0" cellspacing="0" border="0" width="600" style="max-width: 600px; width: 100%%; background-color: #ffffff; border-radius: 12px; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.07);">
+                    <!-- Header -->
+                    <tr>
+                        <td align="center" style="padding: 40px 40px 30px; background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); border-radius: 12px 12px 0 0;">
+                            <table role="presentation" cellpadding="0" cellspacing="0" border="0">
+                                <tr>
+                                    <td align="center" style="width: 64px; height: 64px; background-color: #ffffff; border-radius: 50%%; font-size: 32px; line-height: 64px;">
+                                        %s
+                                    </td>
+                                </tr>
+                                <tr>
+                                    <td align="center" style="padding-top: 16px;">
+                                        <h1 style="margin: 0; color: #ffffff; font-size: 28px; font-weight: 700; letter-spacing: -0.5px;">%s</h1>
+                                    </td>
+                                </tr>
+                            </table>
+                        </td>
+                    </tr>
+
+                    <!-- Content -->
+                    <tr>
+                        <td style="padding: 40px;">
+                            <p style="margin: 0 0 24px; color: #2d3748; font-size: 16px; line-height: 1.6;">%s</p>
+                            <h2 style="margin: 0 0 8px; color: #1a202c; font-size: 22px; font-weight: 700;">%s</h2>
+                            <p style="margin: 0 0 24px; color: #4a5568; font-size: 15px; line-height: 1.6;">🕒 %s</p>%s
+
+                            <!-- Button -->
+                            <table role="presentation" cellpadding="0" cellspacing="0" border="0" width="100%%">
+                                <tr>
+                                    <td align="center" style="padding: 8px 0 32px;">
+                                        <a href="%s" style="display: inline-block; padding: 14px 40px; background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: #ffffff; text-decoration: none; border-radius: 8px; font-size: 16px; font-weight: 600;">Gabung Rapat</a>
+                                    </td>
+                                </tr>
+                            </table>
+
+                            <p style="margin: 0; color: #718096; font-size: 14px; line-height: 1.6;">
+                                Atau salin tautan berikut ke browser Anda:<br>
+                                <a href="%s" style="color: #667eea; word-break: break-all;">%s</a>
+                            </p>
+                        </td>
+                    </tr>
+
+                    <!-- Footer -->
+                    <tr>
+                        <td style="padding: 30px 40px; background-color: #f8fafc; border-radius: 0 0 12px 12px; border-top: 1px solid #e2e8f0;">
+                            <p style="margin: 0; color: #94a3b8; font-size: 12px; text-align: center; line-height: 1.5;">
+                                © %d %s. All rights reserved.<br>
+                                Email ini dikirim secara otomatis, mohon jangan membalas.
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</div>
+`, icon, heading, html.EscapeString(intro), html.EscapeString(meeting.Title), html.EscapeString(when), description,
+		meeting.JoinURL, meeting.JoinURL, meeting.JoinURL, time.Now().Year(), s.config.EmailName)
+
+	textBody := fmt.Sprintf(`
+%s
+
+%s
+Waktu: %s
+%s
+Gabung rapat: %s
+
+Tim %s
+`, intro, meeting.Title, when, meeting.Description, meeting.JoinURL, s.config.EmailName)
+
+	return htmlBody, textBody
+}
+
+// sendEmailWithCalendar mengirim email dengan undangan kalender (text/calendar)
+// agar klien email menampilkan tombol terima/tolak, serta lampiran invite.ics.
+func (s *emailService) sendEmailWithCalendar(to, subject, htmlBody, textBody, ics string) error {
+	if ics == "" {
+		return s.sendEmailHTML(to, subject, htmlBody, textBody)
+	}
+	if s.config.SMTPUsername == "" || s.config.SMTPPassword == "" {
+		// In development, just log the email
+		fmt.Printf("[EMAIL] To: %s, Subject: %s\nBody: %s\nCalendar:\n%s\n", to, subject, textBody, ics)
+		return nil
+	}
+
+	auth := smtp.PlainAuth("", s.config.SMTPUsername, s.config.SMTPPassword, s.config.SMTPHost)
+	from := s.config.EmailFrom
+	if from == "" {
+		from = s.config.SMTPUsername
+	}
+	fromHeader := from
+	if s.config.EmailName != "" {
+		fromHeader = fmt.Sprintf("%s <%s>", s.config.EmailName, from)
+	}
+
+	mixed := fmt.Sprintf("----=_Mixed_%d", time.Now().UnixNano())
+	alternative := fmt.Sprintf("----=_Alt_%d", time.Now().UnixNano())
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=\"%s\"\r\n\r\n",
+		fromHeader, to, subject, mixed)
+
+	// Body: plain text, HTML and the inline calendar request
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: multipart/alternative; boundary=\"%s\"\r\n\r\n", mixed, alternative)
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: text/plain; charset=UTF-8\r\nContent-Transfer-Encoding: 8bit\r\n\r\n%s\r\n", alternative, textBody)
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: text/html; charset=UTF-8\r\nContent-Transfer-Encoding: 8bit\r\n\r\n%s\r\n", alternative, htmlBody)
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: text/calendar; charset=UTF-8; method=REQUEST\r\nContent-Transfer-Encoding: 8bit\r\n\r\n%s\r\n", alternative, ics)
+	fmt.Fprintf(&b, "--%s--\r\n", alternative)
+
+	// Attachment for clients that ignore the inline calendar part
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: application/ics; name=\"invite.ics\"\r\nContent-Disposition: attachment; filename=\"invite.ics\"\r\nContent-Transfer-Encoding: base64\r\n\r\n", mixed)
+	encoded := base64.StdEncoding.EncodeToString([]byte(ics))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	fmt.Fprintf(&b, "--%s--\r\n", mixed)
+
+	addr := fmt.Sprintf("%s:%s", s.config.SMTPHost, s.config.SMTPPort)
+	if err := smtp.SendMail(addr, auth, from, []string{to}, []byte(b.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
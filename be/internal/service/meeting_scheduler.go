@@ -0,0 +1,50 @@
+package service
+
+import (
+	"log"
+	"time"
+)
+
+// MeetingScheduler periodically sends reminders for upcoming scheduled meetings
+type MeetingScheduler struct {
+	scheduleService ScheduleService
+	interval        time.Duration
+	stop            chan struct{}
+}
+
+func NewMeetingScheduler(scheduleService ScheduleService, interval time.Duration) *MeetingScheduler {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &MeetingScheduler{
+		scheduleService: scheduleService,
+		interval:        interval,
+		stop:            make(chan struct{}),
+	}
+}
+
+// Start runs the reminder loop in a goroutine until Stop is called
+func (m *MeetingScheduler) Start() {
+	log.Printf("Meeting scheduler started, checking every %v", m.interval)
+
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case now := <-ticker.C:
+				if sent := m.scheduleService.SendDueReminders(now); sent > 0 {
+					log.Printf("[Schedule] Sent %d meeting reminders", sent)
+				}
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the reminder loop
+func (m *MeetingScheduler) Stop() {
+	close(m.stop)
+}
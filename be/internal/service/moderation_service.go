@@ -0,0 +1,226 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+var ErrParticipantNotConnected = errors.New("participant is not connected to the meeting")
+
+// ModerationService lets hosts and co-hosts act on a live meeting through the
+// LiveKit server API. Actions are mirrored into room_participants and
+// broadcast to the room.
+type ModerationService interface {
+	MuteParticipant(roomID, moderatorID string, req MuteParticipantRequest) (*ModerationResponse, error)
+	RemoveParticipant(roomID, moderatorID string, req RemoveParticipantRequest) (*ModerationResponse, error)
+	EndMeeting(roomID, moderatorID string) error
+	IsModerator(roomID, userID string) bool
+	IsRemoved(roomID, userID string) bool
+}
+
+type moderationService struct {
+	roomRepo       repository.RoomRepository
+	userRepo       repository.UserRepository
+	liveKitService LiveKitService
+	broadcaster    Broadcaster
+}
+
+func NewModerationService(roomRepo repository.RoomRepository, userRepo repository.UserRepository, liveKitService LiveKitService, broadcaster Broadcaster) ModerationService {
+	return &moderationService{
+		roomRepo:       roomRepo,
+		userRepo:       userRepo,
+		liveKitService: liveKitService,
+		broadcaster:    broadcaster,
+	}
+}
+
+type MuteParticipantRequest struct {
+	UserID   string `json:"user_id" binding:"required"`
+	TrackSID string `json:"track_sid"` // Mute a single track; otherwise all tracks of Kind
+	Kind     string `json:"kind"`      // audio (default), video or all
+	Muted    *bool  `json:"muted"`     // Default true; unmuting requires remote unmute enabled on the LiveKit server
+}
+
+type RemoveParticipantRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+type ModerationResponse struct {
+	UserID      string   `json:"user_id"`
+	Identity    string   `json:"identity"`
+	Action      string   `json:"action"`
+	TrackSIDs   []string `json:"track_sids,omitempty"`
+	Muted       *bool    `json:"muted,omitempty"`
+	ModeratorID string   `json:"moderator_id"`
+}
+
+func (s *moderationService) MuteParticipant(roomID, moderatorID string, req MuteParticipantRequest) (*ModerationResponse, error) {
+	identity, err := s.authorizeTarget(roomID, moderatorID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := req.Kind
+	if kind == "" {
+		kind = "audio"
+	}
+	if kind != "audio" && kind != "video" && kind != "all" {
+		return nil, errors.New("kind must be audio, video or all")
+	}
+	muted := req.Muted == nil || *req.Muted
+
+	participant, err := s.liveKitService.GetParticipant(roomID, identity)
+	if err != nil {
+		if errors.Is(err, ErrLiveKitNotFound) {
+			return nil, ErrParticipantNotConnected
+		}
+		return nil, err
+	}
+
+	var trackSIDs []string
+	mutedAudio := false
+	for _, track := range participant.Tracks {
+		if req.TrackSID != "" {
+			if track.Sid != req.TrackSID {
+				continue
+			}
+		} else if !trackMatchesKind(track, kind) {
+			continue
+		}
+
+		if err := s.liveKitService.MuteTrack(roomID, identity, track.Sid, muted); err != nil {
+			return nil, err
+		}
+		trackSIDs = append(trackSIDs, track.Sid)
+		if track.Type == livekit.TrackType_AUDIO {
+			mutedAudio = true
+		}
+	}
+	if len(trackSIDs) == 0 {
+		return nil, errors.New("participant has no matching published tracks")
+	}
+
+	if mutedAudio {
+		if err := s.roomRepo.SetParticipantMuted(roomID, req.UserID, muted); err != nil {
+			log.Printf("[Moderation] Failed to record mute state for %s in room %s: %v", req.UserID, roomID, err)
+		}
+	}
+
+	response := &ModerationResponse{
+		UserID:      req.UserID,
+		Identity:    identity,
+		Action:      "mute",
+		TrackSIDs:   trackSIDs,
+		Muted:       &muted,
+		ModeratorID: moderatorID,
+	}
+	broadcast(s.broadcaster, roomID, moderatorID, "participant_muted", response)
+	return response, nil
+}
+
+func (s *moderationService) RemoveParticipant(roomID, moderatorID string, req RemoveParticipantRequest) (*ModerationResponse, error) {
+	identity, err := s.authorizeTarget(roomID, moderatorID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID == moderatorID {
+		return nil, errors.New("use leave to remove yourself from the meeting")
+	}
+
+	// A participant who already dropped out of LiveKit is still marked removed
+	if err := s.liveKitService.RemoveParticipant(roomID, identity); err != nil && !errors.Is(err, ErrLiveKitNotFound) {
+		return nil, err
+	}
+
+	if err := s.roomRepo.MarkParticipantRemoved(roomID, req.UserID, moderatorID); err != nil {
+		return nil, errors.New("failed to update participant state")
+	}
+
+	response := &ModerationResponse{
+		UserID:      req.UserID,
+		Identity:    identity,
+		Action:      "remove",
+		ModeratorID: moderatorID,
+	}
+	broadcast(s.broadcaster, roomID, moderatorID, "participant_removed", response)
+	return response, nil
+}
+
+// EndMeeting disconnects everyone by deleting the LiveKit room and ends the
+// room, or returns a recurring meeting to scheduled. Only the host can end
+// the meeting.
+func (s *moderationService) EndMeeting(roomID, moderatorID string) error {
+	room, err := ensureRoomRole(s.roomRepo, roomID, moderatorID, model.RoleHost)
+	if err != nil {
+		return err
+	}
+	if !room.IsOpen() {
+		return ErrRoomClosed
+	}
+
+	if err := closeMeeting(s.liveKitService, s.roomRepo, roomID); err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := transitionRoom(s.roomRepo, s.broadcaster, room, endedStatus(room, now), moderatorID, now); err != nil {
+		return err
+	}
+
+	broadcast(s.broadcaster, roomID, moderatorID, "meeting_ended", map[string]interface{}{
+		"room_id":  roomID,
+		"ended_by": moderatorID,
+	})
+	return nil
+}
+
+// IsModerator reports whether the user is a host or co-host of the room
+func (s *moderationService) IsModerator(roomID, userID string) bool {
+	_, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost)
+	return err == nil
+}
+
+// IsRemoved reports whether a moderator removed the user from the room
+func (s *moderationService) IsRemoved(roomID, userID string) bool {
+	return wasRemoved(s.roomRepo, roomID, userID)
+}
+
+// authorizeTarget checks that the moderator is a host or co-host and that the
+// target is a participant they may act on, and returns the target's LiveKit identity.
+// Co-hosts cannot act on the host.
+func (s *moderationService) authorizeTarget(roomID, moderatorID, targetUserID string) (string, error) {
+	room, err := ensureRoomRole(s.roomRepo, roomID, moderatorID, model.RoleHost, model.RoleCoHost)
+	if err != nil {
+		return "", err
+	}
+
+	if targetUserID == room.CreatedByID && moderatorID != room.CreatedByID {
+		return "", ErrRoomPermissionDenied
+	}
+	if targetUserID != room.CreatedByID {
+		if _, err := s.roomRepo.FindParticipant(roomID, targetUserID); err != nil {
+			return "", ErrParticipantNotFound
+		}
+	}
+
+	user, err := s.userRepo.FindByID(targetUserID)
+	if err != nil {
+		return "", ErrParticipantNotFound
+	}
+	return participantIdentity(user), nil
+}
+
+func trackMatchesKind(track *livekit.TrackInfo, kind string) bool {
+	switch kind {
+	case "audio":
+		return track.Type == livekit.TrackType_AUDIO
+	case "video":
+		return track.Type == livekit.TrackType_VIDEO
+	default:
+		return true
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"yourapp/internal/model"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// moderationRoomRepo records the mute and removal writes of the moderation service
+type moderationRoomRepo struct {
+	*fakeRoomRepo
+}
+
+func (r moderationRoomRepo) SetParticipantMuted(roomID, userID string, muted bool) error {
+	r.participants[roomID+"/"+userID].IsMuted = muted
+	return nil
+}
+
+func (r moderationRoomRepo) MarkParticipantRemoved(roomID, userID, removedByID string) error {
+	r.participants[roomID+"/"+userID].RemovedByID = &removedByID
+	return nil
+}
+
+func newTestModerationService(t *testing.T) (ModerationService, *fakeRoomRepo, *fakeRoomServer) {
+	t.Helper()
+	rooms := newFakeRoomRepo()
+	rooms.rooms["room-1"] = &model.Room{ID: "room-1", CreatedByID: "host", Status: model.RoomLive}
+	for _, p := range []struct{ id, role string }{
+		{"host", model.RoleHost},
+		{"cohost", model.RoleCoHost},
+		{"alice", model.RoleParticipant},
+	} {
+		rooms.participants["room-1/"+p.id] = &model.RoomParticipant{RoomID: "room-1", UserID: p.id, Role: p.role, IsActive: true}
+		rooms.access["room-1/"+p.id] = true
+	}
+	users := &fakeUserRepo{users: []*model.User{
+		{ID: "host", Email: "host@example.com"},
+		{ID: "cohost", Email: "cohost@example.com"},
+		{ID: "alice", Email: "alice@example.com"},
+	}}
+	lk, fake := newTestLiveKitService(t)
+	return NewModerationService(moderationRoomRepo{rooms}, users, lk, nil), rooms, fake
+}
+
+func TestModerationCoHostCannotActOnHost(t *testing.T) {
+	moderation, _, fake := newTestModerationService(t)
+
+	if _, err := moderation.MuteParticipant("room-1", "cohost", MuteParticipantRequest{UserID: "host"}); !errors.Is(err, ErrRoomPermissionDenied) {
+		t.Errorf("co-host muting the host: got %v, want ErrRoomPermissionDenied", err)
+	}
+	if _, err := moderation.RemoveParticipant("room-1", "cohost", RemoveParticipantRequest{UserID: "host"}); !errors.Is(err, ErrRoomPermissionDenied) {
+		t.Errorf("co-host removing the host: got %v, want ErrRoomPermissionDenied", err)
+	}
+	if _, err := moderation.RemoveParticipant("room-1", "alice", RemoveParticipantRequest{UserID: "cohost"}); !errors.Is(err, ErrRoomPermissionDenied) {
+		t.Errorf("participant removing a co-host: got %v, want ErrRoomPermissionDenied", err)
+	}
+	if len(fake.calls) != 0 {
+		t.Errorf("LiveKit called %v for refused actions", fake.calls)
+	}
+}
+
+func TestModerationCannotRemoveSelf(t *testing.T) {
+	moderation, rooms, fake := newTestModerationService(t)
+
+	if _, err := moderation.RemoveParticipant("room-1", "cohost", RemoveParticipantRequest{UserID: "cohost"}); err == nil {
+		t.Fatal("co-host removed themselves")
+	}
+	if len(fake.calls) != 0 {
+		t.Errorf("LiveKit called %v for a self removal", fake.calls)
+	}
+	if rooms.participants["room-1/cohost"].RemovedByID != nil {
+		t.Error("self removal was recorded")
+	}
+}
+
+func TestModerationMuteIsMirrored(t *testing.T) {
+	moderation, rooms, fake := newTestModerationService(t)
+	fake.participant = &livekit.ParticipantInfo{
+		Identity: "alice@example.com",
+		Tracks: []*livekit.TrackInfo{
+			{Sid: "TR_audio", Type: livekit.TrackType_AUDIO},
+			{Sid: "TR_video", Type: livekit.TrackType_VIDEO},
+		},
+	}
+
+	response, err := moderation.MuteParticipant("room-1", "cohost", MuteParticipantRequest{UserID: "alice"})
+	if err != nil {
+		t.Fatalf("MuteParticipant: %v", err)
+	}
+	if len(response.TrackSIDs) != 1 || response.TrackSIDs[0] != "TR_audio" {
+		t.Errorf("muted tracks %v, want only the audio track", response.TrackSIDs)
+	}
+	var req livekit.MuteRoomTrackRequest
+	fake.decode(&req)
+	if fake.method != "MutePublishedTrack" || req.Identity != "alice@example.com" || req.TrackSid != "TR_audio" || !req.Muted {
+		t.Errorf("last call %s %+v, want a mute of alice's audio", fake.method, &req)
+	}
+	if !rooms.participants["room-1/alice"].IsMuted {
+		t.Error("mute was not recorded on the participant")
+	}
+}
+
+func TestModerationRemoveIsMirrored(t *testing.T) {
+	moderation, rooms, fake := newTestModerationService(t)
+
+	if _, err := moderation.RemoveParticipant("room-1", "host", RemoveParticipantRequest{UserID: "cohost"}); err != nil {
+		t.Fatalf("RemoveParticipant: %v", err)
+	}
+	var req livekit.RoomParticipantIdentity
+	fake.decode(&req)
+	if fake.method != "RemoveParticipant" || req.Identity != "cohost@example.com" {
+		t.Errorf("last call %s %+v, want the co-host removed from LiveKit", fake.method, &req)
+	}
+	removedBy := rooms.participants["room-1/cohost"].RemovedByID
+	if removedBy == nil || *removedBy != "host" {
+		t.Fatalf("removed_by_id = %v, want host", removedBy)
+	}
+
+	// The removed co-host loses their moderator rights
+	fake.calls = nil
+	if _, err := moderation.RemoveParticipant("room-1", "cohost", RemoveParticipantRequest{UserID: "alice"}); !errors.Is(err, ErrRoomAccessDenied) {
+		t.Errorf("removed co-host moderating: got %v, want ErrRoomAccessDenied", err)
+	}
+	if moderation.IsModerator("room-1", "cohost") {
+		t.Error("removed co-host is still a moderator")
+	}
+	if len(fake.calls) != 0 {
+		t.Errorf("LiveKit called %v by a removed co-host", fake.calls)
+	}
+}
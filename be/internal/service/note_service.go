@@ -0,0 +1,540 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+var (
+	ErrNoteNotFound        = errors.New("note not found")
+	ErrNoteVersionConflict = repository.ErrNoteVersionConflict
+)
+
+type NoteService interface {
+	CreateNote(roomID, userID string, req CreateNoteRequest) (*NoteResponse, error)
+	GetNotes(roomID, userID string) ([]NoteResponse, error)
+	GetNote(roomID, noteID, userID string) (*NoteResponse, error)
+	UpdateNote(roomID, noteID, userID string, req UpdateNoteRequest) (*NoteResponse, error)
+	DeleteNote(roomID, noteID, userID string) error
+	GenerateNote(roomID, userID string, req GenerateNoteRequest) (*NoteResponse, error)
+}
+
+type noteService struct {
+	noteRepo          repository.NoteRepository
+	roomRepo          repository.RoomRepository
+	chatRepo          repository.ChatRepository
+	userRepo          repository.UserRepository
+	kolosalService    KolosalService
+	transcriptService TranscriptService
+	pollService       PollService
+	broadcaster       Broadcaster
+}
+
+func NewNoteService(noteRepo repository.NoteRepository, roomRepo repository.RoomRepository, chatRepo repository.ChatRepository, userRepo repository.UserRepository, kolosalService KolosalService, transcriptService TranscriptService, pollService PollService, broadcaster Broadcaster) NoteService {
+	return &noteService{
+		noteRepo:          noteRepo,
+		roomRepo:          roomRepo,
+		chatRepo:          chatRepo,
+		userRepo:          userRepo,
+		kolosalService:    kolosalService,
+		transcriptService: transcriptService,
+		pollService:       pollService,
+		broadcaster:       broadcaster,
+	}
+}
+
+type ActionItemRequest struct {
+	Description  string  `json:"description" binding:"required"`
+	AssigneeID   *string `json:"assignee_id"`
+	AssigneeName *string `json:"assignee_name"`
+	DueDate      *string `json:"due_date"` // YYYY-MM-DD
+	Completed    bool    `json:"completed"`
+}
+
+type CreateNoteRequest struct {
+	Title            string              `json:"title" binding:"required"`
+	Body             string              `json:"body"`
+	ActionItems      []ActionItemRequest `json:"action_items" binding:"dive"`
+	SourceMessageIDs []string            `json:"source_message_ids"`
+}
+
+// UpdateNoteRequest only changes the fields that are set. Version must match
+// the note's current version, otherwise the update is rejected as a conflict.
+type UpdateNoteRequest struct {
+	Title            *string              `json:"title"`
+	Body             *string              `json:"body"`
+	ActionItems      *[]ActionItemRequest `json:"action_items"`
+	SourceMessageIDs *[]string            `json:"source_message_ids"`
+	Version          int                  `json:"version" binding:"required"`
+}
+
+type GenerateNoteRequest struct {
+	Model       string `json:"model"`
+	MaxMessages int    `json:"max_messages"`
+}
+
+type NoteResponse struct {
+	ID               string             `json:"id"`
+	RoomID           string             `json:"room_id"`
+	CreatedByID      string             `json:"created_by_id"`
+	CreatedByName    string             `json:"created_by_name"`
+	UpdatedByID      *string            `json:"updated_by_id,omitempty"`
+	Title            string             `json:"title"`
+	Body             string             `json:"body"`
+	ActionItems      []model.ActionItem `json:"action_items"`
+	SourceMessageIDs []string           `json:"source_message_ids"`
+	GeneratedByAI    bool               `json:"generated_by_ai"`
+	Version          int                `json:"version"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+func (s *noteService) CreateNote(roomID, userID string, req CreateNoteRequest) (*NoteResponse, error) {
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.createNote(roomID, userID, req, false)
+}
+
+func (s *noteService) createNote(roomID, userID string, req CreateNoteRequest, generated bool) (*NoteResponse, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, errors.New("title cannot be empty")
+	}
+
+	actionItems, err := s.buildActionItems(roomID, req.ActionItems)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceIDs, err := s.validateSourceMessages(roomID, req.SourceMessageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	note := &model.MeetingNote{
+		RoomID:           roomID,
+		CreatedByID:      userID,
+		Title:            title,
+		Body:             req.Body,
+		ActionItems:      actionItems,
+		SourceMessageIDs: sourceIDs,
+		GeneratedByAI:    generated,
+		Version:          1,
+	}
+
+	if err := s.noteRepo.Create(note); err != nil {
+		return nil, errors.New("failed to create note")
+	}
+
+	created, err := s.noteRepo.FindByID(note.ID)
+	if err != nil {
+		return nil, errors.New("failed to fetch created note")
+	}
+
+	response := s.noteToResponse(created)
+	broadcast(s.broadcaster, roomID, userID, "note_created", response)
+	return response, nil
+}
+
+func (s *noteService) GetNotes(roomID, userID string) ([]NoteResponse, error) {
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	notes, err := s.noteRepo.FindByRoomID(roomID)
+	if err != nil {
+		return nil, errors.New("failed to fetch notes")
+	}
+
+	responses := make([]NoteResponse, len(notes))
+	for i := range notes {
+		responses[i] = *s.noteToResponse(&notes[i])
+	}
+	return responses, nil
+}
+
+func (s *noteService) GetNote(roomID, noteID, userID string) (*NoteResponse, error) {
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	note, err := s.findNote(roomID, noteID)
+	if err != nil {
+		return nil, err
+	}
+	return s.noteToResponse(note), nil
+}
+
+func (s *noteService) UpdateNote(roomID, noteID, userID string, req UpdateNoteRequest) (*NoteResponse, error) {
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	note, err := s.findNote(roomID, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			return nil, errors.New("title cannot be empty")
+		}
+		note.Title = title
+	}
+	if req.Body != nil {
+		note.Body = *req.Body
+	}
+	if req.ActionItems != nil {
+		actionItems, err := s.buildActionItems(roomID, *req.ActionItems)
+		if err != nil {
+			return nil, err
+		}
+		note.ActionItems = actionItems
+	}
+	if req.SourceMessageIDs != nil {
+		sourceIDs, err := s.validateSourceMessages(roomID, *req.SourceMessageIDs)
+		if err != nil {
+			return nil, err
+		}
+		note.SourceMessageIDs = sourceIDs
+	}
+	note.UpdatedByID = &userID
+
+	if err := s.noteRepo.Update(note, req.Version); err != nil {
+		if errors.Is(err, repository.ErrNoteVersionConflict) {
+			return nil, ErrNoteVersionConflict
+		}
+		return nil, errors.New("failed to update note")
+	}
+
+	updated, err := s.noteRepo.FindByID(noteID)
+	if err != nil {
+		return nil, errors.New("failed to fetch updated note")
+	}
+
+	response := s.noteToResponse(updated)
+	broadcast(s.broadcaster, roomID, userID, "note_updated", response)
+	return response, nil
+}
+
+func (s *noteService) DeleteNote(roomID, noteID, userID string) error {
+	room, err := s.roomRepo.FindByID(roomID)
+	if err != nil {
+		return ErrRoomNotFound
+	}
+
+	note, err := s.findNote(roomID, noteID)
+	if err != nil {
+		return err
+	}
+
+	// Only the note author or the room creator can delete
+	if note.CreatedByID != userID && room.CreatedByID != userID {
+		return errors.New("unauthorized to delete this note")
+	}
+
+	if err := s.noteRepo.Delete(noteID); err != nil {
+		return errors.New("failed to delete note")
+	}
+
+	broadcast(s.broadcaster, roomID, userID, "note_deleted", map[string]interface{}{
+		"id":      noteID,
+		"room_id": roomID,
+	})
+	return nil
+}
+
+// generatedNote is the JSON shape the model is asked to produce
+type generatedNote struct {
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	ActionItems []struct {
+		Description string `json:"description"`
+		Assignee    string `json:"assignee"`
+		DueDate     string `json:"due_date"`
+	} `json:"action_items"`
+	SourceMessageIDs []string `json:"source_message_ids"`
+}
+
+// noteTranscriptMaxChars caps how much spoken transcript is sent when drafting notes
+const noteTranscriptMaxChars = 12000
+
+const noteGenerationPrompt = `You are a meeting assistant. Draft meeting notes from the chat messages, spoken transcript and poll results below.
+Each chat line starts with the message ID in square brackets. Report poll outcomes as decisions where they settle a question.
+Respond with ONLY a JSON object, no prose and no code fences, using this shape:
+{"title": "short title", "body": "markdown summary with key points, decisions and poll outcomes", "action_items": [{"description": "task", "assignee": "participant name or empty", "due_date": "YYYY-MM-DD or empty"}], "source_message_ids": ["IDs of the messages the notes are based on"]}`
+
+func (s *noteService) GenerateNote(roomID, userID string, req GenerateNoteRequest) (*NoteResponse, error) {
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	maxMessages := req.MaxMessages
+	if maxMessages <= 0 || maxMessages > 500 {
+		maxMessages = 200
+	}
+	messages, err := s.chatRepo.FindByRoomID(roomID, maxMessages, 0)
+	if err != nil {
+		return nil, errors.New("failed to fetch messages")
+	}
+
+	// Spoken transcript of the meeting, if any was captured
+	spoken := ""
+	if s.transcriptService != nil {
+		spoken, _ = s.transcriptService.BuildContext(roomID, userID, noteTranscriptMaxChars)
+	}
+
+	// Poll questions and their results
+	polls := ""
+	if s.pollService != nil {
+		polls, _ = s.pollService.BuildContext(roomID)
+	}
+
+	if len(messages) == 0 && spoken == "" && polls == "" {
+		return nil, errors.New("room has no messages, transcript or polls to generate notes from")
+	}
+
+	var transcript strings.Builder
+	transcript.WriteString("Chat messages:\n")
+	for _, msg := range messages {
+		fmt.Fprintf(&transcript, "[%s] %s (%s): %s\n",
+			msg.ID, displayName(msg.User.FullName, msg.User.Username), msg.CreatedAt.Format("2006-01-02 15:04"), msg.Message)
+	}
+	if spoken != "" {
+		transcript.WriteString("\nSpoken transcript (no message IDs):\n")
+		transcript.WriteString(spoken)
+		transcript.WriteString("\n")
+	}
+	if polls != "" {
+		transcript.WriteString("\nPolls:\n")
+		transcript.WriteString(polls)
+	}
+
+	modelName := req.Model
+	if modelName == "" {
+		modelName = DefaultChatModel
+	}
+
+	response, err := s.kolosalService.ChatCompletions(&KolosalChatRequest{
+		Model: modelName,
+		Messages: []Message{
+			{Role: "system", Content: noteGenerationPrompt},
+			{Role: "user", Content: transcript.String()},
+		},
+		MaxTokens:   2000,
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate notes: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return nil, errors.New("AI returned no notes")
+	}
+
+	draft, err := parseGeneratedNote(response.Choices[0].Message.Content)
+	if err != nil {
+		log.Printf("[NoteService] Could not parse generated note: %v", err)
+		return nil, errors.New("AI returned notes in an unexpected format")
+	}
+
+	// Match assignee names against room participants
+	participants, _ := s.roomRepo.FindParticipants(roomID)
+	createReq := CreateNoteRequest{
+		Title:            draft.Title,
+		Body:             draft.Body,
+		SourceMessageIDs: draft.SourceMessageIDs,
+	}
+	if strings.TrimSpace(createReq.Title) == "" {
+		createReq.Title = "Meeting notes"
+	}
+	for _, item := range draft.ActionItems {
+		if strings.TrimSpace(item.Description) == "" {
+			continue
+		}
+		actionItem := ActionItemRequest{Description: item.Description}
+		if assignee := strings.TrimSpace(item.Assignee); assignee != "" {
+			actionItem.AssigneeName = &assignee
+			for _, p := range participants {
+				if p.RemovedByID != nil {
+					continue
+				}
+				if strings.EqualFold(p.FullName, assignee) || (p.Username != nil && strings.EqualFold(*p.Username, assignee)) {
+					id := p.UserID
+					actionItem.AssigneeID = &id
+					break
+				}
+			}
+		}
+		if dueDate := strings.TrimSpace(item.DueDate); dueDate != "" {
+			if _, err := time.Parse("2006-01-02", dueDate); err == nil {
+				actionItem.DueDate = &dueDate
+			}
+		}
+		createReq.ActionItems = append(createReq.ActionItems, actionItem)
+	}
+
+	// Drop any IDs the model invented
+	known := make(map[string]bool, len(messages))
+	for _, msg := range messages {
+		known[msg.ID] = true
+	}
+	sourceIDs := make([]string, 0, len(createReq.SourceMessageIDs))
+	for _, id := range createReq.SourceMessageIDs {
+		if known[id] {
+			sourceIDs = append(sourceIDs, id)
+		}
+	}
+	createReq.SourceMessageIDs = sourceIDs
+
+	return s.createNote(roomID, userID, createReq, true)
+}
+
+// parseGeneratedNote extracts the JSON object from the model output,
+// tolerating code fences or text around it
+func parseGeneratedNote(content string) (*generatedNote, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start == -1 || end <= start {
+		return nil, errors.New("no JSON object found")
+	}
+
+	var draft generatedNote
+	if err := json.Unmarshal([]byte(content[start:end+1]), &draft); err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+func (s *noteService) findNote(roomID, noteID string) (*model.MeetingNote, error) {
+	note, err := s.noteRepo.FindByID(noteID)
+	if err != nil || note.RoomID != roomID {
+		return nil, ErrNoteNotFound
+	}
+	return note, nil
+}
+
+// buildActionItems validates action items. Assignees must be members of the
+// room: its creator or a participant who was not removed.
+func (s *noteService) buildActionItems(roomID string, items []ActionItemRequest) ([]model.ActionItem, error) {
+	var room *model.Room
+	actionItems := make([]model.ActionItem, 0, len(items))
+	for i, item := range items {
+		description := strings.TrimSpace(item.Description)
+		if description == "" {
+			return nil, errors.New("action item description cannot be empty")
+		}
+
+		actionItem := model.ActionItem{
+			Description:  description,
+			AssigneeID:   item.AssigneeID,
+			AssigneeName: item.AssigneeName,
+			Completed:    item.Completed,
+			Position:     i,
+		}
+
+		if item.AssigneeID != nil && *item.AssigneeID != "" {
+			if room == nil {
+				found, err := s.roomRepo.FindByID(roomID)
+				if err != nil {
+					return nil, ErrRoomNotFound
+				}
+				room = found
+			}
+			if roomRole(s.roomRepo, room, *item.AssigneeID) == "" {
+				return nil, errors.New("assignee must be a member of this room")
+			}
+			user, err := s.userRepo.FindByID(*item.AssigneeID)
+			if err != nil {
+				return nil, errors.New("assignee not found")
+			}
+			if actionItem.AssigneeName == nil || *actionItem.AssigneeName == "" {
+				name := displayName(user.FullName, user.Username)
+				actionItem.AssigneeName = &name
+			}
+		} else {
+			actionItem.AssigneeID = nil
+		}
+
+		if item.DueDate != nil && *item.DueDate != "" {
+			dueDate, err := time.Parse("2006-01-02", *item.DueDate)
+			if err != nil {
+				return nil, errors.New("due_date must be in YYYY-MM-DD format")
+			}
+			actionItem.DueDate = &dueDate
+		}
+
+		actionItems = append(actionItems, actionItem)
+	}
+	return actionItems, nil
+}
+
+// validateSourceMessages ensures every referenced message belongs to the room
+func (s *noteService) validateSourceMessages(roomID string, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return []string{}, nil
+	}
+
+	messages, err := s.chatRepo.FindByIDsInRoom(roomID, ids)
+	if err != nil {
+		return nil, errors.New("failed to verify source messages")
+	}
+
+	found := make(map[string]bool, len(messages))
+	for _, msg := range messages {
+		found[msg.ID] = true
+	}
+
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fmt.Errorf("source message %s not found in this room", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique, nil
+}
+
+func (s *noteService) noteToResponse(note *model.MeetingNote) *NoteResponse {
+	createdByName := note.CreatedBy.FullName
+	if createdByName == "" {
+		createdByName = note.CreatedBy.Email
+	}
+
+	actionItems := note.ActionItems
+	if actionItems == nil {
+		actionItems = []model.ActionItem{}
+	}
+	sourceIDs := note.SourceMessageIDs
+	if sourceIDs == nil {
+		sourceIDs = []string{}
+	}
+
+	return &NoteResponse{
+		ID:               note.ID,
+		RoomID:           note.RoomID,
+		CreatedByID:      note.CreatedByID,
+		CreatedByName:    createdByName,
+		UpdatedByID:      note.UpdatedByID,
+		Title:            note.Title,
+		Body:             note.Body,
+		ActionItems:      actionItems,
+		SourceMessageIDs: sourceIDs,
+		GeneratedByAI:    note.GeneratedByAI,
+		Version:          note.Version,
+		CreatedAt:        note.CreatedAt,
+		UpdatedAt:        note.UpdatedAt,
+	}
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// fakeNoteRepo keeps notes in memory and enforces the optimistic version check
+type fakeNoteRepo struct {
+	repository.NoteRepository
+	notes map[string]*model.MeetingNote
+}
+
+func (r *fakeNoteRepo) Create(note *model.MeetingNote) error {
+	note.ID = fmt.Sprintf("note-%d", len(r.notes)+1)
+	copied := *note
+	r.notes[note.ID] = &copied
+	return nil
+}
+
+func (r *fakeNoteRepo) FindByID(id string) (*model.MeetingNote, error) {
+	note, ok := r.notes[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *note
+	copied.ActionItems = append([]model.ActionItem(nil), note.ActionItems...)
+	return &copied, nil
+}
+
+func (r *fakeNoteRepo) Update(note *model.MeetingNote, expectedVersion int) error {
+	stored, ok := r.notes[note.ID]
+	if !ok || stored.Version != expectedVersion {
+		return repository.ErrNoteVersionConflict
+	}
+	copied := *note
+	copied.Version = expectedVersion + 1
+	r.notes[note.ID] = &copied
+	note.Version = copied.Version
+	return nil
+}
+
+// noteRoomRepo lists the participants AI drafts match assignees against
+type noteRoomRepo struct {
+	*fakeRoomRepo
+	users *fakeUserRepo
+}
+
+func (r *noteRoomRepo) FindParticipants(roomID string) ([]repository.ParticipantDetail, error) {
+	var found []repository.ParticipantDetail
+	for _, p := range r.participants {
+		if p.RoomID != roomID {
+			continue
+		}
+		detail := repository.ParticipantDetail{RoomParticipant: *p}
+		if user, err := r.users.FindByID(p.UserID); err == nil {
+			detail.FullName = user.FullName
+			detail.Username = user.Username
+		}
+		found = append(found, detail)
+	}
+	return found, nil
+}
+
+// noteChatRepo serves the room's chat history
+type noteChatRepo struct {
+	repository.ChatRepository
+	messages []model.ChatMessage
+}
+
+func (r *noteChatRepo) FindByRoomID(roomID string, limit, offset int) ([]model.ChatMessage, error) {
+	return r.messages, nil
+}
+
+func (r *noteChatRepo) FindByIDsInRoom(roomID string, ids []string) ([]model.ChatMessage, error) {
+	var found []model.ChatMessage
+	for _, msg := range r.messages {
+		for _, id := range ids {
+			if msg.ID == id && msg.RoomID == roomID {
+				found = append(found, msg)
+			}
+		}
+	}
+	return found, nil
+}
+
+// fakeKolosal answers chat completions with a canned reply
+type fakeKolosal struct {
+	KolosalService
+	reply    string
+	requests []*KolosalChatRequest
+}
+
+func (k *fakeKolosal) ChatCompletions(request *KolosalChatRequest) (*KolosalChatResponse, error) {
+	k.requests = append(k.requests, request)
+	return &KolosalChatResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: k.reply}}}}, nil
+}
+
+// newTestNoteService sets up room-1, created by "host", with a member, a
+// removed participant and an outsider who has an account but never joined
+func newTestNoteService() (NoteService, *fakeNoteRepo, *noteChatRepo, *fakeKolosal) {
+	users := &fakeUserRepo{users: []*model.User{
+		{ID: "host", FullName: "Host Person"},
+		{ID: "member", FullName: "Member Person"},
+		{ID: "removed", FullName: "Removed Person"},
+		{ID: "outsider", FullName: "Outside Person"},
+	}}
+	rooms := &noteRoomRepo{fakeRoomRepo: newFakeRoomRepo(), users: users}
+	rooms.rooms["room-1"] = &model.Room{ID: "room-1", CreatedByID: "host"}
+	rooms.access["room-1/host"] = true
+	rooms.access["room-1/member"] = true
+	moderator := "host"
+	rooms.participants["room-1/host"] = &model.RoomParticipant{RoomID: "room-1", UserID: "host", Role: model.RoleHost}
+	rooms.participants["room-1/member"] = &model.RoomParticipant{RoomID: "room-1", UserID: "member", Role: model.RoleParticipant}
+	rooms.participants["room-1/removed"] = &model.RoomParticipant{RoomID: "room-1", UserID: "removed", Role: model.RoleParticipant, RemovedByID: &moderator}
+
+	notes := &fakeNoteRepo{notes: make(map[string]*model.MeetingNote)}
+	chat := &noteChatRepo{messages: []model.ChatMessage{
+		{ID: "m1", RoomID: "room-1", Message: "Member will send the budget by Friday", User: model.User{FullName: "Host Person"}},
+		{ID: "m2", RoomID: "room-1", Message: "Sounds good", User: model.User{FullName: "Member Person"}},
+	}}
+	kolosal := &fakeKolosal{}
+	svc := NewNoteService(notes, rooms, chat, users, kolosal, nil, nil, nil)
+	return svc, notes, chat, kolosal
+}
+
+func TestCreateNoteAssigneesMustBeMembers(t *testing.T) {
+	svc, _, _, _ := newTestNoteService()
+
+	tests := []struct {
+		assigneeID string
+		wantErr    bool
+	}{
+		{"member", false},
+		{"host", false},
+		{"removed", true},
+		{"outsider", true},
+		{"missing", true},
+	}
+	for _, tt := range tests {
+		assignee := tt.assigneeID
+		note, err := svc.CreateNote("room-1", "member", CreateNoteRequest{
+			Title:       "Follow-ups",
+			ActionItems: []ActionItemRequest{{Description: "Send the budget", AssigneeID: &assignee}},
+		})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("assignee %s: got a note, want an error", tt.assigneeID)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("assignee %s: %v", tt.assigneeID, err)
+			continue
+		}
+		item := note.ActionItems[0]
+		if item.AssigneeID == nil || *item.AssigneeID != tt.assigneeID || item.AssigneeName == nil || *item.AssigneeName == "" {
+			t.Errorf("assignee %s: action item = %+v, want the ID and the display name", tt.assigneeID, item)
+		}
+	}
+
+	// A free-text assignee without an account is kept as a name
+	name := "Someone from finance"
+	note, err := svc.CreateNote("room-1", "member", CreateNoteRequest{
+		Title:       "Follow-ups",
+		ActionItems: []ActionItemRequest{{Description: "Approve the budget", AssigneeName: &name}},
+	})
+	if err != nil {
+		t.Fatalf("CreateNote: %v", err)
+	}
+	if item := note.ActionItems[0]; item.AssigneeID != nil || item.AssigneeName == nil || *item.AssigneeName != name {
+		t.Errorf("action item = %+v, want only the name", item)
+	}
+}
+
+func TestCreateNoteRequiresRoomAccess(t *testing.T) {
+	svc, _, _, _ := newTestNoteService()
+
+	if _, err := svc.CreateNote("room-1", "outsider", CreateNoteRequest{Title: "Notes"}); !errors.Is(err, ErrRoomAccessDenied) {
+		t.Errorf("outsider: got %v, want ErrRoomAccessDenied", err)
+	}
+	if _, err := svc.CreateNote("room-1", "member", CreateNoteRequest{Title: "Notes", SourceMessageIDs: []string{"m9"}}); err == nil {
+		t.Error("unknown source message: got nil, want an error")
+	}
+}
+
+func TestUpdateNoteVersionConflict(t *testing.T) {
+	svc, notes, _, _ := newTestNoteService()
+
+	note, err := svc.CreateNote("room-1", "host", CreateNoteRequest{Title: "Draft"})
+	if err != nil {
+		t.Fatalf("CreateNote: %v", err)
+	}
+	if note.Version != 1 {
+		t.Fatalf("new note has version %d, want 1", note.Version)
+	}
+
+	first := "Edited by the host"
+	updated, err := svc.UpdateNote("room-1", note.ID, "host", UpdateNoteRequest{Title: &first, Version: 1})
+	if err != nil {
+		t.Fatalf("UpdateNote: %v", err)
+	}
+	if updated.Version != 2 || updated.Title != first || updated.UpdatedByID == nil || *updated.UpdatedByID != "host" {
+		t.Errorf("updated note = version %d, title %q, updated by %v", updated.Version, updated.Title, updated.UpdatedByID)
+	}
+
+	// A second editor still holding version 1 must reload first
+	second := "Edited by the member"
+	if _, err := svc.UpdateNote("room-1", note.ID, "member", UpdateNoteRequest{Title: &second, Version: 1}); !errors.Is(err, ErrNoteVersionConflict) {
+		t.Fatalf("stale update: got %v, want ErrNoteVersionConflict", err)
+	}
+	if stored := notes.notes[note.ID]; stored.Title != first || stored.Version != 2 {
+		t.Errorf("stored note = %q version %d after a conflict, want the first edit kept", stored.Title, stored.Version)
+	}
+
+	// Assignees are checked on updates too
+	outsider := "outsider"
+	items := []ActionItemRequest{{Description: "Review", AssigneeID: &outsider}}
+	if _, err := svc.UpdateNote("room-1", note.ID, "member", UpdateNoteRequest{ActionItems: &items, Version: 2}); err == nil {
+		t.Error("update assigning an outsider: got nil, want an error")
+	}
+}
+
+func TestGenerateNoteFromAIDraft(t *testing.T) {
+	svc, _, _, kolosal := newTestNoteService()
+	kolosal.reply = "Here are the notes:\n```json\n" + `{
+		"title": "Budget sync",
+		"body": "- Budget is due Friday",
+		"action_items": [
+			{"description": "Send the budget", "assignee": "member person", "due_date": "2025-03-07"},
+			{"description": "Check the numbers", "assignee": "Removed Person", "due_date": "Friday"},
+			{"description": "Book a room", "assignee": ""},
+			{"description": "  ", "assignee": "Host Person"}
+		],
+		"source_message_ids": ["m1", "m42"]
+	}` + "\n```"
+
+	note, err := svc.GenerateNote("room-1", "member", GenerateNoteRequest{})
+	if err != nil {
+		t.Fatalf("GenerateNote: %v", err)
+	}
+
+	prompt := kolosal.requests[0].Messages[1].Content
+	if !strings.Contains(prompt, "[m1] Host Person") || !strings.Contains(prompt, "[m2] Member Person") {
+		t.Errorf("prompt does not list the chat messages with their IDs:\n%s", prompt)
+	}
+
+	if !note.GeneratedByAI || note.Title != "Budget sync" || note.CreatedByID != "member" {
+		t.Errorf("note = %+v, want an AI note titled Budget sync by member", note)
+	}
+	if len(note.SourceMessageIDs) != 1 || note.SourceMessageIDs[0] != "m1" {
+		t.Errorf("source messages = %v, want the invented ID dropped", note.SourceMessageIDs)
+	}
+	if len(note.ActionItems) != 3 {
+		t.Fatalf("got %d action items, want 3 (the empty one dropped)", len(note.ActionItems))
+	}
+
+	send := note.ActionItems[0]
+	if send.AssigneeID == nil || *send.AssigneeID != "member" || send.DueDate == nil {
+		t.Errorf("first item = %+v, want it assigned to member with a due date", send)
+	}
+	check := note.ActionItems[1]
+	if check.AssigneeID != nil || check.AssigneeName == nil || *check.AssigneeName != "Removed Person" || check.DueDate != nil {
+		t.Errorf("second item = %+v, want only the name of the removed participant and no due date", check)
+	}
+	if book := note.ActionItems[2]; book.AssigneeID != nil || book.AssigneeName != nil {
+		t.Errorf("third item = %+v, want it unassigned", book)
+	}
+}
+
+func TestGenerateNoteRejectsMalformedDraft(t *testing.T) {
+	svc, notes, _, kolosal := newTestNoteService()
+	kolosal.reply = "Sorry, I cannot help with that."
+
+	if _, err := svc.GenerateNote("room-1", "member", GenerateNoteRequest{}); err == nil {
+		t.Error("malformed draft: got nil, want an error")
+	}
+	if len(notes.notes) != 0 {
+		t.Errorf("stored %d notes, want none", len(notes.notes))
+	}
+}
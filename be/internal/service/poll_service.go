@@ -0,0 +1,453 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+var (
+	ErrPollNotFound = errors.New("poll not found")
+	ErrPollClosed   = repository.ErrPollClosed
+)
+
+// Poll limits
+const (
+	maxPollOptions  = 20
+	maxPollDuration = 24 * time.Hour
+)
+
+// Poll states in responses
+const (
+	pollOpen   = "open"
+	pollClosed = "closed"
+)
+
+// PollService runs polls inside a meeting room. Hosts and co-hosts create and
+// close polls; everyone in the room can vote and sees the tally update live.
+type PollService interface {
+	Create(roomID, userID string, req CreatePollRequest) (*PollResponse, error)
+	List(roomID, userID string) ([]PollResponse, error)
+	Get(roomID, pollID, userID string) (*PollResponse, error)
+	Vote(roomID, pollID, userID string, req VoteRequest) (*PollResponse, error)
+	Close(roomID, pollID, userID string) (*PollResponse, error)
+	BuildContext(roomID string) (string, error)
+	Start()
+}
+
+type pollService struct {
+	pollRepo    repository.PollRepository
+	roomRepo    repository.RoomRepository
+	broadcaster Broadcaster
+
+	mu     sync.Mutex
+	timers map[string]*time.Timer // Close timers by poll
+}
+
+func NewPollService(pollRepo repository.PollRepository, roomRepo repository.RoomRepository, broadcaster Broadcaster) PollService {
+	return &pollService{
+		pollRepo:    pollRepo,
+		roomRepo:    roomRepo,
+		broadcaster: broadcaster,
+		timers:      make(map[string]*time.Timer),
+	}
+}
+
+type CreatePollRequest struct {
+	Question  string     `json:"question" binding:"required"`
+	Options   []string   `json:"options" binding:"required"`
+	Kind      string     `json:"kind"`      // single (default) or multiple
+	Anonymous bool       `json:"anonymous"` // Hide who voted for what
+	ClosesAt  *time.Time `json:"closes_at"` // Close automatically; otherwise open until closed
+}
+
+// VoteRequest replaces the user's earlier vote, if any
+type VoteRequest struct {
+	OptionIDs []string `json:"option_ids" binding:"required"`
+}
+
+type PollVoter struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+type PollOptionResult struct {
+	ID     string      `json:"id"`
+	Text   string      `json:"text"`
+	Votes  int         `json:"votes"`
+	Voters []PollVoter `json:"voters,omitempty"` // Left out for anonymous polls
+}
+
+type PollResponse struct {
+	ID          string             `json:"id"`
+	RoomID      string             `json:"room_id"`
+	CreatedByID string             `json:"created_by_id"`
+	Question    string             `json:"question"`
+	Kind        string             `json:"kind"`
+	Anonymous   bool               `json:"anonymous"`
+	Status      string             `json:"status"` // open or closed
+	ClosesAt    *time.Time         `json:"closes_at,omitempty"`
+	ClosedAt    *time.Time         `json:"closed_at,omitempty"`
+	Options     []PollOptionResult `json:"options"`
+	TotalVoters int                `json:"total_voters"`
+	MyVotes     []string           `json:"my_votes,omitempty"` // Options the requesting user voted for
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+func (s *pollService) Create(roomID, userID string, req CreatePollRequest) (*PollResponse, error) {
+	room, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost)
+	if err != nil {
+		return nil, err
+	}
+	if !room.IsOpen() {
+		return nil, ErrRoomClosed
+	}
+
+	question := strings.TrimSpace(req.Question)
+	if question == "" || len(question) > 500 {
+		return nil, errors.New("question must be between 1 and 500 characters")
+	}
+	kind := req.Kind
+	if kind == "" {
+		kind = model.PollSingleChoice
+	}
+	if kind != model.PollSingleChoice && kind != model.PollMultipleChoice {
+		return nil, errors.New("kind must be single or multiple")
+	}
+
+	if len(req.Options) < 2 || len(req.Options) > maxPollOptions {
+		return nil, fmt.Errorf("a poll needs between 2 and %d options", maxPollOptions)
+	}
+	options := make([]model.PollOption, 0, len(req.Options))
+	seen := make(map[string]bool, len(req.Options))
+	for i, text := range req.Options {
+		text = strings.TrimSpace(text)
+		if text == "" || len(text) > 255 {
+			return nil, errors.New("options must be between 1 and 255 characters")
+		}
+		if seen[strings.ToLower(text)] {
+			return nil, fmt.Errorf("option %q is listed more than once", text)
+		}
+		seen[strings.ToLower(text)] = true
+		options = append(options, model.PollOption{Text: text, Position: i})
+	}
+
+	now := time.Now()
+	var closesAt *time.Time
+	if req.ClosesAt != nil {
+		t := req.ClosesAt.Truncate(time.Second)
+		if !t.After(now) || t.Sub(now) > maxPollDuration {
+			return nil, errors.New("closes_at must be in the future and within 24 hours")
+		}
+		closesAt = &t
+	}
+
+	poll := &model.Poll{
+		RoomID:      roomID,
+		CreatedByID: userID,
+		Question:    question,
+		Kind:        kind,
+		Anonymous:   req.Anonymous,
+		ClosesAt:    closesAt,
+		Options:     options,
+	}
+	if err := s.pollRepo.Create(poll); err != nil {
+		return nil, errors.New("failed to create poll")
+	}
+	if closesAt != nil {
+		s.schedule(poll.ID, *closesAt)
+	}
+
+	response := s.pollToResponse(poll, nil, "")
+	broadcast(s.broadcaster, roomID, userID, "poll_created", response)
+	return &response, nil
+}
+
+func (s *pollService) List(roomID, userID string) ([]PollResponse, error) {
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	polls, err := s.pollRepo.FindByRoomID(roomID)
+	if err != nil {
+		return nil, errors.New("failed to fetch polls")
+	}
+	votes, err := s.findVotes(polls)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]PollResponse, len(polls))
+	for i := range polls {
+		responses[i] = s.pollToResponse(&polls[i], votes[polls[i].ID], userID)
+	}
+	return responses, nil
+}
+
+func (s *pollService) Get(roomID, pollID, userID string) (*PollResponse, error) {
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	poll, err := s.findPoll(roomID, pollID)
+	if err != nil {
+		return nil, err
+	}
+	return s.results(poll, userID)
+}
+
+// Vote records the user's choice and broadcasts the new tally to the room
+func (s *pollService) Vote(roomID, pollID, userID string, req VoteRequest) (*PollResponse, error) {
+	if wasRemoved(s.roomRepo, roomID, userID) {
+		return nil, ErrRemovedFromRoom
+	}
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	poll, err := s.findPoll(roomID, pollID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if poll.IsClosed(now) {
+		return nil, ErrPollClosed
+	}
+
+	valid := make(map[string]bool, len(poll.Options))
+	for _, option := range poll.Options {
+		valid[option.ID] = true
+	}
+	optionIDs := make([]string, 0, len(req.OptionIDs))
+	chosen := make(map[string]bool, len(req.OptionIDs))
+	for _, id := range req.OptionIDs {
+		if !valid[id] {
+			return nil, errors.New("option does not belong to this poll")
+		}
+		if !chosen[id] {
+			chosen[id] = true
+			optionIDs = append(optionIDs, id)
+		}
+	}
+	if len(optionIDs) == 0 {
+		return nil, errors.New("choose at least one option")
+	}
+	if poll.Kind == model.PollSingleChoice && len(optionIDs) > 1 {
+		return nil, errors.New("this poll allows only one choice")
+	}
+
+	if err := s.pollRepo.Vote(pollID, userID, optionIDs, now); err != nil {
+		if errors.Is(err, repository.ErrPollClosed) {
+			return nil, ErrPollClosed
+		}
+		return nil, errors.New("failed to record vote")
+	}
+
+	votes, err := s.pollRepo.FindVotes([]string{pollID})
+	if err != nil {
+		return nil, errors.New("failed to fetch poll results")
+	}
+	broadcast(s.broadcaster, roomID, userID, "poll_updated", s.pollToResponse(poll, votes, ""))
+
+	response := s.pollToResponse(poll, votes, userID)
+	return &response, nil
+}
+
+func (s *pollService) Close(roomID, pollID, userID string) (*PollResponse, error) {
+	if _, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost); err != nil {
+		return nil, err
+	}
+
+	poll, err := s.findPoll(roomID, pollID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.ClosedAt != nil {
+		return nil, ErrPollClosed
+	}
+
+	return s.close(poll, userID, time.Now())
+}
+
+// BuildContext renders the room's polls and their results as plain text for
+// AI prompts, oldest first. Voter names are never included.
+func (s *pollService) BuildContext(roomID string) (string, error) {
+	polls, err := s.pollRepo.FindByRoomID(roomID)
+	if err != nil {
+		return "", errors.New("failed to fetch polls")
+	}
+	votes, err := s.findVotes(polls)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i := len(polls) - 1; i >= 0; i-- {
+		result := s.pollToResponse(&polls[i], votes[polls[i].ID], "")
+		fmt.Fprintf(&b, "Poll (%s choice, %s, %d voters): %s\n", result.Kind, result.Status, result.TotalVoters, result.Question)
+		for _, option := range result.Options {
+			fmt.Fprintf(&b, "- %s: %d votes\n", option.Text, option.Votes)
+		}
+	}
+	return b.String(), nil
+}
+
+// Start re-arms the close timers of open polls. Polls whose close time
+// passed while the server was down close right away.
+func (s *pollService) Start() {
+	polls, err := s.pollRepo.FindPendingClose()
+	if err != nil {
+		log.Printf("[Poll] Failed to restore poll timers: %v", err)
+		return
+	}
+	for i := range polls {
+		s.schedule(polls[i].ID, *polls[i].ClosesAt)
+	}
+}
+
+// close ends voting and broadcasts the final results
+func (s *pollService) close(poll *model.Poll, userID string, at time.Time) (*PollResponse, error) {
+	s.cancelTimer(poll.ID)
+
+	ok, err := s.pollRepo.Close(poll.ID, at)
+	if err != nil {
+		return nil, errors.New("failed to close poll")
+	}
+	if !ok {
+		return nil, ErrPollClosed
+	}
+	poll.ClosedAt = &at
+
+	votes, err := s.pollRepo.FindVotes([]string{poll.ID})
+	if err != nil {
+		return nil, errors.New("failed to fetch poll results")
+	}
+	broadcast(s.broadcaster, poll.RoomID, userID, "poll_closed", s.pollToResponse(poll, votes, ""))
+
+	response := s.pollToResponse(poll, votes, userID)
+	return &response, nil
+}
+
+func (s *pollService) schedule(pollID string, closesAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if timer, ok := s.timers[pollID]; ok {
+		timer.Stop()
+	}
+	s.timers[pollID] = time.AfterFunc(time.Until(closesAt), func() {
+		s.expire(pollID)
+	})
+}
+
+func (s *pollService) cancelTimer(pollID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if timer, ok := s.timers[pollID]; ok {
+		timer.Stop()
+		delete(s.timers, pollID)
+	}
+}
+
+// expire closes a poll when its close time is reached, unless it was closed
+// by hand in the meantime
+func (s *pollService) expire(pollID string) {
+	poll, err := s.pollRepo.FindByID(pollID)
+	if err != nil || poll.ClosedAt != nil || poll.ClosesAt == nil {
+		s.cancelTimer(pollID)
+		return
+	}
+	if _, err := s.close(poll, "", *poll.ClosesAt); err != nil && !errors.Is(err, ErrPollClosed) {
+		log.Printf("[Poll] Failed to close poll %s: %v", pollID, err)
+	}
+}
+
+func (s *pollService) findPoll(roomID, pollID string) (*model.Poll, error) {
+	poll, err := s.pollRepo.FindByID(pollID)
+	if err != nil || poll.RoomID != roomID {
+		return nil, ErrPollNotFound
+	}
+	return poll, nil
+}
+
+func (s *pollService) results(poll *model.Poll, userID string) (*PollResponse, error) {
+	votes, err := s.pollRepo.FindVotes([]string{poll.ID})
+	if err != nil {
+		return nil, errors.New("failed to fetch poll results")
+	}
+	response := s.pollToResponse(poll, votes, userID)
+	return &response, nil
+}
+
+// findVotes loads the votes of several polls, grouped by poll
+func (s *pollService) findVotes(polls []model.Poll) (map[string][]repository.PollVoteDetail, error) {
+	ids := make([]string, len(polls))
+	for i := range polls {
+		ids[i] = polls[i].ID
+	}
+	votes, err := s.pollRepo.FindVotes(ids)
+	if err != nil {
+		return nil, errors.New("failed to fetch poll results")
+	}
+
+	byPoll := make(map[string][]repository.PollVoteDetail, len(polls))
+	for _, vote := range votes {
+		byPoll[vote.PollID] = append(byPoll[vote.PollID], vote)
+	}
+	return byPoll, nil
+}
+
+// pollToResponse tallies the votes of a poll. viewerID, when set, fills in
+// MyVotes; broadcasts leave it empty.
+func (s *pollService) pollToResponse(poll *model.Poll, votes []repository.PollVoteDetail, viewerID string) PollResponse {
+	response := PollResponse{
+		ID:          poll.ID,
+		RoomID:      poll.RoomID,
+		CreatedByID: poll.CreatedByID,
+		Question:    poll.Question,
+		Kind:        poll.Kind,
+		Anonymous:   poll.Anonymous,
+		Status:      pollOpen,
+		ClosesAt:    poll.ClosesAt,
+		ClosedAt:    poll.ClosedAt,
+		Options:     make([]PollOptionResult, len(poll.Options)),
+		CreatedAt:   poll.CreatedAt,
+	}
+	if poll.IsClosed(time.Now()) {
+		response.Status = pollClosed
+	}
+
+	index := make(map[string]int, len(poll.Options))
+	for i, option := range poll.Options {
+		index[option.ID] = i
+		response.Options[i] = PollOptionResult{ID: option.ID, Text: option.Text}
+	}
+
+	voters := make(map[string]bool)
+	for _, vote := range votes {
+		i, ok := index[vote.OptionID]
+		if !ok {
+			continue
+		}
+		result := &response.Options[i]
+		result.Votes++
+		if !poll.Anonymous {
+			result.Voters = append(result.Voters, PollVoter{
+				UserID: vote.UserID,
+				Name:   displayName(vote.FullName, vote.Username),
+			})
+		}
+		voters[vote.UserID] = true
+		if viewerID != "" && vote.UserID == viewerID {
+			response.MyVotes = append(response.MyVotes, vote.OptionID)
+		}
+	}
+	response.TotalVoters = len(voters)
+	return response
+}
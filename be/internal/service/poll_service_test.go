@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// fakePollRepo keeps polls and ballots in memory
+type fakePollRepo struct {
+	repository.PollRepository
+	polls map[string]*model.Poll
+	votes []repository.PollVoteDetail
+}
+
+func (r *fakePollRepo) FindByID(id string) (*model.Poll, error) {
+	poll, ok := r.polls[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *poll
+	return &copied, nil
+}
+
+func (r *fakePollRepo) Vote(pollID, userID string, optionIDs []string, at time.Time) error {
+	kept := r.votes[:0]
+	for _, vote := range r.votes {
+		if vote.PollID != pollID || vote.UserID != userID {
+			kept = append(kept, vote)
+		}
+	}
+	for _, optionID := range optionIDs {
+		kept = append(kept, repository.PollVoteDetail{PollVote: model.PollVote{PollID: pollID, UserID: userID, OptionID: optionID}})
+	}
+	r.votes = kept
+	return nil
+}
+
+func (r *fakePollRepo) FindVotes(pollIDs []string) ([]repository.PollVoteDetail, error) {
+	return r.votes, nil
+}
+
+func TestPollVoteExcludesRemovedParticipants(t *testing.T) {
+	rooms := newFakeRoomRepo()
+	rooms.rooms["room-1"] = &model.Room{ID: "room-1", CreatedByID: "host"}
+	rooms.access["room-1/member"] = true
+	rooms.access["room-1/removed"] = true
+	moderator := "host"
+	rooms.participants["room-1/member"] = &model.RoomParticipant{RoomID: "room-1", UserID: "member"}
+	rooms.participants["room-1/removed"] = &model.RoomParticipant{RoomID: "room-1", UserID: "removed", RemovedByID: &moderator}
+	polls := &fakePollRepo{polls: map[string]*model.Poll{
+		"poll-1": {ID: "poll-1", RoomID: "room-1", Kind: model.PollSingleChoice, Options: []model.PollOption{{ID: "yes"}, {ID: "no"}}},
+	}}
+	svc := NewPollService(polls, rooms, nil)
+
+	if _, err := svc.Vote("room-1", "poll-1", "removed", VoteRequest{OptionIDs: []string{"yes"}}); !errors.Is(err, ErrRemovedFromRoom) {
+		t.Errorf("vote by a removed participant: got %v, want ErrRemovedFromRoom", err)
+	}
+	if _, err := svc.Vote("room-1", "poll-1", "member", VoteRequest{OptionIDs: []string{"no"}}); err != nil {
+		t.Fatalf("vote by a participant: %v", err)
+	}
+	if len(polls.votes) != 1 || polls.votes[0].UserID != "member" {
+		t.Errorf("recorded votes = %+v, want only the participant's", polls.votes)
+	}
+}
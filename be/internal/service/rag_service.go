@@ -0,0 +1,289 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"unicode/utf8"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+const (
+	// chunkSize and chunkOverlap are measured in characters
+	chunkSize    = 800
+	chunkOverlap = 100
+	maxTopK      = 20
+
+	// maxSearchChunks bounds how many chunks a search loads and scores.
+	// Similarity is computed in the application, so very large rooms only
+	// search their most recent content.
+	maxSearchChunks = 5000
+)
+
+// RAGService indexes room content (chat, transcripts, OCR) as embeddings and
+// retrieves the most relevant snippets for a query
+type RAGService interface {
+	IndexMessage(roomID, messageID, author, text string) error
+	IndexTranscriptSegments(segments []model.TranscriptSegment) error
+	IndexOCR(roomID, sourceID, text string) error
+	ReindexRoom(roomID, userID string) (int, error)
+	Search(roomID, userID, query string, k int) ([]RetrievedSnippet, error)
+	BuildContextMessage(snippets []RetrievedSnippet) Message
+}
+
+type ragService struct {
+	embeddingRepo  repository.EmbeddingRepository
+	chatRepo       repository.ChatRepository
+	transcriptRepo repository.TranscriptRepository
+	roomRepo       repository.RoomRepository
+	provider       EmbeddingProvider
+	defaultTopK    int
+	scanLimit      int
+}
+
+func NewRAGService(embeddingRepo repository.EmbeddingRepository, chatRepo repository.ChatRepository, transcriptRepo repository.TranscriptRepository, roomRepo repository.RoomRepository, provider EmbeddingProvider, defaultTopK int) RAGService {
+	if defaultTopK <= 0 {
+		defaultTopK = 5
+	}
+	return &ragService{
+		embeddingRepo:  embeddingRepo,
+		chatRepo:       chatRepo,
+		transcriptRepo: transcriptRepo,
+		roomRepo:       roomRepo,
+		provider:       provider,
+		defaultTopK:    defaultTopK,
+		scanLimit:      maxSearchChunks,
+	}
+}
+
+// RetrievedSnippet is a chunk of room content returned by retrieval.
+// Citation is the 1-based number the model is asked to cite, e.g. [1].
+type RetrievedSnippet struct {
+	Citation   int     `json:"citation"`
+	SourceType string  `json:"source_type"` // message, transcript, ocr
+	SourceID   string  `json:"source_id"`
+	ChunkIndex int     `json:"chunk_index"`
+	Content    string  `json:"content"`
+	Score      float64 `json:"score"`
+}
+
+func (s *ragService) IndexMessage(roomID, messageID, author, text string) error {
+	content := strings.TrimSpace(text)
+	if content == "" {
+		return nil
+	}
+	if author != "" {
+		content = author + ": " + content
+	}
+	return s.indexSource(roomID, model.EmbeddingSourceMessage, messageID, chunkText(content))
+}
+
+func (s *ragService) IndexTranscriptSegments(segments []model.TranscriptSegment) error {
+	for _, segment := range segments {
+		content := segment.SpeakerName + ": " + segment.Text
+		if err := s.indexSource(segment.RoomID, model.EmbeddingSourceTranscript, segment.ID, chunkText(content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ragService) IndexOCR(roomID, sourceID, text string) error {
+	return s.indexSource(roomID, model.EmbeddingSourceOCR, sourceID, chunkText(text))
+}
+
+// ReindexRoom rebuilds the chat and transcript embeddings of a room. OCR chunks
+// are kept because their source text is not stored elsewhere. Reindexing
+// embeds the whole history, so only the host and co-hosts can start it.
+func (s *ragService) ReindexRoom(roomID, userID string) (int, error) {
+	if _, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost); err != nil {
+		return 0, err
+	}
+
+	indexed := 0
+	const pageSize = 200
+	for offset := 0; ; offset += pageSize {
+		messages, err := s.chatRepo.FindByRoomID(roomID, pageSize, offset)
+		if err != nil {
+			return indexed, errors.New("failed to fetch messages")
+		}
+		for _, msg := range messages {
+			if err := s.IndexMessage(roomID, msg.ID, displayName(msg.User.FullName, msg.User.Username), msg.Message); err != nil {
+				return indexed, err
+			}
+			indexed++
+		}
+		if len(messages) < pageSize {
+			break
+		}
+	}
+
+	segments, err := s.transcriptRepo.FindByRoomID(roomID, nil, 100000)
+	if err != nil {
+		return indexed, errors.New("failed to fetch transcript")
+	}
+	if err := s.IndexTranscriptSegments(segments); err != nil {
+		return indexed, err
+	}
+	indexed += len(segments)
+
+	return indexed, nil
+}
+
+// Search returns the top-k chunks of the room most similar to the query,
+// after checking that the user has access to the room
+func (s *ragService) Search(roomID, userID, query string, k int) ([]RetrievedSnippet, error) {
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return nil, err
+	}
+	return s.retrieve(roomID, query, k)
+}
+
+func (s *ragService) retrieve(roomID, query string, k int) ([]RetrievedSnippet, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("query cannot be empty")
+	}
+	if k <= 0 {
+		k = s.defaultTopK
+	}
+	if k > maxTopK {
+		k = maxTopK
+	}
+
+	vectors, err := s.provider.Embed([]string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	queryVector := vectors[0]
+
+	chunks, err := s.embeddingRepo.FindByRoomID(roomID, s.provider.Name(), s.scanLimit)
+	if err != nil {
+		return nil, errors.New("failed to load room embeddings")
+	}
+
+	snippets := make([]RetrievedSnippet, 0, len(chunks))
+	for _, chunk := range chunks {
+		score := cosineSimilarity(queryVector, chunk.Embedding)
+		if score <= 0 {
+			continue
+		}
+		snippets = append(snippets, RetrievedSnippet{
+			SourceType: chunk.SourceType,
+			SourceID:   chunk.SourceID,
+			ChunkIndex: chunk.ChunkIndex,
+			Content:    chunk.Content,
+			Score:      score,
+		})
+	}
+
+	sort.SliceStable(snippets, func(i, j int) bool {
+		return snippets[i].Score > snippets[j].Score
+	})
+	if len(snippets) > k {
+		snippets = snippets[:k]
+	}
+	for i := range snippets {
+		snippets[i].Citation = i + 1
+	}
+
+	return snippets, nil
+}
+
+// BuildContextMessage renders retrieved snippets as a system message that asks
+// the model to cite them by number
+func (s *ragService) BuildContextMessage(snippets []RetrievedSnippet) Message {
+	var b strings.Builder
+	b.WriteString("You are an assistant in a video meeting. Answer using the numbered excerpts from this room below. ")
+	b.WriteString("Cite the excerpts you use with their number in square brackets, e.g. [1]. ")
+	b.WriteString("If the excerpts do not contain the answer, say so.\n\nExcerpts:\n")
+	for _, snippet := range snippets {
+		fmt.Fprintf(&b, "[%d] (%s) %s\n", snippet.Citation, snippet.SourceType, snippet.Content)
+	}
+	return Message{Role: "system", Content: b.String()}
+}
+
+func (s *ragService) indexSource(roomID, sourceType, sourceID string, texts []string) error {
+	if len(texts) == 0 {
+		return nil
+	}
+
+	vectors, err := s.provider.Embed(texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed %s %s: %w", sourceType, sourceID, err)
+	}
+
+	chunks := make([]model.EmbeddingChunk, len(texts))
+	for i, text := range texts {
+		chunks[i] = model.EmbeddingChunk{
+			RoomID:     roomID,
+			SourceType: sourceType,
+			SourceID:   sourceID,
+			ChunkIndex: i,
+			Content:    text,
+			Provider:   s.provider.Name(),
+			Embedding:  vectors[i],
+		}
+	}
+
+	if err := s.embeddingRepo.ReplaceSource(sourceType, sourceID, chunks); err != nil {
+		return fmt.Errorf("failed to store embeddings for %s %s: %w", sourceType, sourceID, err)
+	}
+	return nil
+}
+
+// chunkText splits text into overlapping chunks, preferring whitespace boundaries
+func chunkText(text string) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(text) <= chunkSize {
+		return []string{text}
+	}
+
+	runes := []rune(text)
+	var chunks []string
+	for start := 0; start < len(runes); {
+		end := start + chunkSize
+		if end >= len(runes) {
+			chunks = append(chunks, strings.TrimSpace(string(runes[start:])))
+			break
+		}
+
+		// Back off to the last whitespace so words are not cut in half
+		cut := end
+		for cut > start+chunkSize/2 && !isSpace(runes[cut]) {
+			cut--
+		}
+		if cut == start+chunkSize/2 {
+			cut = end
+		}
+
+		chunks = append(chunks, strings.TrimSpace(string(runes[start:cut])))
+		start = cut - chunkOverlap
+		if start < 0 {
+			start = 0
+		}
+	}
+	return chunks
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\n' || r == '\t' || r == '\r'
+}
+
+// indexAsync runs an indexing function in the background, logging failures
+func indexAsync(rag RAGService, description string, fn func(RAGService) error) {
+	if rag == nil {
+		return
+	}
+	go func() {
+		if err := fn(rag); err != nil {
+			log.Printf("[RAGService] Failed to index %s: %v", description, err)
+		}
+	}()
+}
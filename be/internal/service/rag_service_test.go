@@ -0,0 +1,227 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+// fakeEmbeddingRepo keeps embedding chunks in memory, oldest first
+type fakeEmbeddingRepo struct {
+	chunks []model.EmbeddingChunk
+}
+
+func (r *fakeEmbeddingRepo) ReplaceSource(sourceType, sourceID string, chunks []model.EmbeddingChunk) error {
+	kept := r.chunks[:0]
+	for _, chunk := range r.chunks {
+		if chunk.SourceType != sourceType || chunk.SourceID != sourceID {
+			kept = append(kept, chunk)
+		}
+	}
+	r.chunks = append(kept, chunks...)
+	return nil
+}
+
+func (r *fakeEmbeddingRepo) FindByRoomID(roomID, provider string, limit int) ([]model.EmbeddingChunk, error) {
+	var found []model.EmbeddingChunk
+	for i := len(r.chunks) - 1; i >= 0 && len(found) < limit; i-- {
+		if chunk := r.chunks[i]; chunk.RoomID == roomID && chunk.Provider == provider {
+			found = append(found, chunk)
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeEmbeddingRepo) DeleteByRoomID(roomID string) error {
+	kept := r.chunks[:0]
+	for _, chunk := range r.chunks {
+		if chunk.RoomID != roomID {
+			kept = append(kept, chunk)
+		}
+	}
+	r.chunks = kept
+	return nil
+}
+
+func newTestRAGService(t *testing.T) (RAGService, *fakeRoomRepo) {
+	t.Helper()
+	rooms := newFakeRoomRepo()
+	rooms.rooms["room-1"] = &model.Room{ID: "room-1"}
+	rooms.rooms["room-2"] = &model.Room{ID: "room-2"}
+	rooms.access["room-1/alice"] = true
+
+	rag := NewRAGService(&fakeEmbeddingRepo{}, nil, nil, rooms, NewLocalEmbedder(256), 2)
+
+	index := []struct{ room, id, text string }{
+		{"room-1", "m1", "The marketing budget for next quarter is fifty thousand"},
+		{"room-1", "m2", "Let's move the standup to ten in the morning"},
+		{"room-1", "m3", "Who is reviewing the onboarding designs?"},
+		{"room-2", "m4", "The budget for the other team is secret"},
+	}
+	for _, msg := range index {
+		if err := rag.IndexMessage(msg.room, msg.id, "Alice", msg.text); err != nil {
+			t.Fatalf("IndexMessage(%s): %v", msg.id, err)
+		}
+	}
+	if err := rag.IndexOCR("room-1", "ocr-1", "Scanned spreadsheet: budget forecast by region"); err != nil {
+		t.Fatalf("IndexOCR: %v", err)
+	}
+	return rag, rooms
+}
+
+func TestRAGSearchRanksBySimilarity(t *testing.T) {
+	rag, _ := newTestRAGService(t)
+
+	snippets, err := rag.Search("room-1", "alice", "marketing budget", 0)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(snippets) != 2 {
+		t.Fatalf("got %d snippets, want the default top-k of 2", len(snippets))
+	}
+	if snippets[0].SourceID != "m1" || snippets[0].SourceType != model.EmbeddingSourceMessage {
+		t.Errorf("best match = %s %s, want message m1", snippets[0].SourceType, snippets[0].SourceID)
+	}
+	if snippets[1].SourceID != "ocr-1" {
+		t.Errorf("second match = %s, want ocr-1", snippets[1].SourceID)
+	}
+	for i, snippet := range snippets {
+		if snippet.Citation != i+1 {
+			t.Errorf("snippet %d has citation %d", i, snippet.Citation)
+		}
+		if snippet.SourceID == "m4" {
+			t.Error("retrieved a message from another room")
+		}
+	}
+	if snippets[0].Score < snippets[1].Score {
+		t.Errorf("snippets are not sorted by score: %v < %v", snippets[0].Score, snippets[1].Score)
+	}
+}
+
+func TestRAGSearchSkipsUnrelatedContent(t *testing.T) {
+	rag, _ := newTestRAGService(t)
+
+	snippets, err := rag.Search("room-1", "alice", "onboarding designs", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(snippets) == 0 || snippets[0].SourceID != "m3" {
+		t.Fatalf("got %+v, want m3 first", snippets)
+	}
+	for _, snippet := range snippets {
+		if snippet.Score <= 0 {
+			t.Errorf("snippet %s has non-positive score %v", snippet.SourceID, snippet.Score)
+		}
+	}
+}
+
+func TestRAGSearchChecksRoomAccess(t *testing.T) {
+	rag, _ := newTestRAGService(t)
+
+	if _, err := rag.Search("room-2", "alice", "budget", 5); !errors.Is(err, ErrRoomAccessDenied) {
+		t.Errorf("Search in a room without access: got %v, want ErrRoomAccessDenied", err)
+	}
+	if _, err := rag.Search("missing", "alice", "budget", 5); !errors.Is(err, ErrRoomNotFound) {
+		t.Errorf("Search in a missing room: got %v, want ErrRoomNotFound", err)
+	}
+}
+
+func TestRAGIndexReplacesSource(t *testing.T) {
+	rag, _ := newTestRAGService(t)
+
+	if err := rag.IndexMessage("room-1", "m1", "Alice", "Lunch is at noon"); err != nil {
+		t.Fatalf("IndexMessage: %v", err)
+	}
+	snippets, err := rag.Search("room-1", "alice", "marketing budget quarter", 5)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	for _, snippet := range snippets {
+		if snippet.SourceID == "m1" {
+			t.Errorf("edited message still matches its old text: %q", snippet.Content)
+		}
+	}
+}
+
+func TestRAGSearchScansRecentChunksOnly(t *testing.T) {
+	rag, _ := newTestRAGService(t)
+	rag.(*ragService).scanLimit = 2
+
+	// Only the OCR chunk and m3 are recent enough to be scored
+	snippets, err := rag.Search("room-1", "alice", "marketing budget", 5)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	for _, snippet := range snippets {
+		if snippet.SourceID == "m1" {
+			t.Errorf("scored m1 beyond the scan limit")
+		}
+	}
+	if len(snippets) == 0 || snippets[0].SourceID != "ocr-1" {
+		t.Errorf("got %+v, want ocr-1 first", snippets)
+	}
+}
+
+// reindexChatRepo and reindexTranscriptRepo serve a room's history
+type reindexChatRepo struct {
+	repository.ChatRepository
+	messages []model.ChatMessage
+}
+
+func (r *reindexChatRepo) FindByRoomID(roomID string, limit, offset int) ([]model.ChatMessage, error) {
+	if offset >= len(r.messages) {
+		return nil, nil
+	}
+	return r.messages[offset:min(offset+limit, len(r.messages))], nil
+}
+
+type reindexTranscriptRepo struct {
+	repository.TranscriptRepository
+	segments []model.TranscriptSegment
+}
+
+func (r *reindexTranscriptRepo) FindByRoomID(roomID string, since *time.Time, limit int) ([]model.TranscriptSegment, error) {
+	return r.segments, nil
+}
+
+func TestRAGReindexRequiresHostOrCoHost(t *testing.T) {
+	rooms := newFakeRoomRepo()
+	rooms.rooms["room-1"] = &model.Room{ID: "room-1", CreatedByID: "host"}
+	rooms.participants["room-1/cohost"] = &model.RoomParticipant{RoomID: "room-1", UserID: "cohost", Role: model.RoleCoHost}
+	rooms.participants["room-1/member"] = &model.RoomParticipant{RoomID: "room-1", UserID: "member", Role: model.RoleParticipant}
+	chat := &reindexChatRepo{messages: []model.ChatMessage{
+		{ID: "m1", RoomID: "room-1", Message: "The marketing budget is fifty thousand"},
+		{ID: "m2", RoomID: "room-1", Message: "Standup moves to ten"},
+	}}
+	transcript := &reindexTranscriptRepo{segments: []model.TranscriptSegment{
+		{ID: "t1", RoomID: "room-1", SpeakerName: "Host", Text: "Welcome everyone"},
+	}}
+	embeddings := &fakeEmbeddingRepo{}
+	rag := NewRAGService(embeddings, chat, transcript, rooms, NewLocalEmbedder(64), 5)
+
+	tests := []struct {
+		userID  string
+		wantErr error
+	}{
+		{"member", ErrRoomPermissionDenied},
+		{"stranger", ErrRoomAccessDenied},
+		{"cohost", nil},
+		{"host", nil},
+	}
+	for _, tt := range tests {
+		indexed, err := rag.ReindexRoom("room-1", tt.userID)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("ReindexRoom by %s: got %v, want %v", tt.userID, err, tt.wantErr)
+			continue
+		}
+		if err == nil && indexed != 3 {
+			t.Errorf("ReindexRoom by %s indexed %d items, want 3", tt.userID, indexed)
+		}
+	}
+	// Reindexing replaces chunks instead of adding duplicates
+	if len(embeddings.chunks) != 3 {
+		t.Errorf("stored %d chunks, want 3", len(embeddings.chunks))
+	}
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/livekit/protocol/livekit"
+)
+
+var (
+	ErrRecordingNotFound   = errors.New("recording not found")
+	ErrRecordingInProgress = errors.New("this room is already being recorded")
+	ErrRecordingNotRunning = errors.New("the recording has already stopped")
+	ErrRecordingNotReady   = errors.New("the recording file is not available yet")
+)
+
+// RecordingService records meetings with LiveKit Egress. Egress webhooks
+// keep the status and output file of each recording up to date. Only the
+// host can manage and download recordings.
+type RecordingService interface {
+	Start(roomID, userID string) (*RecordingResponse, error)
+	Stop(roomID, recordingID, userID string) (*RecordingResponse, error)
+	List(roomID, userID string) ([]RecordingResponse, error)
+	GetDownload(roomID, recordingID, userID string) (*RecordingDownload, error)
+	HandleEgress(info *livekit.EgressInfo) error
+}
+
+type recordingService struct {
+	recordingRepo  repository.RecordingRepository
+	roomRepo       repository.RoomRepository
+	liveKitService LiveKitService
+	cfg            *config.Config
+	broadcaster    Broadcaster
+}
+
+func NewRecordingService(recordingRepo repository.RecordingRepository, roomRepo repository.RoomRepository, liveKitService LiveKitService, cfg *config.Config, broadcaster Broadcaster) RecordingService {
+	return &recordingService{
+		recordingRepo:  recordingRepo,
+		roomRepo:       roomRepo,
+		liveKitService: liveKitService,
+		cfg:            cfg,
+		broadcaster:    broadcaster,
+	}
+}
+
+type RecordingResponse struct {
+	ID              string     `json:"id"`
+	RoomID          string     `json:"room_id"`
+	StartedByID     string     `json:"started_by_id"`
+	Status          string     `json:"status"`
+	DurationSeconds int64      `json:"duration_seconds"`
+	SizeBytes       int64      `json:"size_bytes"`
+	Error           string     `json:"error,omitempty"`
+	DownloadURL     string     `json:"download_url,omitempty"` // Set once the recording is complete
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// RecordingDownload is where a finished recording can be fetched from:
+// a local file on the egress storage mount, or the URL egress uploaded it to.
+type RecordingDownload struct {
+	Filename string
+	Path     string
+	URL      string
+}
+
+func (s *recordingService) Start(roomID, userID string) (*RecordingResponse, error) {
+	room, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost)
+	if err != nil {
+		return nil, err
+	}
+	if room.Status != model.RoomLive {
+		return nil, ErrRoomNotLive
+	}
+	// Cheap check first; Create makes the final decision under a lock
+	if _, err := s.recordingRepo.FindRunning(roomID); err == nil {
+		return nil, ErrRecordingInProgress
+	}
+
+	recordingID := uuid.New().String()
+	outputPath := fmt.Sprintf("recordings/%s/%s.mp4", roomID, recordingID)
+	info, err := s.liveKitService.StartRoomRecording(roomID, outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	recording := &model.Recording{
+		ID:          recordingID,
+		RoomID:      roomID,
+		EgressID:    info.EgressId,
+		StartedByID: userID,
+		Status:      model.RecordingStarting,
+		Filepath:    outputPath,
+		StartedAt:   &now,
+	}
+	applyEgressInfo(recording, info)
+	if err := s.recordingRepo.Create(recording); err != nil {
+		// Don't leave an egress running that nobody can stop
+		if _, stopErr := s.liveKitService.StopEgress(info.EgressId); stopErr != nil {
+			log.Printf("[Recording] Failed to stop untracked egress %s: %v", info.EgressId, stopErr)
+		}
+		if errors.Is(err, repository.ErrRecordingRunning) {
+			// Another start for this room won the race
+			return nil, ErrRecordingInProgress
+		}
+		return nil, errors.New("failed to save recording")
+	}
+
+	response := s.recordingToResponse(recording)
+	broadcast(s.broadcaster, roomID, userID, "recording_started", response)
+	return &response, nil
+}
+
+func (s *recordingService) Stop(roomID, recordingID, userID string) (*RecordingResponse, error) {
+	recording, err := s.findRecording(roomID, recordingID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !recording.IsRunning() {
+		return nil, ErrRecordingNotRunning
+	}
+
+	info, err := s.liveKitService.StopEgress(recording.EgressID)
+	if err != nil {
+		return nil, err
+	}
+	applyEgressInfo(recording, info)
+	if err := s.recordingRepo.Update(recording); err != nil {
+		if !errors.Is(err, repository.ErrRecordingFinished) {
+			return nil, errors.New("failed to update recording")
+		}
+		// The egress finished while stopping; report what the webhook stored
+		finished, err := s.recordingRepo.FindByID(recordingID)
+		if err != nil {
+			return nil, ErrRecordingNotFound
+		}
+		response := s.recordingToResponse(finished)
+		return &response, nil
+	}
+
+	response := s.recordingToResponse(recording)
+	broadcast(s.broadcaster, roomID, userID, "recording_stopped", response)
+	return &response, nil
+}
+
+func (s *recordingService) List(roomID, userID string) ([]RecordingResponse, error) {
+	if _, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost); err != nil {
+		return nil, err
+	}
+
+	recordings, err := s.recordingRepo.FindByRoomID(roomID)
+	if err != nil {
+		return nil, errors.New("failed to fetch recordings")
+	}
+
+	responses := make([]RecordingResponse, len(recordings))
+	for i := range recordings {
+		responses[i] = s.recordingToResponse(&recordings[i])
+	}
+	return responses, nil
+}
+
+// GetDownload locates a complete recording's file. A file under
+// RecordingsDir is preferred; otherwise the upload location is used if it
+// is an HTTP(S) URL.
+func (s *recordingService) GetDownload(roomID, recordingID, userID string) (*RecordingDownload, error) {
+	recording, err := s.findRecording(roomID, recordingID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if recording.Status != model.RecordingComplete {
+		return nil, ErrRecordingNotReady
+	}
+
+	download := &RecordingDownload{Filename: path.Base(recording.Filepath)}
+	if s.cfg.RecordingsDir != "" {
+		local := filepath.Join(s.cfg.RecordingsDir, filepath.FromSlash(recording.Filepath))
+		if info, err := os.Stat(local); err == nil && info.Mode().IsRegular() {
+			download.Path = local
+			return download, nil
+		}
+	}
+	if strings.HasPrefix(recording.Location, "https://") || strings.HasPrefix(recording.Location, "http://") {
+		download.URL = recording.Location
+		return download, nil
+	}
+	return nil, ErrRecordingNotReady
+}
+
+// HandleEgress applies an egress webhook to the matching recording.
+// Egresses not started through this service are ignored.
+func (s *recordingService) HandleEgress(info *livekit.EgressInfo) error {
+	recording, err := s.recordingRepo.FindByEgressID(info.EgressId)
+	if err != nil {
+		log.Printf("[Recording] Ignoring update for unknown egress %s", info.EgressId)
+		return nil
+	}
+
+	previous := recording.Status
+	applyEgressInfo(recording, info)
+	if err := s.recordingRepo.Update(recording); err != nil {
+		if errors.Is(err, repository.ErrRecordingFinished) {
+			log.Printf("[Recording] Ignoring %s update for finished egress %s", info.Status, info.EgressId)
+			return nil
+		}
+		return errors.New("failed to update recording")
+	}
+
+	if recording.Status != previous {
+		broadcast(s.broadcaster, recording.RoomID, "", "recording_updated", s.recordingToResponse(recording))
+	}
+	return nil
+}
+
+// findRecording checks that the user is the host and the recording belongs to the room
+func (s *recordingService) findRecording(roomID, recordingID, userID string) (*model.Recording, error) {
+	if _, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost); err != nil {
+		return nil, err
+	}
+
+	recording, err := s.recordingRepo.FindByID(recordingID)
+	if err != nil || recording.RoomID != roomID {
+		return nil, ErrRecordingNotFound
+	}
+	return recording, nil
+}
+
+func (s *recordingService) recordingToResponse(recording *model.Recording) RecordingResponse {
+	response := RecordingResponse{
+		ID:              recording.ID,
+		RoomID:          recording.RoomID,
+		StartedByID:     recording.StartedByID,
+		Status:          recording.Status,
+		DurationSeconds: recording.DurationSeconds,
+		SizeBytes:       recording.SizeBytes,
+		Error:           recording.Error,
+		StartedAt:       recording.StartedAt,
+		EndedAt:         recording.EndedAt,
+		CreatedAt:       recording.CreatedAt,
+	}
+	if recording.Status == model.RecordingComplete {
+		response.DownloadURL = fmt.Sprintf("/api/v1/rooms/%s/recordings/%s/download", recording.RoomID, recording.ID)
+	}
+	return response
+}
+
+// applyEgressInfo copies status, timing and file details from LiveKit.
+// Egress timestamps and durations are in nanoseconds.
+func applyEgressInfo(recording *model.Recording, info *livekit.EgressInfo) {
+	switch info.Status {
+	case livekit.EgressStatus_EGRESS_STARTING:
+		recording.Status = model.RecordingStarting
+	case livekit.EgressStatus_EGRESS_ACTIVE:
+		recording.Status = model.RecordingActive
+	case livekit.EgressStatus_EGRESS_ENDING:
+		recording.Status = model.RecordingEnding
+	case livekit.EgressStatus_EGRESS_COMPLETE, livekit.EgressStatus_EGRESS_LIMIT_REACHED:
+		// A recording cut off at the egress time limit still has a usable file
+		recording.Status = model.RecordingComplete
+	case livekit.EgressStatus_EGRESS_FAILED:
+		recording.Status = model.RecordingFailed
+	case livekit.EgressStatus_EGRESS_ABORTED:
+		recording.Status = model.RecordingAborted
+	}
+
+	if info.StartedAt > 0 {
+		startedAt := time.Unix(0, info.StartedAt)
+		recording.StartedAt = &startedAt
+	}
+	if info.EndedAt > 0 {
+		endedAt := time.Unix(0, info.EndedAt)
+		recording.EndedAt = &endedAt
+	}
+	recording.Error = info.Error
+
+	file := info.GetFile()
+	if len(info.FileResults) > 0 {
+		file = info.FileResults[0]
+	}
+	if file != nil {
+		recording.Location = file.Location
+		recording.SizeBytes = file.Size
+		recording.DurationSeconds = int64(time.Duration(file.Duration) / time.Second)
+	}
+}
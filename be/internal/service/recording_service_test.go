@@ -0,0 +1,296 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+
+	"github.com/livekit/protocol/livekit"
+	"google.golang.org/protobuf/encoding/protojson"
+	"gorm.io/gorm"
+)
+
+// fakeEgressServer answers twirp JSON Egress calls for egress EG_1 and
+// records the requests it received. onStop runs before StopEgress answers.
+type fakeEgressServer struct {
+	t       *testing.T
+	calls   []string
+	started *livekit.RoomCompositeEgressRequest
+	stopped *livekit.StopEgressRequest
+	onStop  func()
+}
+
+func (f *fakeEgressServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/twirp/livekit.Egress/")
+	f.calls = append(f.calls, method)
+	body, _ := io.ReadAll(r.Body)
+
+	info := &livekit.EgressInfo{EgressId: "EG_1", RoomName: "room-1"}
+	switch method {
+	case "StartRoomCompositeEgress":
+		f.started = &livekit.RoomCompositeEgressRequest{}
+		if err := protojson.Unmarshal(body, f.started); err != nil {
+			f.t.Errorf("decoding %s: %v", method, err)
+		}
+		info.Status = livekit.EgressStatus_EGRESS_STARTING
+	case "StopEgress":
+		f.stopped = &livekit.StopEgressRequest{}
+		if err := protojson.Unmarshal(body, f.stopped); err != nil {
+			f.t.Errorf("decoding %s: %v", method, err)
+		}
+		info.Status = livekit.EgressStatus_EGRESS_ENDING
+		if f.onStop != nil {
+			f.onStop()
+		}
+	default:
+		f.t.Errorf("unexpected request %s", r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+
+	out, _ := protojson.Marshal(info)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(out)
+}
+
+// fakeRecordingRepo keeps recordings in memory; createErr fails Create
+type fakeRecordingRepo struct {
+	repository.RecordingRepository
+	recordings map[string]*model.Recording
+	createErr  error
+}
+
+func (r *fakeRecordingRepo) Create(recording *model.Recording) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	copied := *recording
+	r.recordings[recording.ID] = &copied
+	return nil
+}
+
+func (r *fakeRecordingRepo) FindByID(id string) (*model.Recording, error) {
+	recording, ok := r.recordings[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *recording
+	return &copied, nil
+}
+
+func (r *fakeRecordingRepo) FindByEgressID(egressID string) (*model.Recording, error) {
+	for _, recording := range r.recordings {
+		if recording.EgressID == egressID {
+			copied := *recording
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRecordingRepo) FindRunning(roomID string) (*model.Recording, error) {
+	for _, recording := range r.recordings {
+		if recording.RoomID == roomID && recording.IsRunning() {
+			copied := *recording
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// Update mirrors the repository: finished recordings are not written
+func (r *fakeRecordingRepo) Update(recording *model.Recording) error {
+	if stored, ok := r.recordings[recording.ID]; !ok || !stored.IsRunning() {
+		return repository.ErrRecordingFinished
+	}
+	copied := *recording
+	r.recordings[recording.ID] = &copied
+	return nil
+}
+
+func newTestRecordingService(t *testing.T) (RecordingService, *fakeRecordingRepo, *fakeEgressServer) {
+	t.Helper()
+	egress := &fakeEgressServer{t: t}
+	srv := httptest.NewServer(egress)
+	t.Cleanup(srv.Close)
+
+	rooms := newFakeRoomRepo()
+	rooms.rooms["room-1"] = &model.Room{ID: "room-1", CreatedByID: "host", Status: model.RoomLive}
+	recordings := &fakeRecordingRepo{recordings: make(map[string]*model.Recording)}
+	lk := NewLiveKitService(srv.URL, testLiveKitKey, testLiveKitSecret)
+	return NewRecordingService(recordings, rooms, lk, &config.Config{}, nil), recordings, egress
+}
+
+func TestRecordingStart(t *testing.T) {
+	svc, recordings, egress := newTestRecordingService(t)
+
+	response, err := svc.Start("room-1", "host")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if egress.started == nil || egress.started.RoomName != "room-1" {
+		t.Fatalf("egress request = %+v, want a room composite egress of room-1", egress.started)
+	}
+	wantPath := "recordings/room-1/" + response.ID + ".mp4"
+	if outputs := egress.started.FileOutputs; len(outputs) != 1 || outputs[0].Filepath != wantPath {
+		t.Errorf("file outputs = %+v, want %s", outputs, wantPath)
+	}
+
+	stored := recordings.recordings[response.ID]
+	if stored == nil || stored.EgressID != "EG_1" || stored.Status != model.RecordingStarting || stored.StartedByID != "host" {
+		t.Errorf("stored recording = %+v", stored)
+	}
+
+	if _, err := svc.Start("room-1", "host"); !errors.Is(err, ErrRecordingInProgress) {
+		t.Errorf("second Start: got %v, want ErrRecordingInProgress", err)
+	}
+	if len(egress.calls) != 1 {
+		t.Errorf("egress calls = %v, want only the first start", egress.calls)
+	}
+	if _, err := svc.Start("room-1", "guest"); !errors.Is(err, ErrRoomAccessDenied) {
+		t.Errorf("Start by someone outside the room: got %v, want ErrRoomAccessDenied", err)
+	}
+}
+
+func TestRecordingStartLosingRaceStopsEgress(t *testing.T) {
+	svc, recordings, egress := newTestRecordingService(t)
+	recordings.createErr = repository.ErrRecordingRunning
+
+	if _, err := svc.Start("room-1", "host"); !errors.Is(err, ErrRecordingInProgress) {
+		t.Fatalf("Start: got %v, want ErrRecordingInProgress", err)
+	}
+	if egress.stopped == nil || egress.stopped.EgressId != "EG_1" {
+		t.Errorf("stop request = %+v, want the untracked egress EG_1 stopped", egress.stopped)
+	}
+}
+
+func TestRecordingStop(t *testing.T) {
+	svc, recordings, egress := newTestRecordingService(t)
+	recordings.recordings["rec-1"] = &model.Recording{ID: "rec-1", RoomID: "room-1", EgressID: "EG_1", Status: model.RecordingActive}
+
+	response, err := svc.Stop("room-1", "rec-1", "host")
+	if err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if egress.stopped == nil || egress.stopped.EgressId != "EG_1" {
+		t.Errorf("stop request = %+v", egress.stopped)
+	}
+	if response.Status != model.RecordingEnding || recordings.recordings["rec-1"].Status != model.RecordingEnding {
+		t.Errorf("status = %s, want ending", response.Status)
+	}
+
+	if _, err := svc.Stop("room-1", "rec-1", "host"); err != nil {
+		t.Errorf("Stop of an ending recording: %v", err)
+	}
+	recordings.recordings["rec-1"].Status = model.RecordingComplete
+	if _, err := svc.Stop("room-1", "rec-1", "host"); !errors.Is(err, ErrRecordingNotRunning) {
+		t.Errorf("Stop of a complete recording: got %v, want ErrRecordingNotRunning", err)
+	}
+	if _, err := svc.Stop("room-1", "missing", "host"); !errors.Is(err, ErrRecordingNotFound) {
+		t.Errorf("Stop of a missing recording: got %v, want ErrRecordingNotFound", err)
+	}
+}
+
+func TestRecordingHandleEgressMapsStatus(t *testing.T) {
+	tests := []struct {
+		status livekit.EgressStatus
+		want   string
+	}{
+		{livekit.EgressStatus_EGRESS_STARTING, model.RecordingStarting},
+		{livekit.EgressStatus_EGRESS_ACTIVE, model.RecordingActive},
+		{livekit.EgressStatus_EGRESS_ENDING, model.RecordingEnding},
+		{livekit.EgressStatus_EGRESS_COMPLETE, model.RecordingComplete},
+		{livekit.EgressStatus_EGRESS_LIMIT_REACHED, model.RecordingComplete},
+		{livekit.EgressStatus_EGRESS_FAILED, model.RecordingFailed},
+		{livekit.EgressStatus_EGRESS_ABORTED, model.RecordingAborted},
+	}
+	for _, tc := range tests {
+		svc, recordings, _ := newTestRecordingService(t)
+		recordings.recordings["rec-1"] = &model.Recording{ID: "rec-1", RoomID: "room-1", EgressID: "EG_1", Status: model.RecordingStarting}
+
+		if err := svc.HandleEgress(&livekit.EgressInfo{EgressId: "EG_1", Status: tc.status}); err != nil {
+			t.Fatalf("HandleEgress(%s): %v", tc.status, err)
+		}
+		if got := recordings.recordings["rec-1"].Status; got != tc.want {
+			t.Errorf("%s: status = %s, want %s", tc.status, got, tc.want)
+		}
+	}
+}
+
+func TestRecordingHandleEgressStoresFile(t *testing.T) {
+	svc, recordings, _ := newTestRecordingService(t)
+	recordings.recordings["rec-1"] = &model.Recording{ID: "rec-1", RoomID: "room-1", EgressID: "EG_1", Status: model.RecordingEnding}
+
+	ended := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	err := svc.HandleEgress(&livekit.EgressInfo{
+		EgressId: "EG_1",
+		Status:   livekit.EgressStatus_EGRESS_COMPLETE,
+		EndedAt:  ended.UnixNano(),
+		FileResults: []*livekit.FileInfo{{
+			Location: "https://storage.example.com/rec-1.mp4",
+			Size:     2048,
+			Duration: int64(90 * time.Second),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("HandleEgress: %v", err)
+	}
+
+	recording := recordings.recordings["rec-1"]
+	if recording.Location != "https://storage.example.com/rec-1.mp4" || recording.SizeBytes != 2048 || recording.DurationSeconds != 90 {
+		t.Errorf("file details = %q, %d bytes, %ds", recording.Location, recording.SizeBytes, recording.DurationSeconds)
+	}
+	if recording.EndedAt == nil || !recording.EndedAt.Equal(ended) {
+		t.Errorf("ended at %v, want %v", recording.EndedAt, ended)
+	}
+
+	if err := svc.HandleEgress(&livekit.EgressInfo{EgressId: "EG_other", Status: livekit.EgressStatus_EGRESS_COMPLETE}); err != nil {
+		t.Errorf("HandleEgress of an unknown egress: %v", err)
+	}
+}
+
+func TestRecordingHandleEgressOutOfOrder(t *testing.T) {
+	svc, recordings, _ := newTestRecordingService(t)
+	recordings.recordings["rec-1"] = &model.Recording{ID: "rec-1", RoomID: "room-1", EgressID: "EG_1", Status: model.RecordingActive}
+
+	updates := []livekit.EgressStatus{
+		livekit.EgressStatus_EGRESS_COMPLETE,
+		livekit.EgressStatus_EGRESS_ACTIVE, // egress_updated delivered after egress_ended
+		livekit.EgressStatus_EGRESS_ENDING,
+	}
+	for _, status := range updates {
+		if err := svc.HandleEgress(&livekit.EgressInfo{EgressId: "EG_1", Status: status}); err != nil {
+			t.Fatalf("HandleEgress(%s): %v", status, err)
+		}
+	}
+	if got := recordings.recordings["rec-1"].Status; got != model.RecordingComplete {
+		t.Errorf("status = %s, want the recording to stay complete", got)
+	}
+	if _, err := svc.Start("room-1", "host"); err != nil {
+		t.Errorf("Start after the recording completed: %v", err)
+	}
+}
+
+func TestRecordingStopAfterCompleteWebhook(t *testing.T) {
+	svc, recordings, egress := newTestRecordingService(t)
+	recordings.recordings["rec-1"] = &model.Recording{ID: "rec-1", RoomID: "room-1", EgressID: "EG_1", Status: model.RecordingActive}
+	// The egress_ended webhook is stored while StopEgress is in flight
+	egress.onStop = func() {
+		recordings.recordings["rec-1"].Status = model.RecordingComplete
+	}
+
+	response, err := svc.Stop("room-1", "rec-1", "host")
+	if err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if response.Status != model.RecordingComplete || recordings.recordings["rec-1"].Status != model.RecordingComplete {
+		t.Errorf("status = %s (stored %s), want complete", response.Status, recordings.recordings["rec-1"].Status)
+	}
+}
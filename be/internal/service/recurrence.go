@@ -0,0 +1,247 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"yourapp/internal/model"
+)
+
+// maxOccurrenceScan bounds how many occurrences of a series are walked
+const maxOccurrenceScan = 5000
+
+// recurrenceRule is the supported subset of an iCalendar RRULE:
+// FREQ=DAILY|WEEKLY|MONTHLY with INTERVAL, COUNT, UNTIL and BYDAY (weekly only)
+type recurrenceRule struct {
+	freq     string
+	interval int
+	count    int
+	until    *time.Time
+	byDay    []time.Weekday
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+func parseRecurrenceRule(rule string) (*recurrenceRule, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" {
+		return nil, nil
+	}
+
+	r := &recurrenceRule{interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.freq = strings.ToUpper(value)
+			if r.freq != "DAILY" && r.freq != "WEEKLY" && r.freq != "MONTHLY" {
+				return nil, errors.New("recurrence FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, errors.New("recurrence INTERVAL must be a positive number")
+			}
+			r.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, errors.New("recurrence COUNT must be a positive number")
+			}
+			r.count = n
+		case "UNTIL":
+			until, err := parseICalTime(value)
+			if err != nil {
+				return nil, errors.New("recurrence UNTIL must look like 20060102T150405Z")
+			}
+			r.until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := rruleWeekdays[strings.ToUpper(day)]
+				if !ok {
+					return nil, fmt.Errorf("unsupported BYDAY value %q", day)
+				}
+				r.byDay = append(r.byDay, weekday)
+			}
+		case "WKST":
+			// Weeks always start on Monday
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part %q", key)
+		}
+	}
+
+	if r.freq == "" {
+		return nil, errors.New("recurrence rule requires FREQ")
+	}
+	if len(r.byDay) > 0 && r.freq != "WEEKLY" {
+		return nil, errors.New("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if r.count > 0 && r.until != nil {
+		return nil, errors.New("recurrence rule cannot have both COUNT and UNTIL")
+	}
+	sort.Slice(r.byDay, func(i, j int) bool {
+		return weekdayIndex(r.byDay[i]) < weekdayIndex(r.byDay[j])
+	})
+	return r, nil
+}
+
+func parseICalTime(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid time")
+}
+
+// weekdayIndex orders weekdays Monday first
+func weekdayIndex(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
+
+// each calls fn with every occurrence start of the series, in order, until fn
+// returns false. Dates are computed in loc so the wall-clock time is kept
+// across daylight saving changes.
+func (r *recurrenceRule) each(start time.Time, loc *time.Location, fn func(time.Time) bool) {
+	start = start.In(loc)
+	emitted := 0
+	emit := func(t time.Time) bool {
+		if r.until != nil && t.After(*r.until) {
+			return false
+		}
+		if r.count > 0 && emitted >= r.count {
+			return false
+		}
+		emitted++
+		return fn(t)
+	}
+
+	for i := 0; i < maxOccurrenceScan; i++ {
+		switch r.freq {
+		case "DAILY":
+			if !emit(start.AddDate(0, 0, i*r.interval)) {
+				return
+			}
+		case "WEEKLY":
+			if len(r.byDay) == 0 {
+				if !emit(start.AddDate(0, 0, 7*i*r.interval)) {
+					return
+				}
+				continue
+			}
+			weekStart := start.AddDate(0, 0, -weekdayIndex(start.Weekday())+7*i*r.interval)
+			for _, day := range r.byDay {
+				t := weekStart.AddDate(0, 0, weekdayIndex(day))
+				if t.Before(start) {
+					continue
+				}
+				if !emit(t) {
+					return
+				}
+			}
+		case "MONTHLY":
+			t := start.AddDate(0, i*r.interval, 0)
+			if t.Day() != start.Day() {
+				// e.g. the 31st in a 30-day month is skipped
+				continue
+			}
+			if !emit(t) {
+				return
+			}
+		}
+	}
+}
+
+// roomLocation returns the room's time zone, defaulting to UTC
+func roomLocation(room *model.Room) *time.Location {
+	if room.Timezone != "" {
+		if loc, err := time.LoadLocation(room.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// meetingOccurrence returns the occurrence of a scheduled room that is in
+// progress at t, or the next one after t. ok is false for unscheduled rooms
+// and series that are over.
+func meetingOccurrence(room *model.Room, t time.Time) (start, end time.Time, ok bool) {
+	if room.ScheduledStart == nil || room.ScheduledEnd == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	duration := room.ScheduledEnd.Sub(*room.ScheduledStart)
+
+	rule, err := parseRecurrenceRule(room.RecurrenceRule)
+	if err != nil || rule == nil {
+		if room.ScheduledEnd.Before(t) {
+			return time.Time{}, time.Time{}, false
+		}
+		return *room.ScheduledStart, *room.ScheduledEnd, true
+	}
+
+	rule.each(*room.ScheduledStart, roomLocation(room), func(occurrence time.Time) bool {
+		if occurrence.Add(duration).Before(t) {
+			return true
+		}
+		start, end, ok = occurrence, occurrence.Add(duration), true
+		return false
+	})
+	return start, end, ok
+}
+
+// validateSchedule checks the schedule fields of a room request
+func validateSchedule(start, end *time.Time, timezone, rule string) error {
+	if start == nil && end == nil {
+		if rule != "" {
+			return errors.New("recurrence_rule requires scheduled_start and scheduled_end")
+		}
+		return nil
+	}
+	if start == nil || end == nil {
+		return errors.New("scheduled_start and scheduled_end must be set together")
+	}
+	if !end.After(*start) {
+		return errors.New("scheduled_end must be after scheduled_start")
+	}
+	if end.Sub(*start) > 24*time.Hour {
+		return errors.New("a meeting cannot be longer than 24 hours")
+	}
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", timezone)
+		}
+	}
+
+	parsed, err := parseRecurrenceRule(rule)
+	if err != nil {
+		return err
+	}
+	if parsed != nil && len(parsed.byDay) > 0 {
+		loc := time.UTC
+		if timezone != "" {
+			loc, _ = time.LoadLocation(timezone)
+		}
+		weekday := start.In(loc).Weekday()
+		for _, day := range parsed.byDay {
+			if day == weekday {
+				return nil
+			}
+		}
+		return errors.New("BYDAY must include the weekday of scheduled_start")
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"yourapp/internal/model"
+)
+
+func scheduledRoom(start time.Time, length time.Duration, timezone, rule string) *model.Room {
+	end := start.Add(length)
+	return &model.Room{ScheduledStart: &start, ScheduledEnd: &end, Timezone: timezone, RecurrenceRule: rule}
+}
+
+func TestMeetingOccurrence(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC) // a Monday
+	tests := []struct {
+		name   string
+		rule   string
+		at     time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{"one-off before it starts", "", start.Add(-24 * time.Hour), start, true},
+		{"one-off in progress", "", start.Add(30 * time.Minute), start, true},
+		{"one-off over", "", start.Add(2 * time.Hour), time.Time{}, false},
+		{"daily in progress", "FREQ=DAILY", start.AddDate(0, 0, 3).Add(10 * time.Minute), start.AddDate(0, 0, 3), true},
+		{"daily between occurrences", "FREQ=DAILY;INTERVAL=2", start.AddDate(0, 0, 1), start.AddDate(0, 0, 2), true},
+		{"weekly by day", "FREQ=WEEKLY;BYDAY=MO,WE", start.Add(2 * time.Hour), start.AddDate(0, 0, 2), true},
+		{"weekly by day wraps to next week", "FREQ=WEEKLY;BYDAY=MO,WE", start.AddDate(0, 0, 3), start.AddDate(0, 0, 7), true},
+		{"count exhausted", "FREQ=DAILY;COUNT=3", start.AddDate(0, 0, 3), time.Time{}, false},
+		{"until exhausted", "FREQ=WEEKLY;UNTIL=20260310T000000Z", start.AddDate(0, 0, 8), time.Time{}, false},
+		{"monthly", "FREQ=MONTHLY", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 2, 9, 0, 0, 0, time.UTC), true},
+	}
+	for _, tc := range tests {
+		got, end, ok := meetingOccurrence(scheduledRoom(start, time.Hour, "", tc.rule), tc.at)
+		if ok != tc.wantOK || !got.Equal(tc.want) {
+			t.Errorf("%s: got %v (ok %v), want %v (ok %v)", tc.name, got, ok, tc.want, tc.wantOK)
+			continue
+		}
+		if ok && !end.Equal(got.Add(time.Hour)) {
+			t.Errorf("%s: end %v, want an hour after %v", tc.name, end, got)
+		}
+	}
+}
+
+func TestMeetingOccurrenceMonthlyOn31st(t *testing.T) {
+	start := time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC)
+	got, _, ok := meetingOccurrence(scheduledRoom(start, time.Hour, "", "FREQ=MONTHLY"), start.Add(24*time.Hour))
+	if want := time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC); !ok || !got.Equal(want) {
+		t.Errorf("got %v, want %v (February has no 31st)", got, want)
+	}
+}
+
+func TestMeetingOccurrenceKeepsWallClockAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, loc) // EST, before the March 8 change
+	got, _, ok := meetingOccurrence(scheduledRoom(start, time.Hour, "America/New_York", "FREQ=WEEKLY"), start.AddDate(0, 0, 2))
+	if !ok {
+		t.Fatal("no occurrence")
+	}
+	if local := got.In(loc); local.Hour() != 9 || local.Day() != 9 {
+		t.Errorf("next occurrence at %v, want 09:00 local on March 9", local)
+	}
+}
+
+func TestParseRecurrenceRuleRejects(t *testing.T) {
+	for _, rule := range []string{
+		"FREQ=YEARLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=DAILY;COUNT=2;UNTIL=20260310T000000Z",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;BYHOUR=9",
+	} {
+		if _, err := parseRecurrenceRule(rule); err == nil {
+			t.Errorf("parseRecurrenceRule(%q) accepted", rule)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+var (
+	ErrRoomNotFound         = errors.New("room not found")
+	ErrRoomAccessDenied     = errors.New("not authorized to access this room")
+	ErrRoomPermissionDenied = errors.New("your role in this room does not allow this action")
+	ErrParticipantNotFound  = errors.New("participant not found in this room")
+	ErrRemovedFromRoom      = errors.New("you were removed from this meeting by a moderator")
+)
+
+// ensureRoomAccess checks that the room exists and that the user created it
+// or has joined it at some point
+func ensureRoomAccess(roomRepo repository.RoomRepository, roomID, userID string) error {
+	if _, err := roomRepo.FindByID(roomID); err != nil {
+		return ErrRoomNotFound
+	}
+
+	ok, err := roomRepo.HasAccess(roomID, userID)
+	if err != nil {
+		return errors.New("failed to verify room access")
+	}
+	if !ok {
+		return ErrRoomAccessDenied
+	}
+	return nil
+}
+
+// wasRemoved reports whether a moderator removed the user from the room and
+// no host has let them back in since
+func wasRemoved(roomRepo repository.RoomRepository, roomID, userID string) bool {
+	participant, err := roomRepo.FindParticipant(roomID, userID)
+	return err == nil && participant.RemovedByID != nil
+}
+
+// roomRole returns the user's role in the room. The creator is always the
+// host; users who never joined or were removed by a moderator have no role.
+func roomRole(roomRepo repository.RoomRepository, room *model.Room, userID string) string {
+	if room.CreatedByID == userID {
+		return model.RoleHost
+	}
+	participant, err := roomRepo.FindParticipant(room.ID, userID)
+	if err != nil || participant.RemovedByID != nil {
+		return ""
+	}
+	return participant.Role
+}
+
+// ensureRoomRole checks that the room exists and that the user holds one of
+// the allowed roles in it
+func ensureRoomRole(roomRepo repository.RoomRepository, roomID, userID string, allowed ...string) (*model.Room, error) {
+	room, err := roomRepo.FindByID(roomID)
+	if err != nil {
+		return nil, ErrRoomNotFound
+	}
+
+	role := roomRole(roomRepo, room, userID)
+	if role == "" {
+		return nil, ErrRoomAccessDenied
+	}
+	for _, r := range allowed {
+		if r == role {
+			return room, nil
+		}
+	}
+	return nil, ErrRoomPermissionDenied
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"yourapp/internal/model"
+)
+
+func TestEnsureRoomRoleRefusesRemovedCoHost(t *testing.T) {
+	rooms := newFakeRoomRepo()
+	rooms.rooms["room-1"] = &model.Room{ID: "room-1", CreatedByID: "host"}
+	moderator := "host"
+	rooms.participants["room-1/cohost"] = &model.RoomParticipant{RoomID: "room-1", UserID: "cohost", Role: model.RoleCoHost}
+	rooms.participants["room-1/removed"] = &model.RoomParticipant{RoomID: "room-1", UserID: "removed", Role: model.RoleCoHost, RemovedByID: &moderator}
+	rooms.access["room-1/removed"] = true
+
+	if _, err := ensureRoomRole(rooms, "room-1", "cohost", model.RoleHost, model.RoleCoHost); err != nil {
+		t.Errorf("co-host: %v", err)
+	}
+	if _, err := ensureRoomRole(rooms, "room-1", "host", model.RoleHost); err != nil {
+		t.Errorf("creator: %v", err)
+	}
+	if _, err := ensureRoomRole(rooms, "room-1", "removed", model.RoleHost, model.RoleCoHost); !errors.Is(err, ErrRoomAccessDenied) {
+		t.Errorf("removed co-host: got %v, want ErrRoomAccessDenied", err)
+	}
+	if err := ensureRoomAccess(rooms, "room-1", "removed"); !errors.Is(err, ErrRoomAccessDenied) {
+		t.Errorf("removed co-host reading the room: got %v, want ErrRoomAccessDenied", err)
+	}
+}
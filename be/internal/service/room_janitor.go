@@ -0,0 +1,164 @@
+package service
+
+import (
+	"log"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+// RoomCleanupResult counts what a single janitor sweep did
+type RoomCleanupResult struct {
+	Ended    int
+	Archived int
+	Deleted  int
+	Purged   int
+}
+
+// RoomJanitor periodically moves rooms along their lifecycle: it ends live
+// rooms that stayed empty for the idle grace period and scheduled rooms whose
+// meetings are over, archives (or deletes) rooms that have been ended for a
+// while, and purges archived rooms with all their data after the retention
+// period. Deleting an ended room purges it right away instead of archiving it. The clock is injectable so sweeps can be run at any point in time.
+type RoomJanitor struct {
+	roomRepo     repository.RoomRepository
+	broadcaster  Broadcaster
+	idleGrace    time.Duration
+	archiveAfter time.Duration
+	deleteEnded  bool
+	retention    time.Duration
+	interval     time.Duration
+	now          func() time.Time
+	stop         chan struct{}
+}
+
+// NewRoomJanitor creates a janitor using the room cleanup settings. now
+// defaults to time.Now.
+func NewRoomJanitor(roomRepo repository.RoomRepository, broadcaster Broadcaster, cfg *config.Config, now func() time.Time) *RoomJanitor {
+	if now == nil {
+		now = time.Now
+	}
+	j := &RoomJanitor{
+		roomRepo:     roomRepo,
+		broadcaster:  broadcaster,
+		idleGrace:    cfg.RoomIdleGrace,
+		archiveAfter: cfg.RoomArchiveAfter,
+		retention:    cfg.RoomRetention,
+		interval:     cfg.RoomJanitorInterval,
+		now:          now,
+		stop:         make(chan struct{}),
+	}
+	switch cfg.RoomCleanupAction {
+	case "", "archive":
+	case "delete":
+		j.deleteEnded = true
+	default:
+		log.Printf("[RoomJanitor] Unknown ROOM_CLEANUP_ACTION %q, archiving ended rooms", cfg.RoomCleanupAction)
+	}
+	if j.interval <= 0 {
+		j.interval = time.Minute
+	}
+	return j
+}
+
+// Start runs the cleanup loop in a goroutine until Stop is called
+func (j *RoomJanitor) Start() {
+	log.Printf("Room janitor started, checking every %v", j.interval)
+
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				result := j.Sweep(j.now())
+				if result != (RoomCleanupResult{}) {
+					log.Printf("[RoomJanitor] Ended %d, archived %d, deleted %d and purged %d rooms",
+						result.Ended, result.Archived, result.Deleted, result.Purged)
+				}
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the cleanup loop
+func (j *RoomJanitor) Stop() {
+	close(j.stop)
+}
+
+// Sweep runs one cleanup pass as of now
+func (j *RoomJanitor) Sweep(now time.Time) RoomCleanupResult {
+	var result RoomCleanupResult
+	idleSince := now.Add(-j.idleGrace)
+
+	// Live rooms nobody has been in for the grace period
+	idle, err := j.roomRepo.FindIdle(idleSince)
+	if err != nil {
+		log.Printf("[RoomJanitor] Failed to fetch idle rooms: %v", err)
+	}
+	for i := range idle {
+		if j.transition(&idle[i], endedStatus(&idle[i], now), now) {
+			result.Ended++
+		}
+	}
+
+	// Scheduled rooms whose last occurrence is over
+	scheduled, err := j.roomRepo.FindByStatusBefore(model.RoomScheduled, idleSince)
+	if err != nil {
+		log.Printf("[RoomJanitor] Failed to fetch scheduled rooms: %v", err)
+	}
+	for i := range scheduled {
+		if _, _, ok := meetingOccurrence(&scheduled[i], idleSince); ok {
+			continue
+		}
+		if j.transition(&scheduled[i], model.RoomEnded, now) {
+			result.Ended++
+		}
+	}
+
+	ended, err := j.roomRepo.FindByStatusBefore(model.RoomEnded, now.Add(-j.archiveAfter))
+	if err != nil {
+		log.Printf("[RoomJanitor] Failed to fetch ended rooms: %v", err)
+	}
+	for i := range ended {
+		if !j.deleteEnded {
+			if j.transition(&ended[i], model.RoomArchived, now) {
+				result.Archived++
+			}
+			continue
+		}
+		if err := j.roomRepo.Purge(ended[i].ID); err != nil {
+			log.Printf("[RoomJanitor] Failed to delete room %s: %v", ended[i].ID, err)
+			continue
+		}
+		result.Deleted++
+	}
+
+	archived, err := j.roomRepo.FindByStatusBefore(model.RoomArchived, now.Add(-j.retention))
+	if err != nil {
+		log.Printf("[RoomJanitor] Failed to fetch archived rooms: %v", err)
+	}
+	for _, room := range archived {
+		if err := j.roomRepo.Purge(room.ID); err != nil {
+			log.Printf("[RoomJanitor] Failed to purge room %s: %v", room.ID, err)
+			continue
+		}
+		result.Purged++
+	}
+
+	return result
+}
+
+// transition applies a janitor status change. Rooms someone else changed in
+// the meantime are skipped.
+func (j *RoomJanitor) transition(room *model.Room, to string, now time.Time) bool {
+	if err := transitionRoom(j.roomRepo, j.broadcaster, room, to, "", now); err != nil {
+		log.Printf("[RoomJanitor] Room %s not moved to %s: %v", room.ID, to, err)
+		return false
+	}
+	return true
+}
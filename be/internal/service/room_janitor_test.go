@@ -0,0 +1,153 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/model"
+)
+
+// janitorRoomRepo runs the janitor queries against the in-memory rooms
+type janitorRoomRepo struct {
+	*fakeRoomRepo
+	occupied map[string]bool
+	purged   []string
+}
+
+func (r *janitorRoomRepo) FindIdle(emptySince time.Time) ([]model.Room, error) {
+	var idle []model.Room
+	for _, room := range r.rooms {
+		if room.Status == model.RoomLive && room.StatusChangedAt.Before(emptySince) && !r.occupied[room.ID] {
+			idle = append(idle, *room)
+		}
+	}
+	return idle, nil
+}
+
+func (r *janitorRoomRepo) FindByStatusBefore(status string, before time.Time) ([]model.Room, error) {
+	var found []model.Room
+	for _, room := range r.rooms {
+		if room.Status == status && room.StatusChangedAt.Before(before) {
+			found = append(found, *room)
+		}
+	}
+	return found, nil
+}
+
+func (r *janitorRoomRepo) UpdateStatus(roomID, from, to string, at time.Time) (bool, error) {
+	room, ok := r.rooms[roomID]
+	if !ok || room.Status != from {
+		return false, nil
+	}
+	room.Status = to
+	room.StatusChangedAt = at
+	return true, nil
+}
+
+func (r *janitorRoomRepo) Purge(roomID string) error {
+	delete(r.rooms, roomID)
+	r.purged = append(r.purged, roomID)
+	return nil
+}
+
+func newTestJanitor(action string, clock *time.Time) (*RoomJanitor, *janitorRoomRepo) {
+	rooms := &janitorRoomRepo{fakeRoomRepo: newFakeRoomRepo(), occupied: make(map[string]bool)}
+	cfg := &config.Config{
+		RoomIdleGrace:     10 * time.Minute,
+		RoomArchiveAfter:  time.Hour,
+		RoomRetention:     24 * time.Hour,
+		RoomCleanupAction: action,
+	}
+	return NewRoomJanitor(rooms, nil, cfg, func() time.Time { return *clock }), rooms
+}
+
+func TestRoomJanitorMovesRoomsThroughTheirLifecycle(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	clock := start
+	janitor, rooms := newTestJanitor("archive", &clock)
+	rooms.rooms["idle"] = &model.Room{ID: "idle", Status: model.RoomLive, StatusChangedAt: start}
+	rooms.rooms["busy"] = &model.Room{ID: "busy", Status: model.RoomLive, StatusChangedAt: start}
+	rooms.occupied["busy"] = true
+
+	steps := []struct {
+		after  time.Duration
+		want   RoomCleanupResult
+		status string
+	}{
+		{9 * time.Minute, RoomCleanupResult{}, model.RoomLive},
+		{11 * time.Minute, RoomCleanupResult{Ended: 1}, model.RoomEnded},
+		{11*time.Minute + 59*time.Minute, RoomCleanupResult{}, model.RoomEnded},
+		{11*time.Minute + 61*time.Minute, RoomCleanupResult{Archived: 1}, model.RoomArchived},
+		{72*time.Minute + 23*time.Hour, RoomCleanupResult{}, model.RoomArchived},
+		{72*time.Minute + 25*time.Hour, RoomCleanupResult{Purged: 1}, ""},
+	}
+	for _, step := range steps {
+		clock = start.Add(step.after)
+		if got := janitor.Sweep(janitor.now()); got != step.want {
+			t.Errorf("sweep at +%v = %+v, want %+v", step.after, got, step.want)
+		}
+		status := ""
+		if room, ok := rooms.rooms["idle"]; ok {
+			status = room.Status
+		}
+		if status != step.status {
+			t.Errorf("at +%v the idle room is %q, want %q", step.after, status, step.status)
+		}
+	}
+
+	if rooms.rooms["busy"].Status != model.RoomLive {
+		t.Errorf("occupied room is %s, want it to stay live", rooms.rooms["busy"].Status)
+	}
+	if len(rooms.purged) != 1 || rooms.purged[0] != "idle" {
+		t.Errorf("purged %v, want [idle]", rooms.purged)
+	}
+}
+
+func TestRoomJanitorEndsScheduledRoomsAfterTheirMeeting(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	clock := start
+	janitor, rooms := newTestJanitor("archive", &clock)
+	meetingStart, meetingEnd := start.Add(time.Hour), start.Add(2*time.Hour)
+	rooms.rooms["meeting"] = &model.Room{
+		ID:              "meeting",
+		Status:          model.RoomScheduled,
+		StatusChangedAt: start.Add(-24 * time.Hour),
+		ScheduledStart:  &meetingStart,
+		ScheduledEnd:    &meetingEnd,
+	}
+
+	for _, after := range []time.Duration{0, 2 * time.Hour, 2*time.Hour + 9*time.Minute} {
+		clock = start.Add(after)
+		if got := janitor.Sweep(janitor.now()); got != (RoomCleanupResult{}) {
+			t.Errorf("sweep at +%v = %+v, want nothing before the meeting is over", after, got)
+		}
+	}
+
+	clock = start.Add(2*time.Hour + 11*time.Minute)
+	if got := janitor.Sweep(janitor.now()); got != (RoomCleanupResult{Ended: 1}) {
+		t.Errorf("sweep after the meeting = %+v, want one room ended", got)
+	}
+	if status := rooms.rooms["meeting"].Status; status != model.RoomEnded {
+		t.Errorf("meeting room is %s, want ended", status)
+	}
+}
+
+func TestRoomJanitorDeletesEndedRooms(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	clock := start
+	janitor, rooms := newTestJanitor("delete", &clock)
+	rooms.rooms["ended"] = &model.Room{ID: "ended", Status: model.RoomEnded, StatusChangedAt: start}
+
+	clock = start.Add(59 * time.Minute)
+	if got := janitor.Sweep(janitor.now()); got != (RoomCleanupResult{}) {
+		t.Errorf("sweep before archive_after = %+v, want nothing", got)
+	}
+	clock = start.Add(61 * time.Minute)
+	if got := janitor.Sweep(janitor.now()); got != (RoomCleanupResult{Deleted: 1}) {
+		t.Errorf("sweep after archive_after = %+v, want one room deleted", got)
+	}
+	// A soft delete would leave the messages, notes and transcripts behind
+	if len(rooms.purged) != 1 || rooms.purged[0] != "ended" {
+		t.Errorf("purged %v, want [ended]", rooms.purged)
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+var (
+	ErrRoomClosed            = errors.New("this room has ended")
+	ErrInvalidRoomTransition = errors.New("invalid room status change")
+	ErrRoomNotLive           = errors.New("the meeting is not live")
+)
+
+// roomTransitions lists the lifecycle states each state can move to.
+// Live rooms go back to scheduled between occurrences of a recurring meeting.
+var roomTransitions = map[string][]string{
+	model.RoomScheduled: {model.RoomLive, model.RoomEnded},
+	model.RoomLive:      {model.RoomScheduled, model.RoomEnded},
+	model.RoomEnded:     {model.RoomScheduled, model.RoomLive, model.RoomArchived},
+	model.RoomArchived:  {model.RoomEnded},
+}
+
+// validateRoomTransition checks that the room may move to the given state at now
+func validateRoomTransition(room *model.Room, to string, now time.Time) error {
+	if _, ok := roomTransitions[to]; !ok {
+		return errors.New("status must be scheduled, live, ended or archived")
+	}
+	if to == model.RoomScheduled {
+		if _, _, ok := meetingOccurrence(room, now); !ok {
+			return fmt.Errorf("%w: the room has no upcoming occurrence", ErrInvalidRoomTransition)
+		}
+	}
+	for _, allowed := range roomTransitions[room.Status] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s rooms cannot become %s", ErrInvalidRoomTransition, room.Status, to)
+}
+
+// transitionRoom validates and stores a lifecycle change and tells connected
+// clients about it. The update only applies if nobody changed the status in
+// the meantime.
+func transitionRoom(roomRepo repository.RoomRepository, b Broadcaster, room *model.Room, to, userID string, now time.Time) error {
+	if room.Status == to {
+		return nil
+	}
+	if err := validateRoomTransition(room, to, now); err != nil {
+		return err
+	}
+
+	ok, err := roomRepo.UpdateStatus(room.ID, room.Status, to, now)
+	if err != nil {
+		return errors.New("failed to update room status")
+	}
+	if !ok {
+		return fmt.Errorf("%w: the room status was changed by someone else", ErrInvalidRoomTransition)
+	}
+
+	previous := room.Status
+	room.Status = to
+	room.StatusChangedAt = now
+	broadcast(b, room.ID, userID, "room_status_changed", map[string]interface{}{
+		"room_id":         room.ID,
+		"status":          to,
+		"previous_status": previous,
+	})
+	return nil
+}
+
+// endedStatus is the state a room moves to when its meeting ends. Recurring
+// meetings with occurrences left go back to scheduled.
+func endedStatus(room *model.Room, now time.Time) string {
+	if room.RecurrenceRule != "" {
+		if _, _, ok := meetingOccurrence(room, now); ok {
+			return model.RoomScheduled
+		}
+	}
+	return model.RoomEnded
+}
+
+// closeMeeting disconnects everyone from the LiveKit room and closes their
+// participant sessions
+func closeMeeting(liveKitService LiveKitService, roomRepo repository.RoomRepository, roomID string) error {
+	if err := liveKitService.DeleteRoom(roomID); err != nil && !errors.Is(err, ErrLiveKitNotFound) {
+		return err
+	}
+	if err := roomRepo.DeactivateParticipants(roomID, time.Now()); err != nil {
+		return errors.New("failed to update participant state")
+	}
+	return nil
+}
@@ -2,61 +2,194 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 	"yourapp/internal/config"
 	"yourapp/internal/model"
 	"yourapp/internal/repository"
+	"yourapp/internal/util"
 
+	"github.com/google/uuid"
 	"github.com/livekit/protocol/auth"
 )
 
+var (
+	ErrMeetingNotStarted = errors.New("the meeting has not started yet")
+	ErrMeetingEnded      = errors.New("the meeting has ended")
+	ErrRoomFull          = errors.New("room is full")
+	ErrPasscodeRequired  = errors.New("this room requires a passcode")
+	ErrInvalidPasscode   = errors.New("incorrect passcode")
+	ErrInviteRequired    = errors.New("this room is private; an invitation or invite link is required")
+)
+
 type RoomService interface {
 	CreateRoom(req CreateRoomRequest) (*RoomResponse, error)
 	CreateRoomWithUser(req CreateRoomRequest, userID string) (*RoomResponse, error)
 	GetRoomByID(roomID string) (*RoomResponse, error)
+	GetRoom(roomID, userID string) (*RoomResponse, error)
 	GetRoomsByUser(userID string) ([]RoomResponse, error)
-	GetAllRooms() ([]RoomResponse, error)
-	JoinRoom(roomID, userID string) (*JoinRoomResponse, error)
+	ListRooms(query ListRoomsQuery, viewerID string) (*RoomListResponse, error)
+	UpdateRoom(roomID, userID string, req UpdateRoomRequest) (*RoomResponse, error)
+	JoinRoom(roomID, userID string, req JoinRoomRequest) (*JoinRoomResponse, error)
 	LeaveRoom(roomID, userID string) error
 	DeleteRoom(roomID, userID string) error
+	GetParticipants(roomID, userID string) ([]ParticipantResponse, error)
+	UpdateParticipantRole(roomID, hostID, targetUserID, role string) (*ParticipantResponse, error)
+	IssueRecorderToken(roomID, userID string) (*RecorderTokenResponse, error)
+	EnsureSocketAccess(roomID, userID string) error
 }
 
 type roomService struct {
-	roomRepo repository.RoomRepository
-	userRepo repository.UserRepository
-	cfg      *config.Config
+	roomRepo          repository.RoomRepository
+	userRepo          repository.UserRepository
+	cfg               *config.Config
+	broadcaster       Broadcaster
+	scheduleService   ScheduleService
+	lobbyService      LobbyService
+	inviteLinkService InviteLinkService
+	liveKitService    LiveKitService
 }
 
-func NewRoomService(roomRepo repository.RoomRepository, userRepo repository.UserRepository, cfg *config.Config) RoomService {
+func NewRoomService(roomRepo repository.RoomRepository, userRepo repository.UserRepository, cfg *config.Config, broadcaster Broadcaster, scheduleService ScheduleService, lobbyService LobbyService, inviteLinkService InviteLinkService, liveKitService LiveKitService) RoomService {
 	return &roomService{
-		roomRepo: roomRepo,
-		userRepo: userRepo,
-		cfg:      cfg,
+		roomRepo:          roomRepo,
+		userRepo:          userRepo,
+		cfg:               cfg,
+		broadcaster:       broadcaster,
+		scheduleService:   scheduleService,
+		lobbyService:      lobbyService,
+		inviteLinkService: inviteLinkService,
+		liveKitService:    liveKitService,
 	}
 }
 
 type CreateRoomRequest struct {
-	Name            string  `json:"name" binding:"required"`
-	Description     *string `json:"description"`
-	MaxParticipants *int    `json:"max_participants"`
+	Name            string     `json:"name" binding:"required"`
+	Description     *string    `json:"description"`
+	MaxParticipants *int       `json:"max_participants"`
+	LobbyEnabled    bool       `json:"lobby_enabled"`   // Hold joiners in a waiting room until admitted
+	Visibility      string     `json:"visibility"`      // public (default), unlisted or private
+	Passcode        string     `json:"passcode"`        // Optional, required from joiners without an invitation
+	ScheduledStart  *time.Time `json:"scheduled_start"` // Leave empty for an instant meeting
+	ScheduledEnd    *time.Time `json:"scheduled_end"`
+	Timezone        string     `json:"timezone"`        // IANA name, e.g. Asia/Jakarta
+	RecurrenceRule  string     `json:"recurrence_rule"` // e.g. FREQ=WEEKLY;BYDAY=MO,WE
+	Invitees        []string   `json:"invitees"`        // Emails to send calendar invites to
 }
 
 type RoomResponse struct {
+	ID               string     `json:"id"`
+	Name             string     `json:"name"`
+	Description      *string    `json:"description,omitempty"`
+	CreatedByID      string     `json:"created_by_id"`
+	CreatedByName    string     `json:"created_by_name"`
+	Status           string     `json:"status"` // scheduled, live, ended or archived
+	MaxParticipants  *int       `json:"max_participants,omitempty"`
+	LobbyEnabled     bool       `json:"lobby_enabled"`
+	Visibility       string     `json:"visibility"`
+	HasPasscode      bool       `json:"has_passcode"`
+	ParticipantCount int64      `json:"participant_count"`
+	ScheduledStart   *time.Time `json:"scheduled_start,omitempty"`
+	ScheduledEnd     *time.Time `json:"scheduled_end,omitempty"`
+	Timezone         string     `json:"timezone,omitempty"`
+	RecurrenceRule   string     `json:"recurrence_rule,omitempty"`
+	NextStart        *time.Time `json:"next_start,omitempty"` // Next or current occurrence of a scheduled meeting
+	NextEnd          *time.Time `json:"next_end,omitempty"`
+	ParentRoomID     *string    `json:"parent_room_id,omitempty"` // Set on breakout rooms
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// UpdateRoomRequest changes a room's details or lifecycle state. Omitted
+// fields are left unchanged.
+type UpdateRoomRequest struct {
+	Name            *string `json:"name"`
+	Description     *string `json:"description"`      // Empty clears the description
+	MaxParticipants *int    `json:"max_participants"` // 0 removes the limit
+	Status          *string `json:"status"`           // scheduled, live, ended or archived
+}
+
+// Room list paging limits
+const (
+	defaultRoomPageSize = 20
+	maxRoomPageSize     = 100
+)
+
+// ListRoomsQuery holds the query parameters of the room list. Only public
+// rooms are listed, except when the viewer lists their own rooms with
+// created_by=me. Archived rooms are left out unless asked for by status.
+type ListRoomsQuery struct {
+	Page        int    `form:"page"`
+	PageSize    int    `form:"page_size"`
+	Active      bool   `form:"active"`       // Only scheduled and live rooms
+	Status      string `form:"status"`       // Only rooms in this lifecycle state
+	CreatedBy   string `form:"created_by"`   // User ID, or "me"
+	HasCapacity bool   `form:"has_capacity"` // Only rooms with free seats
+	Search      string `form:"q"`            // Matches the room name
+	Sort        string `form:"sort"`         // newest (default) or participants
+	View        string `form:"view"`         // full (default) or summary
+}
+
+// RoomSummary is the lightweight projection returned with view=summary
+type RoomSummary struct {
 	ID               string    `json:"id"`
 	Name             string    `json:"name"`
 	Description      *string   `json:"description,omitempty"`
-	CreatedByID      string    `json:"created_by_id"`
-	CreatedByName    string    `json:"created_by_name"`
-	IsActive         bool      `json:"is_active"`
+	Status           string    `json:"status"`
 	MaxParticipants  *int      `json:"max_participants,omitempty"`
 	ParticipantCount int64     `json:"participant_count"`
 	CreatedAt        time.Time `json:"created_at"`
 }
 
+// RoomListResponse is one page of rooms. Rooms holds []RoomResponse, or
+// []RoomSummary for the summary view.
+type RoomListResponse struct {
+	Rooms    interface{} `json:"rooms"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+	HasMore  bool        `json:"has_more"`
+}
+
+// JoinRoomRequest carries the credentials for protected rooms. Both fields
+// are optional; an invite token takes precedence over the passcode.
+type JoinRoomRequest struct {
+	Passcode    string `json:"passcode"`
+	InviteToken string `json:"invite_token"`
+}
+
+// JoinRoomResponse has Status "joined" with a token, or "waiting" when the
+// user was put in the lobby; the token then arrives as a lobby_admitted event
+// on the room socket.
 type JoinRoomResponse struct {
-	Token string       `json:"token"`
-	URL   string       `json:"url"`
-	Room  RoomResponse `json:"room"`
+	Status string       `json:"status"`
+	Token  string       `json:"token,omitempty"`
+	URL    string       `json:"url,omitempty"`
+	Role   string       `json:"role,omitempty"`
+	Room   RoomResponse `json:"room"`
+}
+
+type ParticipantResponse struct {
+	UserID               string     `json:"user_id"`
+	Name                 string     `json:"name"`
+	Email                string     `json:"email"`
+	Role                 string     `json:"role"`
+	IsActive             bool       `json:"is_active"`
+	IsMuted              bool       `json:"is_muted"`
+	JoinedAt             time.Time  `json:"joined_at"`
+	LeftAt               *time.Time `json:"left_at,omitempty"`
+	TotalDurationSeconds int64      `json:"total_duration_seconds"` // Time actually connected to the media session
+}
+
+type UpdateParticipantRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type RecorderTokenResponse struct {
+	Token    string `json:"token"`
+	URL      string `json:"url"`
+	Identity string `json:"identity"`
 }
 
 func (s *roomService) CreateRoom(req CreateRoomRequest) (*RoomResponse, error) {
@@ -64,18 +197,68 @@ func (s *roomService) CreateRoom(req CreateRoomRequest) (*RoomResponse, error) {
 }
 
 func (s *roomService) CreateRoomWithUser(req CreateRoomRequest, userID string) (*RoomResponse, error) {
+	rule := strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(req.RecurrenceRule), "RRULE:"))
+	if err := validateSchedule(req.ScheduledStart, req.ScheduledEnd, req.Timezone, rule); err != nil {
+		return nil, err
+	}
+	if len(req.Invitees) > 0 {
+		if req.ScheduledStart == nil {
+			return nil, errors.New("invitees require scheduled_start and scheduled_end")
+		}
+		if _, err := normalizeEmails(req.Invitees); err != nil {
+			return nil, err
+		}
+	}
+
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = model.VisibilityPublic
+	}
+	if visibility != model.VisibilityPublic && visibility != model.VisibilityUnlisted && visibility != model.VisibilityPrivate {
+		return nil, errors.New("visibility must be public, unlisted or private")
+	}
+	passcodeHash := ""
+	if req.Passcode != "" {
+		if len(req.Passcode) < 4 || len(req.Passcode) > 64 {
+			return nil, errors.New("passcode must be between 4 and 64 characters")
+		}
+		hash, err := util.HashPassword(req.Passcode)
+		if err != nil {
+			return nil, errors.New("failed to set passcode")
+		}
+		passcodeHash = hash
+	}
+
+	status := model.RoomLive
+	if req.ScheduledStart != nil {
+		status = model.RoomScheduled
+	}
+
 	room := &model.Room{
 		Name:            req.Name,
 		Description:     req.Description,
 		MaxParticipants: req.MaxParticipants,
+		LobbyEnabled:    req.LobbyEnabled,
+		Visibility:      visibility,
+		PasscodeHash:    passcodeHash,
 		CreatedByID:     userID,
-		IsActive:        true,
+		Status:          status,
+		ScheduledStart:  req.ScheduledStart,
+		ScheduledEnd:    req.ScheduledEnd,
+		Timezone:        req.Timezone,
+		RecurrenceRule:  rule,
 	}
 
 	if err := s.roomRepo.Create(room); err != nil {
 		return nil, errors.New("failed to create room")
 	}
 
+	if len(req.Invitees) > 0 {
+		if _, err := s.scheduleService.Invite(room.ID, userID, req.Invitees); err != nil {
+			log.Printf("[Room] Failed to invite attendees to room %s: %v", room.ID, err)
+		}
+	}
+
 	return s.roomToResponse(room), nil
 }
 
@@ -92,56 +275,207 @@ func (s *roomService) GetRoomByID(roomID string) (*RoomResponse, error) {
 	return response, nil
 }
 
+// GetRoom returns a room for display. Private rooms are only visible to
+// people with access; userID is empty for anonymous requests.
+func (s *roomService) GetRoom(roomID, userID string) (*RoomResponse, error) {
+	room, err := s.roomRepo.FindByID(roomID)
+	if err != nil {
+		return nil, ErrRoomNotFound
+	}
+	if room.Visibility == model.VisibilityPrivate {
+		if userID == "" {
+			return nil, ErrRoomNotFound
+		}
+		if ok, _ := s.roomRepo.HasAccess(roomID, userID); !ok && !s.scheduleService.IsInvited(roomID, userID) {
+			return nil, ErrRoomNotFound
+		}
+	}
+	return s.GetRoomByID(roomID)
+}
+
 func (s *roomService) GetRoomsByUser(userID string) ([]RoomResponse, error) {
 	rooms, err := s.roomRepo.FindByCreatedBy(userID)
 	if err != nil {
 		return nil, errors.New("failed to fetch rooms")
 	}
 
-	responses := make([]RoomResponse, len(rooms))
-	for i, room := range rooms {
-		participantCount, _ := s.roomRepo.GetParticipantCount(room.ID)
-		responses[i] = *s.roomToResponse(&room)
-		responses[i].ParticipantCount = participantCount
+	return s.roomsToResponse(rooms), nil
+}
+
+func (s *roomService) ListRooms(query ListRoomsQuery, viewerID string) (*RoomListResponse, error) {
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = defaultRoomPageSize
+	}
+	if query.Page < 0 || query.PageSize < 0 {
+		return nil, errors.New("page and page_size must be positive")
+	}
+	if query.PageSize > maxRoomPageSize {
+		query.PageSize = maxRoomPageSize
 	}
 
-	return responses, nil
-}
+	filter := repository.RoomFilter{
+		Visibility:  model.VisibilityPublic,
+		CreatedByID: query.CreatedBy,
+		HasCapacity: query.HasCapacity,
+		Search:      strings.TrimSpace(query.Search),
+		Limit:       query.PageSize,
+		Offset:      (query.Page - 1) * query.PageSize,
+	}
+	if filter.CreatedByID == "me" {
+		if viewerID == "" {
+			return nil, errors.New("created_by=me requires authentication")
+		}
+		filter.CreatedByID = viewerID
+	}
+	if filter.CreatedByID != "" && filter.CreatedByID == viewerID {
+		filter.Visibility = ""
+	}
+
+	switch {
+	case query.Status != "":
+		if _, ok := roomTransitions[query.Status]; !ok {
+			return nil, errors.New("status must be scheduled, live, ended or archived")
+		}
+		filter.Statuses = []string{query.Status}
+	case query.Active:
+		filter.Statuses = []string{model.RoomScheduled, model.RoomLive}
+	default:
+		filter.Statuses = []string{model.RoomScheduled, model.RoomLive, model.RoomEnded}
+	}
 
-func (s *roomService) GetAllRooms() ([]RoomResponse, error) {
-	rooms, err := s.roomRepo.FindAll()
+	switch query.Sort {
+	case "", repository.RoomSortNewest, repository.RoomSortParticipants:
+		filter.Sort = query.Sort
+	default:
+		return nil, errors.New("sort must be newest or participants")
+	}
+	switch query.View {
+	case "", "full":
+	case "summary":
+		filter.Summary = true
+	default:
+		return nil, errors.New("view must be full or summary")
+	}
+
+	rooms, total, err := s.roomRepo.List(filter)
 	if err != nil {
 		return nil, errors.New("failed to fetch rooms")
 	}
 
-	responses := make([]RoomResponse, len(rooms))
-	for i, room := range rooms {
-		participantCount, _ := s.roomRepo.GetParticipantCount(room.ID)
-		responses[i] = *s.roomToResponse(&room)
-		responses[i].ParticipantCount = participantCount
+	response := &RoomListResponse{
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		HasMore:  int64(filter.Offset+len(rooms)) < total,
+	}
+	if filter.Summary {
+		response.Rooms = s.roomsToSummary(rooms)
+	} else {
+		response.Rooms = s.roomsToResponse(rooms)
 	}
+	return response, nil
+}
 
-	return responses, nil
+// UpdateRoom edits a room. Only the creator can update it; archived rooms
+// must be restored to ended before their details can change. Ending a live
+// room disconnects everyone.
+func (s *roomService) UpdateRoom(roomID, userID string, req UpdateRoomRequest) (*RoomResponse, error) {
+	room, err := s.roomRepo.FindByID(roomID)
+	if err != nil {
+		return nil, ErrRoomNotFound
+	}
+	if room.CreatedByID != userID {
+		return nil, ErrRoomPermissionDenied
+	}
+
+	detailsChanged := req.Name != nil || req.Description != nil || req.MaxParticipants != nil
+	if detailsChanged && room.Status == model.RoomArchived {
+		return nil, errors.New("archived rooms cannot be edited; restore the room first")
+	}
+	if req.Status != nil && *req.Status != room.Status {
+		if err := validateRoomTransition(room, *req.Status, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || len(name) > 255 {
+			return nil, errors.New("name must be between 1 and 255 characters")
+		}
+		room.Name = name
+	}
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		room.Description = &description
+		if description == "" {
+			room.Description = nil
+		}
+	}
+	if req.MaxParticipants != nil {
+		switch limit := *req.MaxParticipants; {
+		case limit < 0:
+			return nil, errors.New("max_participants cannot be negative")
+		case limit == 0:
+			room.MaxParticipants = nil
+		default:
+			count, err := s.roomRepo.GetParticipantCount(roomID)
+			if err != nil {
+				return nil, errors.New("failed to count participants")
+			}
+			if int64(limit) < count {
+				return nil, fmt.Errorf("max_participants cannot be lower than the %d people in the room", count)
+			}
+			room.MaxParticipants = &limit
+		}
+	}
+
+	if detailsChanged {
+		if err := s.roomRepo.UpdateDetails(room); err != nil {
+			return nil, errors.New("failed to update room")
+		}
+	}
+
+	if req.Status != nil && *req.Status != room.Status {
+		if room.Status == model.RoomLive && *req.Status != model.RoomLive {
+			if err := closeMeeting(s.liveKitService, s.roomRepo, roomID); err != nil {
+				return nil, err
+			}
+		}
+		if err := transitionRoom(s.roomRepo, s.broadcaster, room, *req.Status, userID, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := s.GetRoomByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if detailsChanged {
+		broadcast(s.broadcaster, roomID, userID, "room_updated", response)
+	}
+	return response, nil
 }
 
-func (s *roomService) JoinRoom(roomID, userID string) (*JoinRoomResponse, error) {
+func (s *roomService) JoinRoom(roomID, userID string, req JoinRoomRequest) (*JoinRoomResponse, error) {
 	// Verify room exists
 	room, err := s.roomRepo.FindByID(roomID)
 	if err != nil {
 		return nil, errors.New("room not found")
 	}
 
-	if !room.IsActive {
-		return nil, errors.New("room is not active")
-	}
-
-	// Check max participants if set
-	if room.MaxParticipants != nil {
-		count, _ := s.roomRepo.GetParticipantCount(roomID)
-		if count >= int64(*room.MaxParticipants) {
-			return nil, errors.New("room is full")
+	// The creator reopens an ended room by joining it
+	if room.Status == model.RoomEnded && room.CreatedByID == userID {
+		if err := transitionRoom(s.roomRepo, s.broadcaster, room, model.RoomLive, userID, time.Now()); err != nil {
+			return nil, err
 		}
 	}
+	if !room.IsOpen() {
+		return nil, ErrRoomClosed
+	}
 
 	// Get user for identity
 	user, err := s.userRepo.FindByID(userID)
@@ -149,43 +483,93 @@ func (s *roomService) JoinRoom(roomID, userID string) (*JoinRoomResponse, error)
 		return nil, errors.New("user not found")
 	}
 
-	// Add participant to room
-	if err := s.roomRepo.AddParticipant(roomID, userID); err != nil {
-		return nil, errors.New("failed to join room")
+	// Participants a moderator removed can only come back through the lobby
+	removed := room.CreatedByID != userID && wasRemoved(s.roomRepo, roomID, userID)
+	if removed && !room.LobbyEnabled {
+		return nil, ErrRemovedFromRoom
 	}
 
-	// Generate LiveKit token
-	at := auth.NewAccessToken(s.cfg.LiveKitAPIKey, s.cfg.LiveKitAPISecret)
-	grant := &auth.VideoGrant{
-		RoomJoin: true,
-		Room:     roomID,
+	// Hosts and co-hosts skip the access, schedule and lobby checks
+	inviteLinkID := ""
+	if role := roomRole(s.roomRepo, room, userID); role != model.RoleHost && role != model.RoleCoHost {
+		// People who joined before don't need the passcode or invite again
+		if role == "" && !removed {
+			if inviteLinkID, err = s.ensureJoinRights(room, userID, req); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := s.ensureWithinSchedule(room, time.Now()); err != nil {
+			return nil, err
+		}
+
+		if room.LobbyEnabled {
+			admitted, err := s.lobbyService.Enter(room, user, inviteLinkID)
+			if err != nil {
+				return nil, err
+			}
+			if !admitted {
+				roomResponse, _ := s.GetRoomByID(roomID)
+				return &JoinRoomResponse{
+					Status: "waiting",
+					Room:   *roomResponse,
+				}, nil
+			}
+		}
 	}
 
-	// Use user email or ID as identity
-	identity := user.Email
-	if user.Username != nil && *user.Username != "" {
-		identity = *user.Username
+	// Add participant to room; the creator joins as host, everyone else as participant.
+	// Capacity is enforced atomically by the repository.
+	role := model.RoleParticipant
+	if room.CreatedByID == userID {
+		role = model.RoleHost
+	}
+	// The invite link use is reserved first so concurrent joins can't exceed
+	// max_uses, and given back if the user doesn't get in
+	if inviteLinkID != "" {
+		if err := s.inviteLinkService.Redeem(roomID, inviteLinkID); err != nil {
+			return nil, err
+		}
 	}
+	if err := s.roomRepo.AddParticipant(roomID, userID, role); err != nil {
+		if inviteLinkID != "" {
+			s.inviteLinkService.Release(inviteLinkID)
+		}
+		if errors.Is(err, repository.ErrRoomFull) {
+			return nil, ErrRoomFull
+		}
+		return nil, errors.New("failed to join room")
+	}
+	role = roomRole(s.roomRepo, room, userID)
 
-	at.AddGrant(grant).
-		SetIdentity(identity).
-		SetValidFor(time.Hour * 24)
+	// The first person in a scheduled room starts the meeting. Someone else
+	// may have done so already, so a lost race is not an error.
+	if room.Status == model.RoomScheduled {
+		if err := transitionRoom(s.roomRepo, s.broadcaster, room, model.RoomLive, userID, time.Now()); err != nil && !errors.Is(err, ErrInvalidRoomTransition) {
+			log.Printf("[Room] Failed to start room %s: %v", roomID, err)
+		}
+	}
 
-	token, err := at.ToJWT()
+	// Generate LiveKit token with the permissions of the user's role
+	token, err := joinToken(s.cfg, roomID, role, user)
 	if err != nil {
-		return nil, errors.New("failed to generate token")
+		return nil, err
 	}
 
 	// Get room response
 	roomResponse, _ := s.GetRoomByID(roomID)
 
 	return &JoinRoomResponse{
-		Token: token,
-		URL:   s.cfg.LiveKitURL,
-		Room:  *roomResponse,
+		Status: "joined",
+		Token:  token,
+		URL:    s.cfg.LiveKitURL,
+		Role:   role,
+		Room:   *roomResponse,
 	}, nil
 }
 
+// LeaveRoom marks the user as gone. Empty rooms are left alone here; the
+// RoomJanitor ends them once they have been idle for the grace period.
 func (s *roomService) LeaveRoom(roomID, userID string) error {
 	// Verify room exists
 	_, err := s.roomRepo.FindByID(roomID)
@@ -193,27 +577,16 @@ func (s *roomService) LeaveRoom(roomID, userID string) error {
 		return errors.New("room not found")
 	}
 
+	// Users still waiting in the lobby only withdraw their request
+	if waiting, err := s.lobbyService.Leave(roomID, userID); waiting {
+		return err
+	}
+
 	// Remove participant from room
 	if err := s.roomRepo.RemoveParticipant(roomID, userID); err != nil {
 		return errors.New("failed to leave room")
 	}
 
-	// Check if room has no active participants left, then auto-delete
-	count, err := s.roomRepo.GetParticipantCount(roomID)
-	if err != nil {
-		// Continue even if count check fails
-		return nil
-	}
-
-	// Auto-delete room if no participants left
-	if count == 0 {
-		if err := s.roomRepo.Delete(roomID); err != nil {
-			// Log error but don't fail the leave operation
-			// The room will be cleaned up later
-			return nil
-		}
-	}
-
 	return nil
 }
 
@@ -231,6 +604,184 @@ func (s *roomService) DeleteRoom(roomID, userID string) error {
 	return s.roomRepo.Delete(roomID)
 }
 
+func (s *roomService) GetParticipants(roomID, userID string) ([]ParticipantResponse, error) {
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return nil, err
+	}
+	room, _ := s.roomRepo.FindByID(roomID)
+
+	participants, err := s.roomRepo.FindParticipants(roomID)
+	if err != nil {
+		return nil, errors.New("failed to fetch participants")
+	}
+
+	responses := make([]ParticipantResponse, len(participants))
+	for i := range participants {
+		responses[i] = participantToResponse(&participants[i], room)
+	}
+	return responses, nil
+}
+
+// UpdateParticipantRole lets the host promote or demote a participant. The
+// host role itself belongs to the room creator and cannot be assigned.
+func (s *roomService) UpdateParticipantRole(roomID, hostID, targetUserID, role string) (*ParticipantResponse, error) {
+	room, err := ensureRoomRole(s.roomRepo, roomID, hostID, model.RoleHost)
+	if err != nil {
+		return nil, err
+	}
+
+	if !validRole(role) || role == model.RoleHost {
+		return nil, errors.New("role must be one of co_host, participant or viewer")
+	}
+	if targetUserID == room.CreatedByID {
+		return nil, errors.New("the role of the room creator cannot be changed")
+	}
+
+	if _, err := s.roomRepo.FindParticipant(roomID, targetUserID); err != nil {
+		return nil, ErrParticipantNotFound
+	}
+	target, err := s.userRepo.FindByID(targetUserID)
+	if err != nil {
+		return nil, ErrParticipantNotFound
+	}
+
+	// Apply the new publish rights to the live session first, so a demoted
+	// participant cannot keep publishing on their old token. Room admin
+	// rights are part of the token and only change when they rejoin.
+	err = s.liveKitService.UpdateParticipant(roomID, participantIdentity(target), videoGrantForRole(roomID, role).ToPermission())
+	if err != nil && !errors.Is(err, ErrLiveKitNotFound) && !errors.Is(err, ErrLiveKitNotConfigured) {
+		log.Printf("[Room] Failed to update live permissions of %s in room %s: %v", targetUserID, roomID, err)
+		return nil, errors.New("failed to update the participant's permissions in the meeting")
+	}
+
+	if err := s.roomRepo.UpdateParticipantRole(roomID, targetUserID, role); err != nil {
+		return nil, errors.New("failed to update participant role")
+	}
+
+	var response *ParticipantResponse
+	participants, err := s.roomRepo.FindParticipants(roomID)
+	if err != nil {
+		return nil, errors.New("failed to fetch participants")
+	}
+	for i := range participants {
+		if participants[i].UserID == targetUserID {
+			p := participantToResponse(&participants[i], room)
+			response = &p
+			break
+		}
+	}
+	if response == nil {
+		return nil, ErrParticipantNotFound
+	}
+
+	// Clients rejoin on this event to pick up a token with the new permissions
+	broadcast(s.broadcaster, roomID, hostID, "participant_role_changed", map[string]interface{}{
+		"user_id":    targetUserID,
+		"role":       role,
+		"changed_by": hostID,
+	})
+
+	return response, nil
+}
+
+// IssueRecorderToken mints a token for a hidden recorder identity, used by
+// recording and transcription bots started by a host or co-host
+func (s *roomService) IssueRecorderToken(roomID, userID string) (*RecorderTokenResponse, error) {
+	if _, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost); err != nil {
+		return nil, err
+	}
+
+	identity := "recorder-" + uuid.New().String()[:8]
+	at := auth.NewAccessToken(s.cfg.LiveKitAPIKey, s.cfg.LiveKitAPISecret)
+	at.AddGrant(recorderGrant(roomID)).
+		SetIdentity(identity).
+		SetValidFor(liveKitTokenTTL(s.cfg))
+
+	token, err := at.ToJWT()
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	return &RecorderTokenResponse{
+		Token:    token,
+		URL:      s.cfg.LiveKitURL,
+		Identity: identity,
+	}, nil
+}
+
+// EnsureSocketAccess checks that the user may connect to the room's socket.
+// Private rooms are limited to people who have joined or are waiting in the
+// lobby, and participants a moderator removed stay out until readmitted.
+func (s *roomService) EnsureSocketAccess(roomID, userID string) error {
+	room, err := s.roomRepo.FindByID(roomID)
+	if err != nil {
+		return ErrRoomNotFound
+	}
+
+	// Lobby users need the socket to hear that they were admitted
+	waiting := room.LobbyEnabled && s.lobbyService.IsWaiting(roomID, userID)
+	if waiting {
+		return nil
+	}
+	if room.CreatedByID != userID && wasRemoved(s.roomRepo, roomID, userID) {
+		return ErrRemovedFromRoom
+	}
+	if room.Visibility != model.VisibilityPrivate {
+		return nil
+	}
+	return ensureRoomAccess(s.roomRepo, roomID, userID)
+}
+
+// ensureJoinRights checks the visibility and passcode of a room for a first
+// time joiner. An invite link or an email invitation grants access to private
+// and passcode-protected rooms. Returns the ID of the invite link used, whose
+// use is counted once the user is let in.
+func (s *roomService) ensureJoinRights(room *model.Room, userID string, req JoinRoomRequest) (string, error) {
+	if room.Visibility != model.VisibilityPrivate && room.PasscodeHash == "" {
+		return "", nil
+	}
+	if req.InviteToken != "" {
+		return s.inviteLinkService.Validate(room.ID, req.InviteToken)
+	}
+	if s.scheduleService.IsInvited(room.ID, userID) {
+		return "", nil
+	}
+
+	if room.Visibility == model.VisibilityPrivate {
+		return "", ErrInviteRequired
+	}
+	if req.Passcode == "" {
+		return "", ErrPasscodeRequired
+	}
+	if !util.CheckPasswordHash(req.Passcode, room.PasscodeHash) {
+		return "", ErrInvalidPasscode
+	}
+	return "", nil
+}
+
+// ensureWithinSchedule checks that a scheduled room is open for joining at
+// now: from MEETING_EARLY_JOIN before an occurrence until its end. A meeting
+// that runs over or that the host opened early stays joinable while people
+// are in it.
+func (s *roomService) ensureWithinSchedule(room *model.Room, now time.Time) error {
+	if room.ScheduledStart == nil {
+		return nil
+	}
+
+	start, _, ok := meetingOccurrence(room, now)
+	if ok && !now.Before(start.Add(-s.cfg.MeetingEarlyJoin)) {
+		return nil
+	}
+	if count, err := s.roomRepo.GetParticipantCount(room.ID); err == nil && count > 0 {
+		return nil
+	}
+
+	if !ok {
+		return ErrMeetingEnded
+	}
+	return fmt.Errorf("%w: it starts at %s", ErrMeetingNotStarted, start.Format(time.RFC3339))
+}
+
 func (s *roomService) roomToResponse(room *model.Room) *RoomResponse {
 	response := &RoomResponse{
 		ID:              room.ID,
@@ -238,14 +789,113 @@ func (s *roomService) roomToResponse(room *model.Room) *RoomResponse {
 		Description:     room.Description,
 		CreatedByID:     room.CreatedByID,
 		CreatedByName:   room.CreatedBy.FullName,
-		IsActive:        room.IsActive,
+		Status:          room.Status,
 		MaxParticipants: room.MaxParticipants,
+		LobbyEnabled:    room.LobbyEnabled,
+		Visibility:      room.Visibility,
+		HasPasscode:     room.PasscodeHash != "",
+		ScheduledStart:  room.ScheduledStart,
+		ScheduledEnd:    room.ScheduledEnd,
+		Timezone:        room.Timezone,
+		RecurrenceRule:  room.RecurrenceRule,
+		ParentRoomID:    room.ParentRoomID,
 		CreatedAt:       room.CreatedAt,
 	}
 
+	if start, end, ok := meetingOccurrence(room, time.Now()); ok {
+		response.NextStart = &start
+		response.NextEnd = &end
+	}
+
 	if room.CreatedBy.FullName == "" {
 		response.CreatedByName = room.CreatedBy.Email
 	}
 
 	return response
 }
+
+// roomsToResponse converts a room list, fetching all participant counts in one query
+func (s *roomService) roomsToResponse(rooms []model.Room) []RoomResponse {
+	ids := make([]string, len(rooms))
+	for i := range rooms {
+		ids[i] = rooms[i].ID
+	}
+	counts, err := s.roomRepo.GetParticipantCounts(ids)
+	if err != nil {
+		log.Printf("[Room] Failed to count participants: %v", err)
+	}
+
+	responses := make([]RoomResponse, len(rooms))
+	for i := range rooms {
+		responses[i] = *s.roomToResponse(&rooms[i])
+		responses[i].ParticipantCount = counts[rooms[i].ID]
+	}
+	return responses
+}
+
+func (s *roomService) roomsToSummary(rooms []model.Room) []RoomSummary {
+	ids := make([]string, len(rooms))
+	for i := range rooms {
+		ids[i] = rooms[i].ID
+	}
+	counts, err := s.roomRepo.GetParticipantCounts(ids)
+	if err != nil {
+		log.Printf("[Room] Failed to count participants: %v", err)
+	}
+
+	summaries := make([]RoomSummary, len(rooms))
+	for i, room := range rooms {
+		summaries[i] = RoomSummary{
+			ID:               room.ID,
+			Name:             room.Name,
+			Description:      room.Description,
+			Status:           room.Status,
+			MaxParticipants:  room.MaxParticipants,
+			ParticipantCount: counts[room.ID],
+			CreatedAt:        room.CreatedAt,
+		}
+	}
+	return summaries
+}
+
+func participantToResponse(p *repository.ParticipantDetail, room *model.Room) ParticipantResponse {
+	role := p.Role
+	if p.UserID == room.CreatedByID {
+		role = model.RoleHost
+	}
+	return ParticipantResponse{
+		UserID:               p.UserID,
+		Name:                 displayName(p.FullName, p.Username),
+		Email:                p.Email,
+		Role:                 role,
+		IsActive:             p.IsActive,
+		IsMuted:              p.IsMuted,
+		JoinedAt:             p.JoinedAt,
+		LeftAt:               p.LeftAt,
+		TotalDurationSeconds: p.TotalDurationSeconds,
+	}
+}
+
+// roomJoinURL is the client link to join a room
+func roomJoinURL(cfg *config.Config, roomID string) string {
+	return fmt.Sprintf("%s/room/%s", strings.TrimSuffix(cfg.ClientURL, "/"), roomID)
+}
+
+// participantIdentity returns the LiveKit identity used for a user (username, or email as fallback)
+func participantIdentity(user *model.User) string {
+	if user.Username != nil && *user.Username != "" {
+		return *user.Username
+	}
+	return user.Email
+}
+
+// userByIdentity maps a LiveKit identity (username or email) back to a user
+func userByIdentity(userRepo repository.UserRepository, identity string) *model.User {
+	if user, err := userRepo.FindByUsername(identity); err == nil {
+		return user
+	}
+	if user, err := userRepo.FindByEmail(identity); err == nil {
+		return user
+	}
+	return nil
+}
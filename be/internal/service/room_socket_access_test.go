@@ -0,0 +1,50 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"yourapp/internal/model"
+)
+
+// fakeLobby reports the users waiting in the lobby
+type fakeLobby struct {
+	LobbyService
+	waiting map[string]bool // roomID + "/" + userID
+}
+
+func (l *fakeLobby) IsWaiting(roomID, userID string) bool {
+	return l.waiting[roomID+"/"+userID]
+}
+
+func TestEnsureSocketAccess(t *testing.T) {
+	rooms := newFakeRoomRepo()
+	rooms.rooms["public"] = &model.Room{ID: "public", CreatedByID: "host", Visibility: model.VisibilityPublic}
+	rooms.rooms["private"] = &model.Room{ID: "private", CreatedByID: "host", Visibility: model.VisibilityPrivate, LobbyEnabled: true}
+	rooms.access["private/host"] = true
+	rooms.access["private/member"] = true
+	rooms.access["private/removed"] = true
+	moderator := "host"
+	rooms.participants["private/removed"] = &model.RoomParticipant{RemovedByID: &moderator}
+	rooms.participants["public/removed"] = &model.RoomParticipant{RemovedByID: &moderator}
+	lobby := &fakeLobby{waiting: map[string]bool{"private/guest": true}}
+	svc := NewRoomService(rooms, nil, nil, nil, nil, lobby, nil, nil)
+
+	tests := []struct {
+		room, user string
+		want       error
+	}{
+		{"public", "stranger", nil},
+		{"public", "removed", ErrRemovedFromRoom},
+		{"private", "host", nil},
+		{"private", "member", nil},
+		{"private", "guest", nil},
+		{"private", "stranger", ErrRoomAccessDenied},
+		{"private", "removed", ErrRemovedFromRoom},
+		{"missing", "member", ErrRoomNotFound},
+	}
+	for _, tc := range tests {
+		if err := svc.EnsureSocketAccess(tc.room, tc.user); !errors.Is(err, tc.want) {
+			t.Errorf("EnsureSocketAccess(%s, %s) = %v, want %v", tc.room, tc.user, err, tc.want)
+		}
+	}
+}
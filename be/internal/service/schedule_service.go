@@ -0,0 +1,331 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+	"yourapp/internal/util"
+)
+
+// maxInvitationsPerRequest caps how many emails can be invited at once
+const maxInvitationsPerRequest = 200
+
+var ErrRoomNotScheduled = errors.New("room has no schedule")
+
+// ScheduleService manages invitations, calendar files and reminders for
+// scheduled meetings. Emails go through the RabbitMQ email pipeline.
+type ScheduleService interface {
+	Invite(roomID, userID string, emails []string) ([]InvitationResponse, error)
+	GetInvitations(roomID, userID string) ([]InvitationResponse, error)
+	GetCalendar(roomID, userID string) (string, error)
+	SendDueReminders(now time.Time) int
+	IsInvited(roomID, userID string) bool
+}
+
+type scheduleService struct {
+	roomRepo       repository.RoomRepository
+	userRepo       repository.UserRepository
+	invitationRepo repository.InvitationRepository
+	publisher      *emailPublisher
+	cfg            *config.Config
+}
+
+func NewScheduleService(roomRepo repository.RoomRepository, userRepo repository.UserRepository, invitationRepo repository.InvitationRepository, emailService EmailService, rabbitMQ *util.RabbitMQClient, cfg *config.Config) ScheduleService {
+	return &scheduleService{
+		roomRepo:       roomRepo,
+		userRepo:       userRepo,
+		invitationRepo: invitationRepo,
+		publisher:      newEmailPublisher(rabbitMQ, cfg, emailService),
+		cfg:            cfg,
+	}
+}
+
+type InviteRequest struct {
+	Emails []string `json:"emails" binding:"required"`
+}
+
+type InvitationResponse struct {
+	ID          string     `json:"id"`
+	Email       string     `json:"email"`
+	UserID      *string    `json:"user_id,omitempty"`
+	InvitedByID string     `json:"invited_by_id"`
+	Status      string     `json:"status"`
+	SentAt      *time.Time `json:"sent_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Invite adds emails to the invite list of a scheduled room and sends each
+// new invitee an email with the calendar invite. Emails that were already
+// invited successfully are not sent again.
+func (s *scheduleService) Invite(roomID, userID string, emails []string) ([]InvitationResponse, error) {
+	room, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost)
+	if err != nil {
+		return nil, err
+	}
+	if room.ScheduledStart == nil {
+		return nil, ErrRoomNotScheduled
+	}
+
+	normalized, err := normalizeEmails(emails)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]model.RoomInvitation, 0, len(normalized))
+	for _, email := range normalized {
+		invitation := model.RoomInvitation{
+			RoomID:      roomID,
+			Email:       email,
+			InvitedByID: userID,
+			Status:      model.InvitationPending,
+		}
+		if user, err := s.userRepo.FindByEmail(email); err == nil {
+			invitation.UserID = &user.ID
+		}
+		invitations = append(invitations, invitation)
+	}
+	if err := s.invitationRepo.CreateBatch(invitations); err != nil {
+		return nil, errors.New("failed to save invitations")
+	}
+
+	// Re-read so that existing invitations come back with their own state
+	stored, err := s.invitationRepo.FindByRoomAndEmails(roomID, normalized)
+	if err != nil {
+		return nil, errors.New("failed to fetch invitations")
+	}
+
+	if full, err := s.roomRepo.FindByIDWithParticipants(roomID); err == nil {
+		room = full
+	}
+	var pending []model.RoomInvitation
+	for _, invitation := range stored {
+		if invitation.Status != model.InvitationSent {
+			pending = append(pending, invitation)
+		}
+	}
+	if len(pending) > 0 {
+		go s.sendInvitations(room, pending)
+	}
+
+	return invitationsToResponse(stored), nil
+}
+
+func (s *scheduleService) GetInvitations(roomID, userID string) ([]InvitationResponse, error) {
+	if _, err := ensureRoomRole(s.roomRepo, roomID, userID, model.RoleHost, model.RoleCoHost); err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.invitationRepo.FindByRoomID(roomID)
+	if err != nil {
+		return nil, errors.New("failed to fetch invitations")
+	}
+	return invitationsToResponse(invitations), nil
+}
+
+// GetCalendar returns the iCalendar file of a scheduled room. Participants
+// and invitees can download it.
+func (s *scheduleService) GetCalendar(roomID, userID string) (string, error) {
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		if !errors.Is(err, ErrRoomAccessDenied) || !s.IsInvited(roomID, userID) {
+			return "", err
+		}
+	}
+
+	room, err := s.roomRepo.FindByIDWithParticipants(roomID)
+	if err != nil {
+		return "", ErrRoomNotFound
+	}
+	if room.ScheduledStart == nil || room.ScheduledEnd == nil {
+		return "", ErrRoomNotScheduled
+	}
+
+	invitations, _ := s.invitationRepo.FindByRoomID(roomID)
+	attendees := make([]string, len(invitations))
+	for i, invitation := range invitations {
+		attendees[i] = invitation.Email
+	}
+	return util.BuildICS(s.icsEvent(room, attendees)), nil
+}
+
+// SendDueReminders emails invitees of meetings starting within the reminder
+// lead time. Each occurrence is reminded once. Returns the number of emails sent.
+func (s *scheduleService) SendDueReminders(now time.Time) int {
+	lead := s.cfg.MeetingReminderLead
+	if lead <= 0 {
+		lead = 15 * time.Minute
+	}
+
+	rooms, err := s.roomRepo.FindScheduled(now, now.Add(lead))
+	if err != nil {
+		log.Printf("[Schedule] Failed to fetch scheduled rooms: %v", err)
+		return 0
+	}
+
+	sent := 0
+	for i := range rooms {
+		room := &rooms[i]
+		start, end, ok := meetingOccurrence(room, now)
+		if !ok || start.Before(now) || start.After(now.Add(lead)) {
+			continue
+		}
+		if room.LastReminderFor != nil && room.LastReminderFor.Equal(start) {
+			continue
+		}
+
+		invitations, err := s.invitationRepo.FindByRoomID(room.ID)
+		if err != nil {
+			log.Printf("[Schedule] Failed to fetch invitations for room %s: %v", room.ID, err)
+			continue
+		}
+		// Claim first so a slow mail server or another instance cannot cause
+		// duplicate reminders
+		claimed, err := s.roomRepo.MarkReminderSent(room.ID, start)
+		if err != nil {
+			log.Printf("[Schedule] Failed to mark reminder for room %s: %v", room.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		meeting := s.meetingEmail(room, start, end, "")
+		for _, invitation := range invitations {
+			if err := s.publisher.publishMeetingEmail("meeting_reminder", invitation.Email, meeting); err != nil {
+				log.Printf("[Schedule] Failed to send reminder to %s for room %s: %v", invitation.Email, room.ID, err)
+				continue
+			}
+			sent++
+		}
+	}
+	return sent
+}
+
+func (s *scheduleService) sendInvitations(room *model.Room, invitations []model.RoomInvitation) {
+	start, end, ok := meetingOccurrence(room, time.Now())
+	if !ok {
+		start, end = *room.ScheduledStart, *room.ScheduledEnd
+	}
+
+	for _, invitation := range invitations {
+		// The invite covers the whole series, so the ICS uses the first occurrence
+		ics := util.BuildICS(s.icsEvent(room, []string{invitation.Email}))
+		meeting := s.meetingEmail(room, start, end, ics)
+
+		status := model.InvitationSent
+		var sentAt *time.Time
+		if err := s.publisher.publishMeetingEmail("meeting_invite", invitation.Email, meeting); err != nil {
+			log.Printf("[Schedule] Failed to send invitation to %s for room %s: %v", invitation.Email, room.ID, err)
+			status = model.InvitationFailed
+		} else {
+			now := time.Now()
+			sentAt = &now
+		}
+		if err := s.invitationRepo.UpdateStatus(invitation.ID, status, sentAt); err != nil {
+			log.Printf("[Schedule] Failed to update invitation %s: %v", invitation.ID, err)
+		}
+	}
+}
+
+func (s *scheduleService) meetingEmail(room *model.Room, start, end time.Time, ics string) MeetingEmail {
+	meeting := MeetingEmail{
+		RoomID:         room.ID,
+		Title:          room.Name,
+		HostName:       displayName(room.CreatedBy.FullName, room.CreatedBy.Username),
+		HostEmail:      room.CreatedBy.Email,
+		JoinURL:        roomJoinURL(s.cfg, room.ID),
+		Start:          start,
+		End:            end,
+		Timezone:       roomLocation(room).String(),
+		RecurrenceRule: room.RecurrenceRule,
+		ICS:            ics,
+	}
+	if room.Description != nil {
+		meeting.Description = *room.Description
+	}
+	return meeting
+}
+
+func (s *scheduleService) icsEvent(room *model.Room, attendees []string) util.ICSEvent {
+	event := util.ICSEvent{
+		UID:            fmt.Sprintf("%s@%s", room.ID, s.calendarDomain()),
+		Summary:        room.Name,
+		URL:            roomJoinURL(s.cfg, room.ID),
+		Start:          *room.ScheduledStart,
+		End:            *room.ScheduledEnd,
+		Timezone:       room.Timezone,
+		RecurrenceRule: room.RecurrenceRule,
+		OrganizerName:  displayName(room.CreatedBy.FullName, room.CreatedBy.Username),
+		OrganizerEmail: room.CreatedBy.Email,
+		Attendees:      attendees,
+	}
+	if room.Description != nil {
+		event.Description = *room.Description
+	}
+	return event
+}
+
+// calendarDomain is the host part of event UIDs
+func (s *scheduleService) calendarDomain() string {
+	if parsed, err := url.Parse(s.cfg.ClientURL); err == nil && parsed.Hostname() != "" {
+		return parsed.Hostname()
+	}
+	return "localhost"
+}
+
+// IsInvited reports whether the user's email is on the room's invite list
+func (s *scheduleService) IsInvited(roomID, userID string) bool {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return false
+	}
+	invitations, err := s.invitationRepo.FindByRoomAndEmails(roomID, []string{strings.ToLower(user.Email)})
+	return err == nil && len(invitations) > 0
+}
+
+// normalizeEmails validates, lowercases and de-duplicates invitee emails
+func normalizeEmails(emails []string) ([]string, error) {
+	seen := make(map[string]bool, len(emails))
+	var normalized []string
+	for _, raw := range emails {
+		address, err := mail.ParseAddress(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid email address %q", raw)
+		}
+		email := strings.ToLower(address.Address)
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+		normalized = append(normalized, email)
+	}
+	if len(normalized) == 0 {
+		return nil, errors.New("at least one email is required")
+	}
+	if len(normalized) > maxInvitationsPerRequest {
+		return nil, fmt.Errorf("at most %d emails can be invited at once", maxInvitationsPerRequest)
+	}
+	return normalized, nil
+}
+
+func invitationsToResponse(invitations []model.RoomInvitation) []InvitationResponse {
+	responses := make([]InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		responses[i] = InvitationResponse{
+			ID:          invitation.ID,
+			Email:       invitation.Email,
+			UserID:      invitation.UserID,
+			InvitedByID: invitation.InvitedByID,
+			Status:      invitation.Status,
+			SentAt:      invitation.SentAt,
+			CreatedAt:   invitation.CreatedAt,
+		}
+	}
+	return responses
+}
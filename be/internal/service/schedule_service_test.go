@@ -0,0 +1,106 @@
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+	"yourapp/internal/config"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+// reminderRoomRepo serves the rooms as they were read at the start of a
+// scheduler tick and claims reminders like the repository does
+type reminderRoomRepo struct {
+	repository.RoomRepository
+	mu        sync.Mutex
+	scheduled []model.Room
+	reminded  map[string]time.Time
+}
+
+func (r *reminderRoomRepo) FindScheduled(from, until time.Time) ([]model.Room, error) {
+	return append([]model.Room(nil), r.scheduled...), nil
+}
+
+func (r *reminderRoomRepo) MarkReminderSent(roomID string, occurrence time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if last, ok := r.reminded[roomID]; ok && last.Equal(occurrence) {
+		return false, nil
+	}
+	r.reminded[roomID] = occurrence
+	return true, nil
+}
+
+type reminderInvitationRepo struct {
+	repository.InvitationRepository
+	emails []string
+}
+
+func (r *reminderInvitationRepo) FindByRoomID(roomID string) ([]model.RoomInvitation, error) {
+	invitations := make([]model.RoomInvitation, len(r.emails))
+	for i, email := range r.emails {
+		invitations[i] = model.RoomInvitation{RoomID: roomID, Email: email}
+	}
+	return invitations, nil
+}
+
+// reminderEmails counts the reminders sent per address
+type reminderEmails struct {
+	EmailService
+	mu   sync.Mutex
+	sent map[string]int
+}
+
+func (e *reminderEmails) SendMeetingReminderEmail(to string, meeting MeetingEmail) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sent[to]++
+	return nil
+}
+
+func TestSendDueRemindersOncePerOccurrence(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC) // a Monday
+	end := start.Add(time.Hour)
+	rooms := &reminderRoomRepo{
+		scheduled: []model.Room{{
+			ID:             "room-1",
+			Name:           "Standup",
+			Status:         model.RoomScheduled,
+			ScheduledStart: &start,
+			ScheduledEnd:   &end,
+			RecurrenceRule: "FREQ=WEEKLY;BYDAY=MO",
+		}},
+		reminded: make(map[string]time.Time),
+	}
+	invitations := &reminderInvitationRepo{emails: []string{"a@example.com", "b@example.com"}}
+	emails := &reminderEmails{sent: make(map[string]int)}
+	cfg := &config.Config{MeetingReminderLead: 15 * time.Minute}
+	newInstance := func() *scheduleService {
+		return &scheduleService{
+			roomRepo:       rooms,
+			invitationRepo: invitations,
+			publisher:      newEmailPublisher(nil, nil, emails),
+			cfg:            cfg,
+		}
+	}
+
+	// Two instances run the same tick against the same snapshot
+	now := start.Add(-10 * time.Minute)
+	if sent := newInstance().SendDueReminders(now) + newInstance().SendDueReminders(now); sent != 2 {
+		t.Errorf("sent %d reminders, want one per invitee", sent)
+	}
+	if sent := newInstance().SendDueReminders(start.Add(-time.Hour)); sent != 0 {
+		t.Errorf("sent %d reminders outside the lead time", sent)
+	}
+
+	// The next weekly occurrence is reminded again
+	if sent := newInstance().SendDueReminders(now.AddDate(0, 0, 7)); sent != 2 {
+		t.Errorf("sent %d reminders for the next occurrence, want 2", sent)
+	}
+	for _, email := range invitations.emails {
+		if emails.sent[email] != 2 {
+			t.Errorf("%s got %d reminders, want 2", email, emails.sent[email])
+		}
+	}
+}
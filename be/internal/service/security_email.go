@@ -0,0 +1,95 @@
+package service
+
+import (
+	"fmt"
+	"html"
+	"time"
+)
+
+// AccountLockedEmail berisi data untuk email pemberitahuan akun terkunci.
+type AccountLockedEmail struct {
+	Minutes   int    `json:"minutes"`    // Lama akun terkunci
+	IPAddress string `json:"ip_address"` // IP dari percobaan login terakhir
+}
+
+// SendAccountLockedEmail memberi tahu pemilik akun bahwa login dikunci
+// sementara setelah terlalu banyak percobaan password yang salah.
+func (s *emailService) SendAccountLockedEmail(to string, lock AccountLockedEmail) error {
+	subject := "Peringatan Keamanan: Akun Anda Dikunci Sementara"
+	resetURL := fmt.Sprintf("%s/auth/forgot-password", s.config.ClientURL)
+
+	htmlBody := fmt.Sprintf(`
+<div style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f5f7fa;">
+    <table role="presentation" cellpadding="0" cellspacing="0" border="0" width="100%%" style="background-color: #f5f7fa;">
+        <tr>
+            <td align="center" style="padding: 40px 20px;">
+                <table role="presentation" cellpadding="0" cellspacing="0" border="0" width="600" style="max-width: 600px; width: 100%%; background-color: #ffffff; border-radius: 12px; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.07);">
+                    <!-- Header -->
+                    <tr>
+                        <td align="center" style="padding: 40px 40px 30px; background: linear-gradient(135deg, #f093fb 0%%, #f5576c 100%%); border-radius: 12px 12px 0 0;">
+                            <table role="presentation" cellpadding="0" cellspacing="0" border="0">
+                                <tr>
+                                    <td align="center" style="width: 64px; height: 64px; background-color: #ffffff; border-radius: 50%%; font-size: 32px; line-height: 64px;">
+                                        🔒
+                                    </td>
+                                </tr>
+                                <tr>
+                                    <td align="center" style="padding-top: 16px;">
+                                        <h1 style="margin: 0; color: #ffffff; font-size: 28px; font-weight: 700; letter-spacing: -0.5px;">Akun Dikunci Sementara</h1>
+                                    </td>
+                                </tr>
+                            </table>
+                        </td>
+                    </tr>
+
+                    <!-- Content -->
+                    <tr>
+                        <td style="padding: 40px;">
+                            <p style="margin: 0 0 24px; color: #2d3748; font-size: 16px; line-height: 1.6;">
+                                Kami mendeteksi beberapa percobaan login dengan password yang salah ke akun Anda (IP terakhir: %s).
+                                Demi keamanan, login ke akun Anda dikunci selama %d menit.
+                            </p>
+                            <p style="margin: 0 0 24px; color: #2d3748; font-size: 16px; line-height: 1.6;">
+                                Jika itu bukan Anda, segera ganti password Anda.
+                            </p>
+
+                            <!-- Button -->
+                            <table role="presentation" cellpadding="0" cellspacing="0" border="0" width="100%%">
+                                <tr>
+                                    <td align="center" style="padding: 8px 0 32px;">
+                                        <a href="%s" style="display: inline-block; padding: 14px 40px; background: linear-gradient(135deg, #f093fb 0%%, #f5576c 100%%); color: #ffffff; text-decoration: none; border-radius: 8px; font-size: 16px; font-weight: 600;">Reset Password</a>
+                                    </td>
+                                </tr>
+                            </table>
+                        </td>
+                    </tr>
+
+                    <!-- Footer -->
+                    <tr>
+                        <td style="padding: 30px 40px; background-color: #f8fafc; border-radius: 0 0 12px 12px; border-top: 1px solid #e2e8f0;">
+                            <p style="margin: 0; color: #94a3b8; font-size: 12px; text-align: center; line-height: 1.5;">
+                                © %d %s. All rights reserved.<br>
+                                Email ini dikirim secara otomatis, mohon jangan membalas.
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</div>
+`, html.EscapeString(lock.IPAddress), lock.Minutes, resetURL, time.Now().Year(), s.config.EmailName)
+
+	textBody := fmt.Sprintf(`
+Akun Dikunci Sementara
+
+Kami mendeteksi beberapa percobaan login dengan password yang salah ke akun Anda (IP terakhir: %s).
+Demi keamanan, login ke akun Anda dikunci selama %d menit.
+
+Jika itu bukan Anda, segera ganti password Anda: %s
+
+Tim %s
+`, lock.IPAddress, lock.Minutes, resetURL, s.config.EmailName)
+
+	return s.sendEmailHTML(to, subject, htmlBody, textBody)
+}
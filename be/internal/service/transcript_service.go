@@ -0,0 +1,255 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"yourapp/internal/model"
+	"yourapp/internal/repository"
+)
+
+// maxSegmentsPerRequest limits how many segments can be pushed in one call
+const maxSegmentsPerRequest = 100
+
+type TranscriptService interface {
+	IngestSegments(roomID string, source TranscriptSource, segments []TranscriptSegmentRequest) ([]TranscriptSegmentResponse, error)
+	GetSegments(roomID, userID string, since *time.Time, limit int) ([]TranscriptSegmentResponse, error)
+	Search(roomID, query, speaker string, limit int) ([]TranscriptSegmentResponse, error)
+	BuildContext(roomID, userID string, maxChars int) (string, error)
+}
+
+type transcriptService struct {
+	transcriptRepo repository.TranscriptRepository
+	roomRepo       repository.RoomRepository
+	userRepo       repository.UserRepository
+	ragService     RAGService
+	broadcaster    Broadcaster
+}
+
+func NewTranscriptService(transcriptRepo repository.TranscriptRepository, roomRepo repository.RoomRepository, userRepo repository.UserRepository, ragService RAGService, broadcaster Broadcaster) TranscriptService {
+	return &transcriptService{
+		transcriptRepo: transcriptRepo,
+		roomRepo:       roomRepo,
+		userRepo:       userRepo,
+		ragService:     ragService,
+		broadcaster:    broadcaster,
+	}
+}
+
+// TranscriptSource describes who is pushing segments. A user can only push
+// their own speech; an agent (authenticated with a LiveKit token) can push
+// segments for any speaker in the room.
+type TranscriptSource struct {
+	UserID  string
+	IsAgent bool
+}
+
+type TranscriptSegmentRequest struct {
+	SpeakerIdentity string    `json:"speaker_identity"`
+	SpeakerName     string    `json:"speaker_name"`
+	Text            string    `json:"text" binding:"required"`
+	Language        string    `json:"language"`
+	StartTime       time.Time `json:"start_time" binding:"required"`
+	EndTime         time.Time `json:"end_time" binding:"required"`
+	IsFinal         *bool     `json:"is_final"` // Interim segments are broadcast but not stored (default true)
+}
+
+type IngestTranscriptRequest struct {
+	Segments []TranscriptSegmentRequest `json:"segments" binding:"required,dive"`
+}
+
+type TranscriptSegmentResponse struct {
+	ID              string    `json:"id,omitempty"`
+	RoomID          string    `json:"room_id"`
+	SpeakerIdentity string    `json:"speaker_identity"`
+	SpeakerUserID   *string   `json:"speaker_user_id,omitempty"`
+	SpeakerName     string    `json:"speaker_name"`
+	Text            string    `json:"text"`
+	Language        string    `json:"language,omitempty"`
+	Source          string    `json:"source"`
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+	IsFinal         bool      `json:"is_final"`
+}
+
+func (s *transcriptService) IngestSegments(roomID string, source TranscriptSource, segments []TranscriptSegmentRequest) ([]TranscriptSegmentResponse, error) {
+	if len(segments) == 0 {
+		return nil, errors.New("no segments provided")
+	}
+	if len(segments) > maxSegmentsPerRequest {
+		return nil, fmt.Errorf("too many segments, maximum is %d per request", maxSegmentsPerRequest)
+	}
+
+	if source.IsAgent {
+		if _, err := s.roomRepo.FindByID(roomID); err != nil {
+			return nil, ErrRoomNotFound
+		}
+	} else if err := ensureRoomAccess(s.roomRepo, roomID, source.UserID); err != nil {
+		return nil, err
+	}
+
+	// Users always speak as themselves
+	var self *model.User
+	if !source.IsAgent {
+		user, err := s.userRepo.FindByID(source.UserID)
+		if err != nil {
+			return nil, errors.New("user not found")
+		}
+		self = user
+	}
+
+	speakers := make(map[string]*model.User)
+	toStore := make([]model.TranscriptSegment, 0, len(segments))
+	responses := make([]TranscriptSegmentResponse, 0, len(segments))
+
+	for _, req := range segments {
+		text := strings.TrimSpace(req.Text)
+		if text == "" {
+			continue
+		}
+		if req.EndTime.Before(req.StartTime) {
+			return nil, errors.New("segment end_time must not be before start_time")
+		}
+
+		segment := model.TranscriptSegment{
+			RoomID:    roomID,
+			Text:      text,
+			Language:  req.Language,
+			StartTime: req.StartTime,
+			EndTime:   req.EndTime,
+		}
+
+		if self != nil {
+			segment.Source = "client"
+			segment.SpeakerIdentity = participantIdentity(self)
+			segment.SpeakerUserID = &self.ID
+			segment.SpeakerName = displayName(self.FullName, self.Username)
+		} else {
+			if req.SpeakerIdentity == "" {
+				return nil, errors.New("speaker_identity is required")
+			}
+			segment.Source = "agent"
+			segment.SpeakerIdentity = req.SpeakerIdentity
+			segment.SpeakerName = req.SpeakerName
+
+			speaker, cached := speakers[req.SpeakerIdentity]
+			if !cached {
+				speaker = userByIdentity(s.userRepo, req.SpeakerIdentity)
+				speakers[req.SpeakerIdentity] = speaker
+			}
+			if speaker != nil {
+				segment.SpeakerUserID = &speaker.ID
+				if segment.SpeakerName == "" {
+					segment.SpeakerName = displayName(speaker.FullName, speaker.Username)
+				}
+			}
+			if segment.SpeakerName == "" {
+				segment.SpeakerName = req.SpeakerIdentity
+			}
+		}
+
+		isFinal := req.IsFinal == nil || *req.IsFinal
+		if isFinal {
+			toStore = append(toStore, segment)
+		} else {
+			responses = append(responses, segmentToResponse(&segment, false))
+		}
+	}
+
+	if err := s.transcriptRepo.CreateBatch(toStore); err != nil {
+		return nil, errors.New("failed to store transcript segments")
+	}
+	for i := range toStore {
+		responses = append(responses, segmentToResponse(&toStore[i], true))
+	}
+	if len(toStore) > 0 {
+		indexAsync(s.ragService, "transcript segments", func(rag RAGService) error {
+			return rag.IndexTranscriptSegments(toStore)
+		})
+	}
+
+	for i := range responses {
+		broadcast(s.broadcaster, roomID, source.UserID, "transcript_segment", responses[i])
+	}
+
+	return responses, nil
+}
+
+func (s *transcriptService) GetSegments(roomID, userID string, since *time.Time, limit int) ([]TranscriptSegmentResponse, error) {
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	segments, err := s.transcriptRepo.FindByRoomID(roomID, since, limit)
+	if err != nil {
+		return nil, errors.New("failed to fetch transcript")
+	}
+
+	responses := make([]TranscriptSegmentResponse, len(segments))
+	for i := range segments {
+		responses[i] = segmentToResponse(&segments[i], true)
+	}
+	return responses, nil
+}
+
+// Search finds transcript segments by keyword. Callers are expected to have
+// checked room access already.
+func (s *transcriptService) Search(roomID, query, speaker string, limit int) ([]TranscriptSegmentResponse, error) {
+	segments, err := s.transcriptRepo.Search(roomID, query, speaker, limit)
+	if err != nil {
+		return nil, errors.New("failed to search transcript")
+	}
+
+	responses := make([]TranscriptSegmentResponse, len(segments))
+	for i := range segments {
+		responses[i] = segmentToResponse(&segments[i], true)
+	}
+	return responses, nil
+}
+
+// BuildContext renders the most recent transcript as plain text for AI prompts,
+// keeping at most maxChars characters (oldest lines are dropped first)
+func (s *transcriptService) BuildContext(roomID, userID string, maxChars int) (string, error) {
+	if err := ensureRoomAccess(s.roomRepo, roomID, userID); err != nil {
+		return "", err
+	}
+
+	segments, err := s.transcriptRepo.FindRecent(roomID, 500)
+	if err != nil {
+		return "", errors.New("failed to fetch transcript")
+	}
+
+	lines := make([]string, 0, len(segments))
+	total := 0
+	for i := len(segments) - 1; i >= 0; i-- {
+		line := fmt.Sprintf("[%s] %s: %s", segments[i].StartTime.Format("15:04:05"), segments[i].SpeakerName, segments[i].Text)
+		if total+len(line)+1 > maxChars {
+			break
+		}
+		total += len(line) + 1
+		lines = append(lines, line)
+	}
+
+	// Lines were collected newest first
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func segmentToResponse(segment *model.TranscriptSegment, isFinal bool) TranscriptSegmentResponse {
+	return TranscriptSegmentResponse{
+		ID:              segment.ID,
+		RoomID:          segment.RoomID,
+		SpeakerIdentity: segment.SpeakerIdentity,
+		SpeakerUserID:   segment.SpeakerUserID,
+		SpeakerName:     segment.SpeakerName,
+		Text:            segment.Text,
+		Language:        segment.Language,
+		Source:          segment.Source,
+		StartTime:       segment.StartTime,
+		EndTime:         segment.EndTime,
+		IsFinal:         isFinal,
+	}
+}
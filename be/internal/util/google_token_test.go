@@ -0,0 +1,160 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testGoogleClientID = "client-123.apps.googleusercontent.com"
+
+// fakeGoogle serves a JWKS with one signing key and counts the fetches
+type fakeGoogle struct {
+	key     *rsa.PrivateKey
+	kid     string
+	fetches atomic.Int32
+}
+
+func newFakeGoogle(t *testing.T) (*fakeGoogle, *GoogleTokenVerifier) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	google := &fakeGoogle{key: key, kid: "key-1"}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		google.fetches.Add(1)
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": google.kid,
+				"kty": "RSA",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	return google, NewGoogleTokenVerifier(testGoogleClientID, "secret", "", srv.URL, "")
+}
+
+// claims returns valid ID token claims that tests then break one at a time
+func (g *fakeGoogle) claims() *GoogleIDClaims {
+	now := time.Now()
+	return &GoogleIDClaims{
+		Email:         "user@example.com",
+		EmailVerified: true,
+		Name:          "Test User",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "https://accounts.google.com",
+			Subject:   "1234567890",
+			Audience:  jwt.ClaimStrings{testGoogleClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+}
+
+func (g *fakeGoogle) sign(t *testing.T, claims *GoogleIDClaims, kid string, key *rsa.PrivateKey) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return signed
+}
+
+func TestGoogleVerifyAcceptsValidToken(t *testing.T) {
+	google, verifier := newFakeGoogle(t)
+
+	claims, err := verifier.Verify(google.sign(t, google.claims(), google.kid, google.key))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject != "1234567890" || claims.Email != "user@example.com" || !claims.EmailVerified {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	// The keys are cached for the max-age Google sends
+	if _, err := verifier.Verify(google.sign(t, google.claims(), google.kid, google.key)); err != nil {
+		t.Fatalf("second Verify: %v", err)
+	}
+	if fetches := google.fetches.Load(); fetches != 1 {
+		t.Errorf("fetched the keys %d times, want once", fetches)
+	}
+}
+
+func TestGoogleVerifyRejectsInvalidTokens(t *testing.T) {
+	google, verifier := newFakeGoogle(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{"wrong audience", func() string {
+			claims := google.claims()
+			claims.Audience = jwt.ClaimStrings{"someone-else.apps.googleusercontent.com"}
+			return google.sign(t, claims, google.kid, google.key)
+		}},
+		{"wrong issuer", func() string {
+			claims := google.claims()
+			claims.Issuer = "https://evil.example.com"
+			return google.sign(t, claims, google.kid, google.key)
+		}},
+		{"expired", func() string {
+			claims := google.claims()
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+			return google.sign(t, claims, google.kid, google.key)
+		}},
+		{"unknown kid", func() string {
+			return google.sign(t, google.claims(), "key-2", otherKey)
+		}},
+		{"forged signature", func() string {
+			return google.sign(t, google.claims(), google.kid, otherKey)
+		}},
+		{"missing expiry", func() string {
+			claims := google.claims()
+			claims.ExpiresAt = nil
+			return google.sign(t, claims, google.kid, google.key)
+		}},
+	}
+	for _, tc := range tests {
+		if _, err := verifier.Verify(tc.token()); !errors.Is(err, ErrInvalidGoogleToken) {
+			t.Errorf("%s: got %v, want ErrInvalidGoogleToken", tc.name, err)
+		}
+	}
+}
+
+func TestGoogleVerifyLimitsKeyRefetches(t *testing.T) {
+	google, verifier := newFakeGoogle(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		verifier.Verify(google.sign(t, google.claims(), "unknown", otherKey))
+	}
+	if fetches := google.fetches.Load(); fetches != 1 {
+		t.Errorf("fetched the keys %d times for unknown key IDs, want once per %v", fetches, googleKeysMinRefresh)
+	}
+}
@@ -0,0 +1,237 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ICSEvent describes a calendar event for an iCalendar (RFC 5545) invite
+type ICSEvent struct {
+	UID            string
+	Sequence       int
+	Summary        string
+	Description    string
+	URL            string
+	Start          time.Time
+	End            time.Time
+	Timezone       string // IANA name; recurring events are written in local time so BYDAY matches
+	RecurrenceRule string // RRULE value without the "RRULE:" prefix
+	OrganizerName  string
+	OrganizerEmail string
+	Attendees      []string
+	Method         string // REQUEST (default) or CANCEL
+}
+
+const (
+	icsTimeLayout      = "20060102T150405Z"
+	icsLocalTimeLayout = "20060102T150405"
+)
+
+// BuildICS renders the event as an iCalendar document. Times are written in
+// UTC, except for recurring events with a time zone.
+func BuildICS(event ICSEvent) string {
+	method := event.Method
+	if method == "" {
+		method = "REQUEST"
+	}
+	status := "CONFIRMED"
+	if method == "CANCEL" {
+		status = "CANCELLED"
+	}
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Zacode//Meetings//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:" + method,
+	}
+	loc, err := time.LoadLocation(event.Timezone)
+	localTimes := err == nil && event.RecurrenceRule != "" && loc != time.UTC
+	if localTimes {
+		// Every TZID used must be defined in the calendar
+		lines = append(lines, buildVTimezone(event.Timezone, loc, event.Start.In(loc).Year())...)
+	}
+	lines = append(lines,
+		"BEGIN:VEVENT",
+		"UID:"+event.UID,
+		fmt.Sprintf("SEQUENCE:%d", event.Sequence),
+		"DTSTAMP:"+time.Now().UTC().Format(icsTimeLayout),
+	)
+	if localTimes {
+		lines = append(lines,
+			fmt.Sprintf("DTSTART;TZID=%s:%s", event.Timezone, event.Start.In(loc).Format(icsLocalTimeLayout)),
+			fmt.Sprintf("DTEND;TZID=%s:%s", event.Timezone, event.End.In(loc).Format(icsLocalTimeLayout)),
+		)
+	} else {
+		lines = append(lines,
+			"DTSTART:"+event.Start.UTC().Format(icsTimeLayout),
+			"DTEND:"+event.End.UTC().Format(icsTimeLayout),
+		)
+	}
+	if event.RecurrenceRule != "" {
+		lines = append(lines, "RRULE:"+strings.TrimPrefix(event.RecurrenceRule, "RRULE:"))
+	}
+	lines = append(lines,
+		"SUMMARY:"+escapeICSText(event.Summary),
+		"STATUS:"+status,
+	)
+	if event.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeICSText(event.Description))
+	}
+	if event.URL != "" {
+		lines = append(lines, "URL:"+event.URL, "LOCATION:"+escapeICSText(event.URL))
+	}
+	if event.OrganizerEmail != "" {
+		lines = append(lines, fmt.Sprintf("ORGANIZER;CN=%s:mailto:%s", escapeICSParam(event.OrganizerName), event.OrganizerEmail))
+	}
+	for _, attendee := range event.Attendees {
+		lines = append(lines, "ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:"+attendee)
+	}
+	if method == "REQUEST" {
+		lines = append(lines,
+			"BEGIN:VALARM",
+			"ACTION:DISPLAY",
+			"DESCRIPTION:"+escapeICSText(event.Summary),
+			"TRIGGER:-PT15M",
+			"END:VALARM",
+		)
+	}
+	lines = append(lines, "END:VEVENT", "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(foldICSLine(line))
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
+
+// buildVTimezone describes loc as a VTIMEZONE (RFC 5545 section 3.6.5). The
+// daylight saving transitions of year are repeated yearly on the same weekday
+// of the month, which is how zones define them; zones without daylight saving
+// get a single STANDARD observance.
+func buildVTimezone(tzid string, loc *time.Location, year int) []string {
+	lines := []string{"BEGIN:VTIMEZONE", "TZID:" + tzid}
+
+	transitions := zoneTransitions(loc, year)
+	if len(transitions) == 0 {
+		name, offset := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+		lines = append(lines,
+			"BEGIN:STANDARD",
+			"DTSTART:19700101T000000",
+			"TZOFFSETFROM:"+formatICSOffset(offset),
+			"TZOFFSETTO:"+formatICSOffset(offset),
+			"TZNAME:"+name,
+			"END:STANDARD",
+		)
+	}
+	for _, at := range transitions {
+		_, from := at.Add(-time.Minute).Zone()
+		name, to := at.Zone()
+		component := "STANDARD"
+		if at.IsDST() {
+			component = "DAYLIGHT"
+		}
+		// DTSTART is the wall-clock time the change happens at, before it
+		local := at.UTC().Add(time.Duration(from) * time.Second)
+		lines = append(lines,
+			"BEGIN:"+component,
+			"DTSTART:"+local.Format(icsLocalTimeLayout),
+			"RRULE:"+yearlyWeekdayRule(local),
+			"TZOFFSETFROM:"+formatICSOffset(from),
+			"TZOFFSETTO:"+formatICSOffset(to),
+			"TZNAME:"+name,
+			"END:"+component,
+		)
+	}
+	return append(lines, "END:VTIMEZONE")
+}
+
+// zoneTransitions returns the instants in year at which loc changes its UTC
+// offset, to the minute
+func zoneTransitions(loc *time.Location, year int) []time.Time {
+	var transitions []time.Time
+	end := time.Date(year+1, time.January, 1, 0, 0, 0, 0, loc)
+	for t := time.Date(year, time.January, 1, 0, 0, 0, 0, loc); t.Before(end); t = t.Add(time.Hour) {
+		_, before := t.Zone()
+		next := t.Add(time.Hour)
+		if _, after := next.Zone(); after == before {
+			continue
+		}
+		lo, hi := t, next
+		for hi.Sub(lo) > time.Minute {
+			mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Minute)
+			if _, offset := mid.Zone(); offset == before {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		transitions = append(transitions, hi)
+	}
+	return transitions
+}
+
+// yearlyWeekdayRule repeats a date yearly as the nth (or last) weekday of its month
+func yearlyWeekdayRule(t time.Time) string {
+	day := strings.ToUpper(t.Weekday().String()[:2])
+	daysInMonth := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	nth := strconv.Itoa((t.Day()-1)/7 + 1)
+	if t.Day()+7 > daysInMonth {
+		nth = "-1"
+	}
+	return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%s%s", int(t.Month()), nth, day)
+}
+
+// formatICSOffset renders a UTC offset in seconds as UTC-OFFSET (+hhmm[ss])
+func formatICSOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	offset := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+	if seconds%60 != 0 {
+		offset += fmt.Sprintf("%02d", seconds%60)
+	}
+	return offset
+}
+
+// escapeICSText escapes TEXT values (RFC 5545 section 3.3.11)
+func escapeICSText(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(s)
+}
+
+// escapeICSParam quotes a parameter value such as CN
+func escapeICSParam(s string) string {
+	s = strings.ReplaceAll(s, `"`, "'")
+	if strings.ContainsAny(s, ":;,") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// foldICSLine splits lines longer than 75 octets, continuing with a space
+func foldICSLine(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
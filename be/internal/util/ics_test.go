@@ -0,0 +1,135 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func icsLines(ics string) []string {
+	return strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n")
+}
+
+// icsBlocks returns the lines between BEGIN:name and END:name, once per block
+func icsBlocks(lines []string, name string) [][]string {
+	var blocks [][]string
+	var current []string
+	inside := false
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:"+name:
+			inside, current = true, nil
+		case line == "END:"+name:
+			inside = false
+			blocks = append(blocks, current)
+		case inside:
+			current = append(current, line)
+		}
+	}
+	return blocks
+}
+
+func hasLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBuildICSRecurringEventDefinesTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, loc)
+	lines := icsLines(BuildICS(ICSEvent{
+		UID:            "room-1@example.com",
+		Summary:        "Standup",
+		Start:          start,
+		End:            start.Add(30 * time.Minute),
+		Timezone:       "America/New_York",
+		RecurrenceRule: "FREQ=WEEKLY;BYDAY=MO",
+	}))
+
+	if !hasLine(lines, "DTSTART;TZID=America/New_York:20260302T090000") {
+		t.Errorf("missing local DTSTART in\n%s", strings.Join(lines, "\n"))
+	}
+	zones := icsBlocks(lines, "VTIMEZONE")
+	if len(zones) != 1 || !hasLine(zones[0], "TZID:America/New_York") {
+		t.Fatalf("VTIMEZONE blocks = %v, want one for America/New_York", zones)
+	}
+
+	daylight := icsBlocks(zones[0], "DAYLIGHT")
+	if len(daylight) != 1 {
+		t.Fatalf("DAYLIGHT blocks = %v", daylight)
+	}
+	for _, want := range []string{"DTSTART:20260308T020000", "RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=2SU", "TZOFFSETFROM:-0500", "TZOFFSETTO:-0400", "TZNAME:EDT"} {
+		if !hasLine(daylight[0], want) {
+			t.Errorf("DAYLIGHT %v is missing %s", daylight[0], want)
+		}
+	}
+	standard := icsBlocks(zones[0], "STANDARD")
+	if len(standard) != 1 {
+		t.Fatalf("STANDARD blocks = %v", standard)
+	}
+	for _, want := range []string{"DTSTART:20261101T020000", "RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=1SU", "TZOFFSETFROM:-0400", "TZOFFSETTO:-0500", "TZNAME:EST"} {
+		if !hasLine(standard[0], want) {
+			t.Errorf("STANDARD %v is missing %s", standard[0], want)
+		}
+	}
+
+	// The time zone is defined before the event that uses it
+	if ics := strings.Join(lines, "\n"); strings.Index(ics, "BEGIN:VTIMEZONE") > strings.Index(ics, "BEGIN:VEVENT") {
+		t.Error("VTIMEZONE comes after VEVENT")
+	}
+}
+
+func TestBuildVTimezoneLastSundayRule(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	lines := buildVTimezone("Europe/Berlin", loc, 2026)
+	daylight := icsBlocks(lines, "DAYLIGHT")
+	if len(daylight) != 1 || !hasLine(daylight[0], "RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU") || !hasLine(daylight[0], "DTSTART:20260329T020000") {
+		t.Errorf("DAYLIGHT = %v, want the last Sunday of March at 02:00", daylight)
+	}
+}
+
+func TestBuildVTimezoneWithoutDaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	lines := buildVTimezone("Asia/Jakarta", loc, 2026)
+	standard := icsBlocks(lines, "STANDARD")
+	if len(standard) != 1 || len(icsBlocks(lines, "DAYLIGHT")) != 0 {
+		t.Fatalf("observances = %v, want a single STANDARD", lines)
+	}
+	for _, want := range []string{"TZOFFSETFROM:+0700", "TZOFFSETTO:+0700", "TZNAME:WIB"} {
+		if !hasLine(standard[0], want) {
+			t.Errorf("STANDARD %v is missing %s", standard[0], want)
+		}
+	}
+}
+
+func TestBuildICSOneOffEventUsesUTC(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	lines := icsLines(BuildICS(ICSEvent{UID: "room-1@example.com", Summary: "Review, part 1; final", Start: start, End: start.Add(time.Hour), Timezone: "Asia/Jakarta"}))
+
+	if len(icsBlocks(lines, "VTIMEZONE")) != 0 {
+		t.Error("one-off event in UTC has a VTIMEZONE")
+	}
+	for _, want := range []string{"DTSTART:20260302T090000Z", "DTEND:20260302T100000Z", `SUMMARY:Review\, part 1\; final`} {
+		if !hasLine(lines, want) {
+			t.Errorf("missing %s in\n%s", want, strings.Join(lines, "\n"))
+		}
+	}
+	for _, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+}
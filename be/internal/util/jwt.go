@@ -5,65 +5,104 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Token types, carried in the "typ" claim so that one kind of token can never
+// be used in place of another
+const (
+	TokenTypeAccess      = "access"
+	TokenTypeRefresh     = "refresh"
+	TokenTypeReset       = "reset"
+	TokenTypeVerifyEmail = "verify_email"
+)
+
+// Token lifetimes
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour
 )
 
 type JWTClaims struct {
-	UserID   string `json:"userId"`
-	Email    string `json:"email"`
-	UserType string `json:"role"`
+	UserID    string `json:"userId"`
+	Email     string `json:"email"`
+	UserType  string `json:"role"`
+	TokenType string `json:"typ"`
+	SessionID string `json:"sid,omitempty"` // Access and refresh tokens: the login session they belong to
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a JWT token
-func GenerateToken(userID, email, userType, secret string, expiresIn time.Duration) (string, error) {
-	claims := JWTClaims{
-		UserID:   userID,
-		Email:    email,
-		UserType: userType,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "yourapp",
-			Subject:   userID,
-		},
+// GenerateToken generates a JWT token of the given type
+func GenerateToken(userID, email, userType, tokenType string, keys *JWTKeySet, expiresIn time.Duration) (string, error) {
+	return signToken(JWTClaims{
+		UserID:    userID,
+		Email:     email,
+		UserType:  userType,
+		TokenType: tokenType,
+	}, keys, expiresIn)
+}
+
+// signToken fills in the registered claims and signs. Every token gets a
+// unique ID, so two tokens issued in the same second never collide.
+func signToken(claims JWTClaims, keys *JWTKeySet, expiresIn time.Duration) (string, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.New().String(),
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		Issuer:    "yourapp",
+		Subject:   claims.UserID,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return keys.Sign(claims)
 }
 
-// GenerateAccessToken generates an access token (15 minutes)
-func GenerateAccessToken(userID, email, userType, secret string) (string, error) {
-	return GenerateToken(userID, email, userType, secret, 15*time.Minute)
+// GenerateAccessToken generates an access token (15 minutes) for a session
+func GenerateAccessToken(userID, email, userType, sessionID string, keys *JWTKeySet) (string, error) {
+	return signToken(JWTClaims{
+		UserID:    userID,
+		Email:     email,
+		UserType:  userType,
+		TokenType: TokenTypeAccess,
+		SessionID: sessionID,
+	}, keys, AccessTokenTTL)
 }
 
-// GenerateRefreshToken generates a refresh token (7 days)
-func GenerateRefreshToken(userID, email, userType, secret string) (string, error) {
-	return GenerateToken(userID, email, userType, secret, 7*24*time.Hour)
+// GenerateRefreshToken generates a refresh token (7 days) for a session
+func GenerateRefreshToken(userID, email, userType, sessionID string, keys *JWTKeySet) (string, error) {
+	return signToken(JWTClaims{
+		UserID:    userID,
+		Email:     email,
+		UserType:  userType,
+		TokenType: TokenTypeRefresh,
+		SessionID: sessionID,
+	}, keys, RefreshTokenTTL)
 }
 
 // GenerateResetPasswordToken generates a reset password token (1 hour)
-func GenerateResetPasswordToken(userID, email, secret string) (string, error) {
-	return GenerateToken(userID, email, "reset", secret, 1*time.Hour)
+func GenerateResetPasswordToken(userID, email string, keys *JWTKeySet) (string, error) {
+	return GenerateToken(userID, email, "", TokenTypeReset, keys, 1*time.Hour)
 }
 
-// ValidateToken validates a JWT token
-func ValidateToken(tokenString, secret string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(secret), nil
-	})
+// GenerateVerifyEmailToken generates an email verification token (24 hours)
+func GenerateVerifyEmailToken(userID, email string, keys *JWTKeySet) (string, error) {
+	return GenerateToken(userID, email, "", TokenTypeVerifyEmail, keys, 24*time.Hour)
+}
 
+// ValidateToken validates a JWT token and checks it is of the expected type
+func ValidateToken(tokenString string, keys *JWTKeySet, tokenType string) (*JWTClaims, error) {
+	token, err := keys.Parse(tokenString, &JWTClaims{})
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
 	}
-
-	return nil, errors.New("invalid token")
+	if claims.TokenType != tokenType {
+		return nil, errors.New("wrong token type")
+	}
+	return claims, nil
 }
@@ -0,0 +1,258 @@
+package util
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"yourapp/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtKey is a key tokens are signed or verified with
+type jwtKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{} // nil for keys only kept to verify older tokens
+	verifyKey interface{}
+	notAfter  time.Time // Tokens are no longer accepted with this key after it; zero for no limit
+}
+
+// JWTKeySet signs tokens with its current key and verifies them with any of
+// its keys, so tokens signed before a key rotation stay valid until they
+// expire. Asymmetric keys are published as a JWKS, letting other services
+// verify tokens without holding a secret.
+type JWTKeySet struct {
+	current *jwtKey
+	keys    map[string]*jwtKey
+	methods []string
+	now     func() time.Time
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // OKP curve
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKS is a set of public keys, as served from /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadJWTKeySet builds the key set from config. With JWT_PRIVATE_KEY_FILE
+// set, tokens are signed with that RSA (RS256) or Ed25519 (EdDSA) key and
+// JWT_PREVIOUS_KEY_FILES are kept for verification only. Otherwise tokens are
+// signed with JWT_SECRET using HS256.
+//
+// When moving from JWT_SECRET to a key file, JWT_SECRET keeps verifying the
+// HS256 tokens already issued until a refresh token lifetime after
+// JWT_SECRET_RETIRED_AT (or after startup, if that is not set), so the switch
+// doesn't sign everyone out.
+func LoadJWTKeySet(cfg *config.Config) (*JWTKeySet, error) {
+	if cfg.JWTPrivateKeyFile == "" {
+		return NewHMACKeySet(cfg.JWTSecret), nil
+	}
+
+	current, err := loadJWTKey(cfg.JWTPrivateKeyFile, true)
+	if err != nil {
+		return nil, err
+	}
+	set := newJWTKeySet(current)
+	for _, path := range cfg.JWTPreviousKeyFiles {
+		key, err := loadJWTKey(path, false)
+		if err != nil {
+			return nil, err
+		}
+		key.signKey = nil
+		set.add(key)
+	}
+
+	if cfg.JWTSecret != "" {
+		retiredAt := time.Now()
+		if cfg.JWTSecretRetiredAt != "" {
+			if retiredAt, err = time.Parse(time.RFC3339, cfg.JWTSecretRetiredAt); err != nil {
+				return nil, fmt.Errorf("JWT_SECRET_RETIRED_AT must be an RFC3339 time: %w", err)
+			}
+		}
+		set.add(&jwtKey{
+			method:    jwt.SigningMethodHS256,
+			verifyKey: []byte(cfg.JWTSecret),
+			notAfter:  retiredAt.Add(RefreshTokenTTL),
+		})
+	}
+	return set, nil
+}
+
+// NewHMACKeySet creates a key set that signs and verifies with a shared secret
+func NewHMACKeySet(secret string) *JWTKeySet {
+	return newJWTKeySet(&jwtKey{
+		method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	})
+}
+
+func newJWTKeySet(current *jwtKey) *JWTKeySet {
+	set := &JWTKeySet{current: current, keys: make(map[string]*jwtKey), now: time.Now}
+	set.add(current)
+	return set
+}
+
+func (s *JWTKeySet) add(key *jwtKey) {
+	if _, ok := s.keys[key.id]; ok {
+		return
+	}
+	s.keys[key.id] = key
+	for _, method := range s.methods {
+		if method == key.method.Alg() {
+			return
+		}
+	}
+	s.methods = append(s.methods, key.method.Alg())
+}
+
+// Sign signs claims with the current key
+func (s *JWTKeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.current.method, claims)
+	if s.current.id != "" {
+		token.Header["kid"] = s.current.id
+	}
+	return token.SignedString(s.current.signKey)
+}
+
+// Parse verifies a token against the key named by its kid header
+func (s *JWTKeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := s.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		// The algorithm must be the key's own, never one chosen by the token
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, errors.New("unexpected signing method")
+		}
+		if !key.notAfter.IsZero() && s.now().After(key.notAfter) {
+			return nil, fmt.Errorf("signing key %q has been retired", kid)
+		}
+		return key.verifyKey, nil
+	}, jwt.WithValidMethods(s.methods))
+}
+
+// JWKS returns the public keys of the set. Shared secrets are never published.
+func (s *JWTKeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	if jwk, ok := s.current.jwk(); ok {
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	for _, key := range s.keys {
+		if key == s.current {
+			continue
+		}
+		if jwk, ok := key.jwk(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	return jwks
+}
+
+func (k *jwtKey) jwk() (JWK, bool) {
+	switch public := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: k.id,
+			Use: "sig",
+			Alg: k.method.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: k.id,
+			Use: "sig",
+			Alg: k.method.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+		}, true
+	}
+	return JWK{}, false
+}
+
+// newAsymmetricKey picks the signing method from the key type. The key ID is
+// derived from the public key, so it stays stable across restarts and
+// instances without being configured.
+func newAsymmetricKey(public crypto.PublicKey, private crypto.Signer) (*jwtKey, error) {
+	var method jwt.SigningMethod
+	switch public.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported JWT key type %T", public)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+
+	return &jwtKey{
+		id:        base64.RawURLEncoding.EncodeToString(sum[:12]),
+		method:    method,
+		signKey:   private,
+		verifyKey: public,
+	}, nil
+}
+
+// loadJWTKey reads a PEM key file. Signing keys must be private keys (PKCS#8,
+// or PKCS#1 for RSA); previous keys may also be public keys.
+func loadJWTKey(path string, signing bool) (*jwtKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in JWT key file %s", path)
+	}
+
+	if private, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported JWT key in %s", path)
+		}
+		return newAsymmetricKey(signer.Public(), signer)
+	}
+	if private, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return newAsymmetricKey(private.Public(), private)
+	}
+	if signing {
+		return nil, fmt.Errorf("JWT signing key %s is not a private key", path)
+	}
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported JWT key in %s: %w", path, err)
+	}
+	return newAsymmetricKey(public, nil)
+}
@@ -0,0 +1,180 @@
+package util
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"yourapp/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKeyFile stores a PKCS#8 private key as PEM and returns its path
+func writeKeyFile(t *testing.T, name string, key interface{}) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+	return path
+}
+
+func testKeys(t *testing.T) (rsaKey *rsa.PrivateKey, edKey ed25519.PrivateKey) {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	_, edKey, err = ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey: %v", err)
+	}
+	return rsaKey, edKey
+}
+
+func signTestToken(t *testing.T, keys *JWTKeySet) string {
+	t.Helper()
+	token, err := GenerateAccessToken("user-1", "user@example.com", "member", "session-1", keys)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+	return token
+}
+
+func tokenKid(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &JWTClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified: %v", err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestJWTKeySetRotation(t *testing.T) {
+	rsaKey, edKey := testKeys(t)
+	oldPath := writeKeyFile(t, "old.pem", edKey)
+	newPath := writeKeyFile(t, "new.pem", rsaKey)
+
+	before, err := LoadJWTKeySet(&config.Config{JWTPrivateKeyFile: oldPath})
+	if err != nil {
+		t.Fatalf("LoadJWTKeySet before rotation: %v", err)
+	}
+	after, err := LoadJWTKeySet(&config.Config{JWTPrivateKeyFile: newPath, JWTPreviousKeyFiles: []string{oldPath}})
+	if err != nil {
+		t.Fatalf("LoadJWTKeySet after rotation: %v", err)
+	}
+
+	oldToken := signTestToken(t, before)
+	newToken := signTestToken(t, after)
+	oldKid, newKid := tokenKid(t, oldToken), tokenKid(t, newToken)
+	if oldKid == "" || newKid == "" || oldKid == newKid {
+		t.Fatalf("kids = %q and %q, want two distinct key IDs", oldKid, newKid)
+	}
+	if parsed, _, _ := jwt.NewParser().ParseUnverified(newToken, &JWTClaims{}); parsed.Method.Alg() != "RS256" {
+		t.Errorf("new token signed with %s, want RS256", parsed.Method.Alg())
+	}
+
+	if _, err := ValidateToken(oldToken, after, TokenTypeAccess); err != nil {
+		t.Errorf("token of the previous key rejected after rotation: %v", err)
+	}
+	if _, err := ValidateToken(newToken, after, TokenTypeAccess); err != nil {
+		t.Errorf("token of the current key rejected: %v", err)
+	}
+	if _, err := ValidateToken(newToken, before, TokenTypeAccess); err == nil {
+		t.Error("a key set without the new key accepted its token")
+	}
+
+	// The kid picks the key; a token can't borrow another key's ID
+	forged := jwt.NewWithClaims(jwt.SigningMethodEdDSA, JWTClaims{TokenType: TokenTypeAccess})
+	forged.Header["kid"] = newKid
+	forgedToken, err := forged.SignedString(edKey)
+	if err != nil {
+		t.Fatalf("signing forged token: %v", err)
+	}
+	if _, err := ValidateToken(forgedToken, after, TokenTypeAccess); err == nil {
+		t.Error("token signed by the old key under the new kid was accepted")
+	}
+}
+
+func TestJWTKeySetKeepsSecretAfterSwitchingToKeyFile(t *testing.T) {
+	_, edKey := testKeys(t)
+	path := writeKeyFile(t, "key.pem", edKey)
+	secret := "shared-secret"
+
+	hmacToken := signTestToken(t, NewHMACKeySet(secret))
+	retiredAt := time.Now().Add(-time.Hour)
+	keys, err := LoadJWTKeySet(&config.Config{
+		JWTSecret:          secret,
+		JWTPrivateKeyFile:  path,
+		JWTSecretRetiredAt: retiredAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatalf("LoadJWTKeySet: %v", err)
+	}
+
+	if _, err := ValidateToken(hmacToken, keys, TokenTypeAccess); err != nil {
+		t.Errorf("HS256 token rejected right after switching keys: %v", err)
+	}
+	if kid := tokenKid(t, signTestToken(t, keys)); kid == "" {
+		t.Error("new tokens are still signed with the shared secret")
+	}
+
+	keys.now = func() time.Time { return retiredAt.Add(RefreshTokenTTL + time.Minute) }
+	if _, err := ValidateToken(hmacToken, keys, TokenTypeAccess); err == nil {
+		t.Error("HS256 token accepted after the refresh token lifetime passed")
+	}
+
+	if _, err := LoadJWTKeySet(&config.Config{JWTSecret: secret, JWTPrivateKeyFile: path, JWTSecretRetiredAt: "yesterday"}); err == nil {
+		t.Error("invalid JWT_SECRET_RETIRED_AT accepted")
+	}
+}
+
+func TestJWTKeySetJWKS(t *testing.T) {
+	rsaKey, edKey := testKeys(t)
+	rsaPath := writeKeyFile(t, "rsa.pem", rsaKey)
+	edPath := writeKeyFile(t, "ed.pem", edKey)
+
+	keys, err := LoadJWTKeySet(&config.Config{JWTSecret: "shared-secret", JWTPrivateKeyFile: rsaPath, JWTPreviousKeyFiles: []string{edPath}})
+	if err != nil {
+		t.Fatalf("LoadJWTKeySet: %v", err)
+	}
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want the RSA and Ed25519 keys only: %+v", len(jwks.Keys), jwks.Keys)
+	}
+
+	rsaJWK, edJWK := jwks.Keys[0], jwks.Keys[1]
+	if rsaJWK.Kty != "RSA" || rsaJWK.Alg != "RS256" || rsaJWK.Use != "sig" || rsaJWK.Kid != tokenKid(t, signTestToken(t, keys)) {
+		t.Errorf("current key = %+v, want the RS256 signing key first", rsaJWK)
+	}
+	n, _ := base64.RawURLEncoding.DecodeString(rsaJWK.N)
+	e, _ := base64.RawURLEncoding.DecodeString(rsaJWK.E)
+	if new(big.Int).SetBytes(n).Cmp(rsaKey.N) != 0 || int(new(big.Int).SetBytes(e).Int64()) != rsaKey.E {
+		t.Errorf("RSA JWK n/e do not match the public key")
+	}
+
+	if edJWK.Kty != "OKP" || edJWK.Crv != "Ed25519" || edJWK.Alg != "EdDSA" || edJWK.Kid == "" {
+		t.Errorf("previous key = %+v, want an Ed25519 OKP key", edJWK)
+	}
+	x, _ := base64.RawURLEncoding.DecodeString(edJWK.X)
+	if !ed25519.PublicKey(x).Equal(edKey.Public()) {
+		t.Errorf("Ed25519 JWK x does not match the public key")
+	}
+
+	if hmacOnly := NewHMACKeySet("shared-secret").JWKS(); len(hmacOnly.Keys) != 0 {
+		t.Errorf("HMAC key set published %+v", hmacOnly.Keys)
+	}
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"errors"
+
+	"github.com/livekit/protocol/auth"
+)
+
+// VerifyLiveKitToken verifies a token signed with the LiveKit API key/secret
+// and returns its grants. Used to authenticate LiveKit agents and services.
+func VerifyLiveKitToken(token, apiKey, apiSecret string) (*auth.ClaimGrants, error) {
+	verifier, err := auth.ParseAPIToken(token)
+	if err != nil {
+		return nil, errors.New("invalid LiveKit token")
+	}
+
+	if verifier.APIKey() != apiKey {
+		return nil, errors.New("unknown LiveKit API key")
+	}
+
+	claims, err := verifier.Verify(apiSecret)
+	if err != nil {
+		return nil, errors.New("invalid or expired LiveKit token")
+	}
+
+	return claims, nil
+}
@@ -17,10 +17,11 @@ type RabbitMQClient struct {
 }
 
 type EmailMessage struct {
-	To      string `json:"to"`
-	Subject string `json:"subject"`
-	Body    string `json:"body"`
-	Type    string `json:"type"` // "otp", "reset_password", "verification"
+	To      string          `json:"to"`
+	Subject string          `json:"subject"`
+	Body    string          `json:"body"`
+	Type    string          `json:"type"`              // "otp", "reset_password", "verification", "meeting_invite", "meeting_reminder", "account_locked"
+	Payload json.RawMessage `json:"payload,omitempty"` // Structured data for templated emails
 }
 
 const (
@@ -0,0 +1,166 @@
+package util
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimit is a token bucket holding up to Burst requests, refilled
+// evenly so that a full bucket takes Period to refill
+type RateLimit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// interval is how long one token takes to refill
+func (l RateLimit) interval() time.Duration {
+	return l.Period / time.Duration(l.Burst)
+}
+
+// RateLimiter takes tokens from named buckets
+type RateLimiter interface {
+	// Allow takes a token from the bucket of key. When the bucket is empty
+	// it returns false and how long until the next token is available.
+	Allow(key string, limit RateLimit) (bool, time.Duration)
+}
+
+// memoryRateLimiter keeps buckets in process, for single-instance deployments
+// and as a fallback when Redis is unavailable
+type memoryRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens   int
+	last     time.Time // When tokens was last refilled
+	interval time.Duration
+	burst    int
+}
+
+// NewMemoryRateLimiter creates a rate limiter that keeps buckets in memory
+func NewMemoryRateLimiter() RateLimiter {
+	return &memoryRateLimiter{buckets: make(map[string]*tokenBucket), now: time.Now}
+}
+
+func (l *memoryRateLimiter) Allow(key string, limit RateLimit) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: limit.Burst, last: now, interval: limit.interval(), burst: limit.Burst}
+		l.buckets[key] = bucket
+	}
+	bucket.refill(now)
+
+	if bucket.tokens == 0 {
+		return false, bucket.interval - now.Sub(bucket.last)
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// refill adds the tokens earned since the last refill
+func (b *tokenBucket) refill(now time.Time) {
+	if earned := int(now.Sub(b.last) / b.interval); earned > 0 {
+		b.tokens += earned
+		b.last = b.last.Add(time.Duration(earned) * b.interval)
+	}
+	if b.tokens >= b.burst {
+		b.tokens = b.burst
+		b.last = now
+	}
+}
+
+// sweep drops buckets that have refilled completely, at most once a minute
+func (l *memoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		bucket.refill(now)
+		if bucket.tokens == bucket.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+const rateLimitKeyPrefix = "rate_limit:"
+
+// rateLimitScript is the token bucket of the memory limiter, run atomically
+// in Redis. It returns 0 when a token was taken, otherwise the milliseconds
+// until the next one.
+var rateLimitScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(state[1])
+local last = tonumber(state[2])
+if tokens == nil or last == nil then
+	tokens = burst
+	last = now
+end
+
+local earned = math.floor((now - last) / interval)
+if earned > 0 then
+	tokens = tokens + earned
+	last = last + earned * interval
+end
+if tokens >= burst then
+	tokens = burst
+	last = now
+end
+
+local wait = 0
+if tokens == 0 then
+	wait = interval - (now - last)
+else
+	tokens = tokens - 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'last', last)
+redis.call('PEXPIRE', KEYS[1], burst * interval)
+return wait
+`)
+
+// redisRateLimiter shares buckets between instances. If Redis fails, it
+// falls back to buckets kept by this instance rather than letting every
+// request through.
+type redisRateLimiter struct {
+	client   *redis.Client
+	fallback RateLimiter
+}
+
+// NewRedisRateLimiter creates a rate limiter backed by Redis
+func NewRedisRateLimiter(client *redis.Client) RateLimiter {
+	return &redisRateLimiter{client: client, fallback: NewMemoryRateLimiter()}
+}
+
+func (l *redisRateLimiter) Allow(key string, limit RateLimit) (bool, time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	wait, err := rateLimitScript.Run(ctx, l.client, []string{rateLimitKeyPrefix + key},
+		limit.Burst, limit.interval().Milliseconds(), time.Now().UnixMilli()).Int64()
+	if err != nil {
+		log.Printf("[RateLimit] Redis unavailable, using in-memory limits: %v", err)
+		return l.fallback.Allow(key, limit)
+	}
+	if wait > 0 {
+		return false, time.Duration(wait) * time.Millisecond
+	}
+	return true, 0
+}
@@ -0,0 +1,149 @@
+package util
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeClock is a settable time source for the memory limiter
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestMemoryLimiter() (*memoryRateLimiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewMemoryRateLimiter().(*memoryRateLimiter)
+	limiter.now = clock.now
+	return limiter, clock
+}
+
+// takeAll drains the bucket and reports how many requests were allowed
+func takeAll(limiter RateLimiter, key string, limit RateLimit) (int, time.Duration) {
+	for allowed := 0; ; allowed++ {
+		ok, retryAfter := limiter.Allow(key, limit)
+		if !ok {
+			return allowed, retryAfter
+		}
+		if allowed > limit.Burst {
+			return allowed, 0
+		}
+	}
+}
+
+func TestMemoryRateLimiterBurstAndRetryAfter(t *testing.T) {
+	limiter, clock := newTestMemoryLimiter()
+	limit := RateLimit{Burst: 3, Period: 3 * time.Minute} // a token a minute
+
+	allowed, retryAfter := takeAll(limiter, "login:a", limit)
+	if allowed != 3 {
+		t.Fatalf("allowed %d requests, want the burst of 3", allowed)
+	}
+	if retryAfter != time.Minute {
+		t.Errorf("retry after %v, want a minute", retryAfter)
+	}
+
+	clock.advance(40 * time.Second)
+	if ok, retryAfter := limiter.Allow("login:a", limit); ok || retryAfter != 20*time.Second {
+		t.Errorf("40s later: allowed %v, retry after %v; want refused for 20s more", ok, retryAfter)
+	}
+
+	// Another key has its own bucket
+	if ok, _ := limiter.Allow("login:b", limit); !ok {
+		t.Error("a different key was throttled")
+	}
+}
+
+func TestMemoryRateLimiterRefill(t *testing.T) {
+	limiter, clock := newTestMemoryLimiter()
+	limit := RateLimit{Burst: 3, Period: 3 * time.Minute}
+	takeAll(limiter, "key", limit)
+
+	clock.advance(time.Minute)
+	if allowed, _ := takeAll(limiter, "key", limit); allowed != 1 {
+		t.Errorf("after one interval allowed %d, want 1", allowed)
+	}
+
+	// A long pause refills the bucket, but never beyond the burst
+	clock.advance(time.Hour)
+	if allowed, _ := takeAll(limiter, "key", limit); allowed != 3 {
+		t.Errorf("after an hour allowed %d, want the burst of 3", allowed)
+	}
+}
+
+func TestMemoryRateLimiterSweepsFullBuckets(t *testing.T) {
+	limiter, clock := newTestMemoryLimiter()
+	limit := RateLimit{Burst: 2, Period: time.Minute}
+	limiter.Allow("idle", limit)
+	takeAll(limiter, "busy", limit)
+
+	clock.advance(2 * time.Minute)
+	limiter.Allow("new", limit)
+	if _, ok := limiter.buckets["idle"]; ok {
+		t.Error("refilled bucket was not swept")
+	}
+	if _, ok := limiter.buckets["new"]; !ok {
+		t.Error("bucket in use was swept")
+	}
+}
+
+func TestRedisRateLimiterFallsBackToMemory(t *testing.T) {
+	// Nothing listens on the discard port, so every script run fails
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:9", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	limiter := NewRedisRateLimiter(client)
+	limit := RateLimit{Burst: 2, Period: time.Hour}
+
+	allowed, retryAfter := takeAll(limiter, "fallback", limit)
+	if allowed != 2 || retryAfter <= 0 {
+		t.Errorf("allowed %d, retry after %v; want the burst enforced in memory", allowed, retryAfter)
+	}
+}
+
+// TestRedisRateLimiterScript runs the token bucket script against the Redis
+// server in TEST_REDIS_ADDR
+func TestRedisRateLimiterScript(t *testing.T) {
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR is not set")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { client.Close() })
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("connecting to Redis: %v", err)
+	}
+
+	limiter := &redisRateLimiter{client: client, fallback: failingLimiter{t}}
+	key := "test:" + uuid.New().String()
+	t.Cleanup(func() { client.Del(context.Background(), rateLimitKeyPrefix+key) })
+	limit := RateLimit{Burst: 3, Period: 3 * time.Second}
+
+	allowed, retryAfter := takeAll(limiter, key, limit)
+	if allowed != 3 {
+		t.Fatalf("allowed %d requests, want the burst of 3", allowed)
+	}
+	if retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("retry after %v, want at most one interval", retryAfter)
+	}
+	if ttl := client.PTTL(context.Background(), rateLimitKeyPrefix+key).Val(); ttl <= 0 || ttl > limit.Period {
+		t.Errorf("bucket expires in %v, want within the refill period", ttl)
+	}
+
+	time.Sleep(retryAfter + 50*time.Millisecond)
+	if ok, _ := limiter.Allow(key, limit); !ok {
+		t.Error("no token after waiting Retry-After")
+	}
+}
+
+// failingLimiter fails the test if the Redis limiter falls back
+type failingLimiter struct{ t *testing.T }
+
+func (l failingLimiter) Allow(key string, limit RateLimit) (bool, time.Duration) {
+	l.t.Errorf("fell back to memory for %s", key)
+	return true, 0
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yourapp/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedisClient connects to Redis and checks that it answers
+func NewRedisClient(cfg *config.Config) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
+		Password: cfg.RedisPassword,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return client, nil
+}
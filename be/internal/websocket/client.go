@@ -79,6 +79,11 @@ func (c *Client) readPump() {
 		msg.RoomID = c.roomID
 		msg.UserID = c.userID
 
+		// Raise-hand commands are handled by the hub, not relayed
+		if c.hub.handleHandCommand(c, &msg) {
+			continue
+		}
+
 		// Broadcast to hub
 		c.hub.broadcast <- &msg
 	}
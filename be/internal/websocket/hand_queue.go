@@ -0,0 +1,302 @@
+package websocket
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// RaisedHand is a user waiting for their turn to speak
+type RaisedHand struct {
+	UserID   string    `json:"user_id"`
+	RaisedAt time.Time `json:"raised_at"`
+}
+
+// HandStore keeps the raise-hand queue of each room, ordered by when hands
+// were raised. Stores shared between instances also relay queue events, so
+// every instance can pass them on to its own clients.
+type HandStore interface {
+	Raise(roomID, userID string, at time.Time) (bool, error) // false if the hand was already raised
+	Lower(roomID, userID string) (bool, error)               // false if the hand was not raised
+	PopNext(roomID string) (*RaisedHand, error)              // nil when nobody is waiting
+	Clear(roomID string) error
+	List(roomID string) ([]RaisedHand, error)
+	Publish(message *Message) error
+	Subscribe(deliver func(message *Message))
+}
+
+var (
+	errNotModerator = errors.New("only hosts and co-hosts can do this")
+	errRemoved      = errors.New("you were removed from this meeting by a moderator")
+)
+
+// SetHandStore replaces the in-memory raise-hand store, e.g. with a Redis
+// store shared by all instances. Call it before clients connect.
+func (h *Hub) SetHandStore(store HandStore) {
+	h.hands = store
+	store.Subscribe(func(message *Message) {
+		h.BroadcastMessage(message.RoomID, message)
+	})
+}
+
+// SetModeratorCheck sets how the hub decides who may call on speakers and
+// clear the queue. Without it nobody can.
+func (h *Hub) SetModeratorCheck(canModerate func(roomID, userID string) bool) {
+	h.canModerate = canModerate
+}
+
+// SetRemovedCheck sets how the hub recognizes participants a moderator
+// removed from the room; they cannot use the hand queue.
+func (h *Hub) SetRemovedCheck(wasRemoved func(roomID, userID string) bool) {
+	h.wasRemoved = wasRemoved
+}
+
+// handleHandCommand runs raise-hand commands sent over the socket. It
+// reports false for other messages, which are broadcast as usual.
+//
+// Anyone still in the meeting can raise_hand, lower_hand and get_hand_queue;
+// hosts and co-hosts can also lower someone else's hand (payload
+// {"user_id": ...}), call_next_speaker and clear_hand_queue.
+func (h *Hub) handleHandCommand(c *Client, msg *Message) bool {
+	var command func() error
+	switch msg.Type {
+	case "raise_hand":
+		command = func() error { return h.raiseHand(c) }
+	case "lower_hand":
+		command = func() error { return h.lowerHand(c, msg) }
+	case "call_next_speaker":
+		command = func() error { return h.callNextSpeaker(c) }
+	case "clear_hand_queue":
+		command = func() error { return h.clearHandQueue(c) }
+	case "get_hand_queue":
+		command = func() error { return h.sendHandQueue(c) }
+	default:
+		return false
+	}
+
+	err := errRemoved
+	if !h.removed(c.roomID, c.userID) {
+		err = command()
+	}
+	if err != nil {
+		if !errors.Is(err, errNotModerator) && !errors.Is(err, errRemoved) {
+			log.Printf("[HandQueue] %s failed: room=%s, user=%s, error=%v", msg.Type, c.roomID, c.userID, err)
+			err = errors.New("failed to update the hand queue")
+		}
+		h.SendToUser(c.roomID, c.userID, &Message{
+			RoomID:  c.roomID,
+			UserID:  c.userID,
+			Type:    "hand_queue_error",
+			Payload: map[string]interface{}{"command": msg.Type, "error": err.Error()},
+		})
+	}
+	return true
+}
+
+func (h *Hub) raiseHand(c *Client) error {
+	raised, err := h.hands.Raise(c.roomID, c.userID, time.Now())
+	if err != nil || !raised {
+		return err
+	}
+	return h.publishHandEvent(c.roomID, c.userID, "hand_raised", map[string]interface{}{
+		"user_id": c.userID,
+	})
+}
+
+func (h *Hub) lowerHand(c *Client, msg *Message) error {
+	target := c.userID
+	if payload, ok := msg.Payload.(map[string]interface{}); ok {
+		if userID, ok := payload["user_id"].(string); ok && userID != "" {
+			target = userID
+		}
+	}
+	if target != c.userID && !h.isModerator(c) {
+		return errNotModerator
+	}
+
+	lowered, err := h.hands.Lower(c.roomID, target)
+	if err != nil || !lowered {
+		return err
+	}
+	return h.publishHandEvent(c.roomID, c.userID, "hand_lowered", map[string]interface{}{
+		"user_id":    target,
+		"lowered_by": c.userID,
+	})
+}
+
+func (h *Hub) callNextSpeaker(c *Client) error {
+	if !h.isModerator(c) {
+		return errNotModerator
+	}
+
+	// Skip hands raised by people removed since
+	next, err := h.hands.PopNext(c.roomID)
+	for err == nil && next != nil && h.removed(c.roomID, next.UserID) {
+		next, err = h.hands.PopNext(c.roomID)
+	}
+	if err != nil || next == nil {
+		return err
+	}
+	return h.publishHandEvent(c.roomID, c.userID, "speaker_called", map[string]interface{}{
+		"user_id":   next.UserID,
+		"raised_at": next.RaisedAt,
+		"called_by": c.userID,
+	})
+}
+
+func (h *Hub) clearHandQueue(c *Client) error {
+	if !h.isModerator(c) {
+		return errNotModerator
+	}
+
+	if err := h.hands.Clear(c.roomID); err != nil {
+		return err
+	}
+	return h.publishHandEvent(c.roomID, c.userID, "hand_queue_cleared", map[string]interface{}{
+		"cleared_by": c.userID,
+	})
+}
+
+// sendHandQueue sends the current queue to the requesting user only
+func (h *Hub) sendHandQueue(c *Client) error {
+	queue, err := h.hands.List(c.roomID)
+	if err != nil {
+		return err
+	}
+	h.SendToUser(c.roomID, c.userID, &Message{
+		RoomID:  c.roomID,
+		UserID:  c.userID,
+		Type:    "hand_queue",
+		Payload: map[string]interface{}{"queue": queue},
+	})
+	return nil
+}
+
+// publishHandEvent sends a queue change, with the queue as it is now, to
+// everyone in the room
+func (h *Hub) publishHandEvent(roomID, userID, eventType string, payload map[string]interface{}) error {
+	queue, err := h.hands.List(roomID)
+	if err != nil {
+		return err
+	}
+	payload["queue"] = queue
+	return h.hands.Publish(&Message{
+		RoomID:  roomID,
+		UserID:  userID,
+		Type:    eventType,
+		Payload: payload,
+	})
+}
+
+// dropHand lowers the hand of a user whose last connection to the room closed
+func (h *Hub) dropHand(roomID, userID string) {
+	lowered, err := h.hands.Lower(roomID, userID)
+	if err != nil {
+		log.Printf("[HandQueue] Failed to lower hand of disconnected user: room=%s, user=%s, error=%v", roomID, userID, err)
+		return
+	}
+	if !lowered {
+		return
+	}
+	if err := h.publishHandEvent(roomID, userID, "hand_lowered", map[string]interface{}{
+		"user_id":    userID,
+		"lowered_by": userID,
+	}); err != nil {
+		log.Printf("[HandQueue] Failed to publish lowered hand: room=%s, user=%s, error=%v", roomID, userID, err)
+	}
+}
+
+func (h *Hub) isModerator(c *Client) bool {
+	return h.canModerate != nil && h.canModerate(c.roomID, c.userID)
+}
+
+func (h *Hub) removed(roomID, userID string) bool {
+	return h.wasRemoved != nil && h.wasRemoved(roomID, userID)
+}
+
+// memoryHandStore keeps queues in process, for single-instance deployments
+type memoryHandStore struct {
+	mu      sync.Mutex
+	queues  map[string][]RaisedHand
+	deliver func(message *Message)
+}
+
+func newMemoryHandStore() *memoryHandStore {
+	return &memoryHandStore{queues: make(map[string][]RaisedHand)}
+}
+
+func (s *memoryHandStore) Raise(roomID, userID string, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, hand := range s.queues[roomID] {
+		if hand.UserID == userID {
+			return false, nil
+		}
+	}
+	s.queues[roomID] = append(s.queues[roomID], RaisedHand{UserID: userID, RaisedAt: at})
+	return true, nil
+}
+
+func (s *memoryHandStore) Lower(roomID, userID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue := s.queues[roomID]
+	for i, hand := range queue {
+		if hand.UserID == userID {
+			s.set(roomID, append(queue[:i:i], queue[i+1:]...))
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memoryHandStore) PopNext(roomID string) (*RaisedHand, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue := s.queues[roomID]
+	if len(queue) == 0 {
+		return nil, nil
+	}
+	next := queue[0]
+	s.set(roomID, queue[1:])
+	return &next, nil
+}
+
+func (s *memoryHandStore) Clear(roomID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.queues, roomID)
+	return nil
+}
+
+func (s *memoryHandStore) List(roomID string) ([]RaisedHand, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]RaisedHand{}, s.queues[roomID]...), nil
+}
+
+func (s *memoryHandStore) Publish(message *Message) error {
+	if s.deliver != nil {
+		s.deliver(message)
+	}
+	return nil
+}
+
+func (s *memoryHandStore) Subscribe(deliver func(message *Message)) {
+	s.deliver = deliver
+}
+
+// set stores a room's queue, dropping empty ones
+func (s *memoryHandStore) set(roomID string, queue []RaisedHand) {
+	if len(queue) == 0 {
+		delete(s.queues, roomID)
+		return
+	}
+	s.queues[roomID] = queue
+}
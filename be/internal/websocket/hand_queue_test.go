@@ -0,0 +1,103 @@
+package websocket
+
+import "testing"
+
+// connect registers a client without a network connection; messages for it
+// pile up in its send channel
+func connect(h *Hub, roomID, userID string) *Client {
+	c := &Client{hub: h, send: make(chan *Message, 32), roomID: roomID, userID: userID}
+	h.mu.Lock()
+	if h.rooms[roomID] == nil {
+		h.rooms[roomID] = make(map[*Client]bool)
+	}
+	h.rooms[roomID][c] = true
+	h.mu.Unlock()
+	return c
+}
+
+// received drains the messages sent to the client so far
+func received(c *Client) []*Message {
+	var messages []*Message
+	for {
+		select {
+		case msg := <-c.send:
+			messages = append(messages, msg)
+		default:
+			return messages
+		}
+	}
+}
+
+func queueOf(t *testing.T, h *Hub, roomID string) []string {
+	t.Helper()
+	queue, err := h.hands.List(roomID)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	users := make([]string, len(queue))
+	for i, hand := range queue {
+		users[i] = hand.UserID
+	}
+	return users
+}
+
+func newTestHub(removed map[string]bool) *Hub {
+	h := NewHub()
+	h.SetModeratorCheck(func(roomID, userID string) bool { return userID == "host" })
+	h.SetRemovedCheck(func(roomID, userID string) bool { return removed[userID] })
+	return h
+}
+
+func TestHandQueueRejectsRemovedParticipants(t *testing.T) {
+	h := newTestHub(map[string]bool{"removed": true})
+	removed := connect(h, "room-1", "removed")
+
+	for _, command := range []string{"raise_hand", "lower_hand", "get_hand_queue"} {
+		if !h.handleHandCommand(removed, &Message{RoomID: "room-1", Type: command}) {
+			t.Fatalf("%s was not handled as a hand command", command)
+		}
+		messages := received(removed)
+		if len(messages) != 1 || messages[0].Type != "hand_queue_error" {
+			t.Fatalf("%s: got %+v, want a single hand_queue_error", command, messages)
+		}
+		payload := messages[0].Payload.(map[string]interface{})
+		if payload["error"] != errRemoved.Error() {
+			t.Errorf("%s: error = %v, want %q", command, payload["error"], errRemoved)
+		}
+	}
+	if queue := queueOf(t, h, "room-1"); len(queue) != 0 {
+		t.Errorf("queue = %v, want it empty", queue)
+	}
+}
+
+func TestCallNextSpeakerSkipsRemovedParticipants(t *testing.T) {
+	removedUsers := map[string]bool{}
+	h := newTestHub(removedUsers)
+	host := connect(h, "room-1", "host")
+	for _, user := range []string{"alice", "bob"} {
+		if !h.handleHandCommand(connect(h, "room-1", user), &Message{RoomID: "room-1", Type: "raise_hand"}) {
+			t.Fatal("raise_hand was not handled")
+		}
+	}
+	if queue := queueOf(t, h, "room-1"); len(queue) != 2 {
+		t.Fatalf("queue = %v, want alice and bob", queue)
+	}
+
+	// alice is removed while waiting in the queue
+	removedUsers["alice"] = true
+	received(host)
+	h.handleHandCommand(host, &Message{RoomID: "room-1", Type: "call_next_speaker"})
+
+	var called interface{}
+	for _, msg := range received(host) {
+		if msg.Type == "speaker_called" {
+			called = msg.Payload.(map[string]interface{})["user_id"]
+		}
+	}
+	if called != "bob" {
+		t.Errorf("called %v, want bob", called)
+	}
+	if queue := queueOf(t, h, "room-1"); len(queue) != 0 {
+		t.Errorf("queue = %v, want it empty", queue)
+	}
+}
@@ -0,0 +1,127 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	handQueueKeyPrefix = "hand_queue:"
+	handEventsChannel  = "hand_queue_events"
+
+	// Queues of rooms nobody touches for this long are dropped
+	handQueueTTL = 24 * time.Hour
+
+	redisTimeout = 3 * time.Second
+)
+
+// redisHandStore keeps each room's queue in a sorted set scored by the time
+// the hand was raised, so all instances share one queue. Events are relayed
+// to every instance over pub/sub.
+type redisHandStore struct {
+	client *redis.Client
+}
+
+// NewRedisHandStore creates a raise-hand store backed by Redis
+func NewRedisHandStore(client *redis.Client) HandStore {
+	return &redisHandStore{client: client}
+}
+
+func (s *redisHandStore) Raise(roomID, userID string, at time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	key := handQueueKeyPrefix + roomID
+	added, err := s.client.ZAddNX(ctx, key, redis.Z{Score: float64(at.UnixMilli()), Member: userID}).Result()
+	if err != nil {
+		return false, err
+	}
+	if err := s.client.Expire(ctx, key, handQueueTTL).Err(); err != nil {
+		return false, err
+	}
+	return added == 1, nil
+}
+
+func (s *redisHandStore) Lower(roomID, userID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	removed, err := s.client.ZRem(ctx, handQueueKeyPrefix+roomID, userID).Result()
+	return removed == 1, err
+}
+
+// PopNext removes the longest waiting hand atomically, so two hosts calling
+// on the next speaker at once get different people
+func (s *redisHandStore) PopNext(roomID string) (*RaisedHand, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	popped, err := s.client.ZPopMin(ctx, handQueueKeyPrefix+roomID, 1).Result()
+	if err != nil || len(popped) == 0 {
+		return nil, err
+	}
+	hand := toRaisedHand(popped[0])
+	return &hand, nil
+}
+
+func (s *redisHandStore) Clear(roomID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	return s.client.Del(ctx, handQueueKeyPrefix+roomID).Err()
+}
+
+func (s *redisHandStore) List(roomID string) ([]RaisedHand, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	members, err := s.client.ZRangeWithScores(ctx, handQueueKeyPrefix+roomID, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	queue := make([]RaisedHand, len(members))
+	for i, member := range members {
+		queue[i] = toRaisedHand(member)
+	}
+	return queue, nil
+}
+
+func (s *redisHandStore) Publish(message *Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return s.client.Publish(ctx, handEventsChannel, data).Err()
+}
+
+// Subscribe delivers the events published by all instances, including this
+// one. The subscription reconnects on its own if Redis goes away.
+func (s *redisHandStore) Subscribe(deliver func(message *Message)) {
+	pubsub := s.client.Subscribe(context.Background(), handEventsChannel)
+
+	go func() {
+		for event := range pubsub.Channel() {
+			var message Message
+			if err := json.Unmarshal([]byte(event.Payload), &message); err != nil {
+				log.Printf("[HandQueue] Ignoring malformed event: %v", err)
+				continue
+			}
+			deliver(&message)
+		}
+	}()
+}
+
+func toRaisedHand(member redis.Z) RaisedHand {
+	userID, _ := member.Member.(string)
+	return RaisedHand{
+		UserID:   userID,
+		RaisedAt: time.UnixMilli(int64(member.Score)),
+	}
+}
@@ -19,6 +19,11 @@ type Hub struct {
 	// Unregister requests from clients
 	unregister chan *Client
 
+	// Raise-hand queues, who may manage them and who is kept out of them
+	hands       HandStore
+	canModerate func(roomID, userID string) bool
+	wasRemoved  func(roomID, userID string) bool
+
 	// Mutex for thread-safe operations
 	mu sync.RWMutex
 }
@@ -33,12 +38,14 @@ type Message struct {
 
 // NewHub creates a new Hub instance
 func NewHub() *Hub {
-	return &Hub{
+	h := &Hub{
 		rooms:      make(map[string]map[*Client]bool),
 		broadcast:  make(chan *Message),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 	}
+	h.SetHandStore(newMemoryHandStore())
+	return h
 }
 
 // Run starts the hub
@@ -66,6 +73,10 @@ func (h *Hub) Run() {
 					}
 					log.Printf("Client unregistered: room=%s, user=%s, total=%d",
 						client.roomID, client.userID, len(h.rooms[client.roomID]))
+
+					if !h.connected(client.roomID, client.userID) {
+						go h.dropHand(client.roomID, client.userID)
+					}
 				}
 			}
 			h.mu.Unlock()
@@ -104,6 +115,35 @@ func (h *Hub) BroadcastMessage(roomID string, message *Message) {
 	}
 }
 
+// SendToUser sends a message only to the connections of one user in a room.
+// Messages for slow clients are dropped rather than disconnecting them.
+func (h *Hub) SendToUser(roomID, userID string, message *Message) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.rooms[roomID] {
+		if client.userID != userID {
+			continue
+		}
+		select {
+		case client.send <- message:
+		default:
+			log.Printf("Dropping %s message for slow client: room=%s, user=%s", message.Type, roomID, userID)
+		}
+	}
+}
+
+// connected reports whether the user still has a connection to the room.
+// The caller must hold the lock.
+func (h *Hub) connected(roomID, userID string) bool {
+	for client := range h.rooms[roomID] {
+		if client.userID == userID {
+			return true
+		}
+	}
+	return false
+}
+
 // GetRoomClientCount returns the number of clients in a room
 func (h *Hub) GetRoomClientCount(roomID string) int {
 	h.mu.RLock()